@@ -0,0 +1,300 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"terminal/internal/engine"
+	"terminal/internal/exchange"
+	"terminal/internal/strategy"
+)
+
+// runBacktest implements "hyperterm backtest"
+func runBacktest(args []string) error {
+	fs := flag.NewFlagSet("backtest", flag.ExitOnError)
+	var out outputFlags
+	var src sourceFlags
+	var strat strategyFlags
+	var exec executionFlags
+	out.register(fs)
+	src.register(fs)
+	strat.register(fs)
+	exec.register(fs)
+	fs.Parse(args)
+
+	if err := out.checkFormat(); err != nil {
+		return err
+	}
+	config, err := exec.config()
+	if err != nil {
+		return err
+	}
+	source, err := src.open()
+	if err != nil {
+		return err
+	}
+	s, _, err := strat.strategy()
+	if err != nil {
+		return err
+	}
+
+	backtester := engine.NewBacktester()
+	symbols := strat.symbolList()
+	var result *engine.BacktestResult
+	if multi, ok := s.(strategy.MultiAssetStrategy); ok {
+		if len(symbols) < 2 {
+			return fmt.Errorf("multi-asset strategy %s needs at least 2 symbols", strat.id)
+		}
+		result, err = backtester.RunBasketStrategy(source, multi, symbols, strat.interval, strat.limit, config)
+	} else {
+		result, err = backtester.RunStrategy(source, s, symbols[0], strat.interval, strat.limit, config)
+	}
+	if err != nil {
+		return err
+	}
+
+	return out.write(report{
+		value:  result,
+		header: positionHeader,
+		rows:   positionRows(result.Positions),
+		text: func(w io.Writer) {
+			fmt.Fprintf(w, "%s v%s on %v %s\n", result.StrategyName, result.StrategyVersion, symbols, strat.interval)
+			writeSummary(w, result.Summary())
+		},
+	})
+}
+
+// runOptimize implements "hyperterm optimize"
+func runOptimize(args []string) error {
+	fs := flag.NewFlagSet("optimize", flag.ExitOnError)
+	var out outputFlags
+	var src sourceFlags
+	var strat strategyFlags
+	var exec executionFlags
+	grid := gridFlag{}
+	out.register(fs)
+	src.register(fs)
+	strat.register(fs)
+	exec.register(fs)
+	fs.Var(grid, "grid", "param values to search as name=v1,v2,...; repeat for each param")
+	objective := fs.String("objective", engine.ObjectivePnL, "metric to maximize: pnl, sharpe, profitFactor, winRate or drawdown")
+	top := fs.Int("top", 10, "runs to print in text format; 0 prints all")
+	fs.Parse(args)
+
+	if err := out.checkFormat(); err != nil {
+		return err
+	}
+	if len(grid) == 0 {
+		return fmt.Errorf("at least one -grid is required")
+	}
+	cfg, dataCtx, err := optimizeSetup(&src, &strat, &exec, grid, *objective)
+	if err != nil {
+		return err
+	}
+
+	runs, err := engine.NewBacktester().Optimize(dataCtx, strat.limit, cfg)
+	if err != nil {
+		return err
+	}
+
+	names := slices.Sorted(maps.Keys(grid))
+	header := append(slices.Clone(names), "score")
+	header = append(header, summaryHeader...)
+	rows := make([][]string, 0, len(runs))
+	for _, run := range runs {
+		row := make([]string, 0, len(header))
+		for _, name := range names {
+			row = append(row, fmt.Sprint(run.Params[name]))
+		}
+		row = append(row, formatFloat(run.Score))
+		row = append(row, summaryRow(run.Summary)...)
+		rows = append(rows, row)
+	}
+
+	return out.write(report{
+		value:  runs,
+		header: header,
+		rows:   rows,
+		text: func(w io.Writer) {
+			fmt.Fprintf(w, "%d combinations, best %s first\n", len(runs), cfg.Objective)
+			for i, run := range runs {
+				if *top > 0 && i == *top {
+					break
+				}
+				fmt.Fprintf(w, "%3d. score=%-12.4f trades=%-4d pnl=%-10.2f winRate=%.1f%%  ",
+					i+1, run.Score, run.Summary.TotalTrades, run.Summary.TotalPnL, run.Summary.WinRate)
+				for _, name := range names {
+					fmt.Fprintf(w, " %s=%v", name, run.Params[name])
+				}
+				fmt.Fprintln(w)
+			}
+		},
+	})
+}
+
+// runWalkForward implements "hyperterm walkforward"
+func runWalkForward(args []string) error {
+	fs := flag.NewFlagSet("walkforward", flag.ExitOnError)
+	var out outputFlags
+	var src sourceFlags
+	var strat strategyFlags
+	var exec executionFlags
+	grid := gridFlag{}
+	out.register(fs)
+	src.register(fs)
+	strat.register(fs)
+	exec.register(fs)
+	fs.Var(grid, "grid", "param values to search as name=v1,v2,...; repeat for each param")
+	objective := fs.String("objective", engine.ObjectivePnL, "metric to maximize: pnl, sharpe, profitFactor, winRate or drawdown")
+	trainBars := fs.Int("train", 500, "bars in each training window")
+	testBars := fs.Int("test", 100, "bars in each test window")
+	fs.Parse(args)
+
+	if err := out.checkFormat(); err != nil {
+		return err
+	}
+	cfg, dataCtx, err := optimizeSetup(&src, &strat, &exec, grid, *objective)
+	if err != nil {
+		return err
+	}
+
+	result, err := engine.NewBacktester().WalkForward(dataCtx, engine.WalkForwardConfig{
+		OptimizeConfig: cfg,
+		TrainBars:      *trainBars,
+		TestBars:       *testBars,
+	})
+	if err != nil {
+		return err
+	}
+
+	header := append([]string{"fold", "trainFrom", "testFrom", "testTo", "params", "trainPnL"}, summaryHeader...)
+	rows := make([][]string, 0, len(result.Folds))
+	for _, fold := range result.Folds {
+		row := []string{
+			fmt.Sprint(fold.Index),
+			formatTime(fold.TrainFrom),
+			formatTime(fold.TestFrom),
+			formatTime(fold.TestTo),
+			fmt.Sprint(fold.Params),
+			formatFloat(fold.Train.TotalPnL),
+		}
+		rows = append(rows, append(row, summaryRow(fold.Test)...))
+	}
+
+	return out.write(report{
+		value:  result,
+		header: header,
+		rows:   rows,
+		text: func(w io.Writer) {
+			for _, fold := range result.Folds {
+				fmt.Fprintf(w, "fold %d  test %s .. %s  params=%v  train pnl=%.2f  test pnl=%.2f (%d trades)\n",
+					fold.Index, formatTime(fold.TestFrom), formatTime(fold.TestTo), fold.Params,
+					fold.Train.TotalPnL, fold.Test.TotalPnL, fold.Test.TotalTrades)
+			}
+			fmt.Fprintln(w, "out-of-sample:")
+			writeSummary(w, result.Summary)
+		},
+	})
+}
+
+// optimizeSetup loads the data and builds the search config shared by
+// optimize and walkforward
+func optimizeSetup(
+	src *sourceFlags,
+	strat *strategyFlags,
+	exec *executionFlags,
+	grid gridFlag,
+	objective string,
+) (engine.OptimizeConfig, *strategy.DataContext, error) {
+	var cfg engine.OptimizeConfig
+	config, err := exec.config()
+	if err != nil {
+		return cfg, nil, err
+	}
+	source, err := src.open()
+	if err != nil {
+		return cfg, nil, err
+	}
+	s, params, err := strat.strategy()
+	if err != nil {
+		return cfg, nil, err
+	}
+	if _, ok := s.(strategy.MultiAssetStrategy); ok {
+		return cfg, nil, fmt.Errorf("optimization supports single-asset strategies only")
+	}
+
+	meta := s.GetMetadata()
+	symbol := strat.symbolList()[0]
+	candles, err := source.FetchHistoricalCandles(symbol, strat.interval, strat.limit+meta.WarmupBars)
+	if err != nil {
+		return cfg, nil, err
+	}
+	dataCtx, err := engine.LoadDataContext(source, meta, symbol, strat.interval, candles, time.Now())
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	cfg = engine.OptimizeConfig{
+		StrategyID: strat.id,
+		BaseParams: params,
+		Grid:       engine.ParamGrid(grid),
+		Objective:  objective,
+		Execution:  config,
+	}
+	return cfg, dataCtx, nil
+}
+
+var positionHeader = []string{
+	"symbol", "side", "entryTime", "entryPrice", "exitTime", "exitPrice",
+	"size", "pnl", "pnlPercent", "exitReason", "open",
+}
+
+func positionRows(positions []exchange.Position) [][]string {
+	rows := make([][]string, 0, len(positions))
+	for _, pos := range positions {
+		rows = append(rows, []string{
+			pos.Symbol,
+			pos.Side,
+			formatTime(pos.EntryTime),
+			formatFloat(pos.EntryPrice),
+			formatTime(pos.ExitTime),
+			formatFloat(pos.ExitPrice),
+			formatFloat(pos.Size),
+			formatFloat(pos.PnL),
+			formatFloat(pos.PnLPercentage),
+			pos.ExitReason,
+			fmt.Sprint(pos.IsOpen),
+		})
+	}
+	return rows
+}
+
+var summaryHeader = []string{
+	"totalPnL", "totalPnLPercent", "winRate", "totalTrades",
+	"profitFactor", "maxDrawdownPercent", "sharpeRatio",
+}
+
+func summaryRow(s engine.BacktestSummary) []string {
+	return []string{
+		formatFloat(s.TotalPnL),
+		formatFloat(s.TotalPnLPercent),
+		formatFloat(s.WinRate),
+		fmt.Sprint(s.TotalTrades),
+		formatFloat(s.ProfitFactor),
+		formatFloat(s.MaxDrawdownPercent),
+		formatFloat(s.SharpeRatio),
+	}
+}
+
+func writeSummary(w io.Writer, s engine.BacktestSummary) {
+	fmt.Fprintf(w, "  trades:        %d\n", s.TotalTrades)
+	fmt.Fprintf(w, "  total pnl:     %.2f (%.2f%%)\n", s.TotalPnL, s.TotalPnLPercent)
+	fmt.Fprintf(w, "  win rate:      %.1f%%\n", s.WinRate)
+	fmt.Fprintf(w, "  profit factor: %.2f\n", s.ProfitFactor)
+	fmt.Fprintf(w, "  max drawdown:  %.2f%%\n", s.MaxDrawdownPercent)
+	fmt.Fprintf(w, "  sharpe:        %.2f\n", s.SharpeRatio)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"terminal/internal/engine"
+	"terminal/internal/journal"
+)
+
+// runDivergence implements "hyperterm divergence". It compares the trades a
+// strategy made live, as recorded in the trade journal, with a backtest of
+// the period it ran.
+func runDivergence(args []string) error {
+	fs := flag.NewFlagSet("divergence", flag.ExitOnError)
+	var out outputFlags
+	var src sourceFlags
+	var strat strategyFlags
+	var exec executionFlags
+	out.register(fs)
+	src.register(fs)
+	strat.register(fs)
+	exec.register(fs)
+	id := fs.String("id", "", "id the strategy ran under; defaults to strategy-symbol-interval like run")
+	journalPath := fs.String("journal", "", "trade journal to read; defaults to the configured one")
+	from := fs.String("from", "", "when the live run started, RFC 3339; defaults to one bar before its first journal entry")
+	to := fs.String("to", "", "end of the compared period, RFC 3339; defaults to now")
+	fee := fs.Float64("fee", 0, "fee rate charged on the notional of every live fill, e.g. 0.00045; 0 uses the journaled fees")
+	fs.Parse(args)
+
+	if err := out.checkFormat(); err != nil {
+		return err
+	}
+	execConfig, err := exec.config()
+	if err != nil {
+		return err
+	}
+	source, err := src.open()
+	if err != nil {
+		return err
+	}
+	_, params, err := strat.strategy()
+	if err != nil {
+		return err
+	}
+	symbol, err := strat.symbol()
+	if err != nil {
+		return err
+	}
+
+	cfg := engine.DivergenceConfig{
+		ID:         *id,
+		StrategyID: strat.id,
+		Symbol:     symbol,
+		Interval:   strat.interval,
+		Params:     params,
+		Execution:  execConfig,
+		FeeRate:    *fee,
+	}
+	if cfg.ID == "" {
+		cfg.ID = fmt.Sprintf("%s-%s-%s", strat.id, cfg.Symbol, strat.interval)
+	}
+	if cfg.From, err = parseTimeFlag("from", *from); err != nil {
+		return err
+	}
+	if cfg.To, err = parseTimeFlag("to", *to); err != nil {
+		return err
+	}
+
+	path := *journalPath
+	if path == "" {
+		path = src.cfg.Log.Journal
+	}
+	if path == "" {
+		return fmt.Errorf("no trade journal configured; set -journal")
+	}
+	j, err := journal.Open(path)
+	if err != nil {
+		return err
+	}
+	defer j.Close()
+	entries, err := j.Query(journal.Filter{Strategy: cfg.ID, Symbol: cfg.Symbol})
+	if err != nil {
+		return err
+	}
+
+	result, err := engine.NewBacktester().Divergence(source, entries, cfg)
+	if err != nil {
+		return err
+	}
+
+	return out.write(report{
+		value:  result,
+		header: divergenceHeader,
+		rows:   divergenceRows(result.Trades),
+		text: func(w io.Writer) {
+			a := result.Attribution
+			fmt.Fprintf(w, "%s on %s %s from %s to %s\n", cfg.ID, cfg.Symbol, cfg.Interval,
+				formatTime(result.Config.From), formatTime(result.Config.To))
+			fmt.Fprintf(w, "  trades:         %d matched, %d missed, %d extra\n", result.Matched, result.Missed, result.Extra)
+			fmt.Fprintf(w, "  slippage:       entry %.1f bps, exit %.1f bps on average\n",
+				result.AvgEntrySlippageBps, result.AvgExitSlippageBps)
+			fmt.Fprintf(w, "  entry latency:  %s on average, %s at most\n",
+				time.Duration(result.AvgEntryLatency)*time.Millisecond, time.Duration(result.MaxEntryLatency)*time.Millisecond)
+			fmt.Fprintf(w, "  backtest pnl:   %.2f\n", a.BacktestPnL)
+			fmt.Fprintf(w, "  missed trades:  %+.2f\n", a.Missed)
+			fmt.Fprintf(w, "  extra trades:   %+.2f\n", a.Extra)
+			fmt.Fprintf(w, "  entry slippage: %+.2f\n", a.EntrySlippage)
+			fmt.Fprintf(w, "  exit slippage:  %+.2f\n", a.ExitSlippage)
+			fmt.Fprintf(w, "  size:           %+.2f\n", a.Size)
+			fmt.Fprintf(w, "  fees:           %+.2f\n", -a.Fees)
+			fmt.Fprintf(w, "  live pnl:       %.2f\n", a.LivePnL)
+		},
+	})
+}
+
+// parseTimeFlag parses an optional RFC 3339 time flag into unix milliseconds
+func parseTimeFlag(name, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid -%s: %w", name, err)
+	}
+	return t.UnixMilli(), nil
+}
+
+var divergenceHeader = []string{
+	"status", "side", "backtestEntryTime", "liveEntryTime", "backtestEntry", "liveEntry", "entrySlippageBps",
+	"backtestExit", "liveExit", "exitSlippageBps", "entryLatencyMs", "backtestPnL", "livePnL",
+}
+
+func divergenceRows(trades []engine.TradeDivergence) [][]string {
+	rows := make([][]string, 0, len(trades))
+	for _, t := range trades {
+		row := []string{t.Status, "", "", "", "", "", "", "", "", "", "", "", ""}
+		if bt := t.Backtest; bt != nil {
+			row[1] = bt.Side
+			row[2] = formatTime(bt.EntryTime)
+			row[4] = formatFloat(bt.EntryPrice)
+			row[7] = formatFloat(bt.ExitPrice)
+			row[11] = formatFloat(bt.PnL)
+		}
+		if live := t.Live; live != nil {
+			row[1] = live.Side
+			row[3] = formatTime(live.EntryTime)
+			row[5] = formatFloat(live.EntryPrice)
+			row[8] = formatFloat(live.ExitPrice)
+			row[12] = formatFloat(t.Attribution.LivePnL)
+		}
+		if t.Status == engine.TradeMatched {
+			row[6] = formatFloat(t.EntrySlippageBps)
+			row[9] = formatFloat(t.ExitSlippageBps)
+			row[10] = fmt.Sprint(t.EntryLatency)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
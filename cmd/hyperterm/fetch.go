@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// runFetch implements "hyperterm fetch"
+func runFetch(args []string) error {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	var out outputFlags
+	var src sourceFlags
+	out.register(fs)
+	src.register(fs)
+	symbol := fs.String("symbol", "BTC", "symbol")
+	interval := fs.String("interval", "1h", "candle interval")
+	limit := fs.Int("limit", 1000, "number of candles")
+	before := fs.Int64("before", 0, "fetch candles before this unix millisecond timestamp; 0 means now")
+	fs.Parse(args)
+
+	if err := out.checkFormat(); err != nil {
+		return err
+	}
+	source, err := src.open()
+	if err != nil {
+		return err
+	}
+
+	var candles []hyperliquid.Candle
+	if *before > 0 {
+		candles, err = source.FetchCandlesBefore(*symbol, *interval, *limit, *before)
+	} else {
+		candles, err = source.FetchHistoricalCandles(*symbol, *interval, *limit)
+	}
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(candles))
+	for _, c := range candles {
+		rows = append(rows, []string{
+			fmt.Sprint(c.Time), fmt.Sprint(c.Timestamp), c.Open, c.High, c.Low, c.Close, c.Volume,
+		})
+	}
+
+	return out.write(report{
+		value:  candles,
+		header: []string{"openTime", "closeTime", "open", "high", "low", "close", "volume"},
+		rows:   rows,
+		text: func(w io.Writer) {
+			if len(candles) == 0 {
+				fmt.Fprintln(w, "no candles")
+				return
+			}
+			fmt.Fprintf(w, "%d %s %s candles from %s to %s\n", len(candles), *symbol, *interval,
+				formatTime(candles[0].Time), formatTime(candles[len(candles)-1].Timestamp))
+		},
+	})
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"terminal/internal/config"
+	"terminal/internal/data"
+	"terminal/internal/engine"
+	"terminal/internal/strategy"
+	"terminal/internal/strategy/composite"
+
+	_ "terminal/internal/strategy/builtin"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// outputFlags selects how results are written
+type outputFlags struct {
+	format string
+	out    string
+}
+
+func (o *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.format, "format", "text", "output format: text, json or csv")
+	fs.StringVar(&o.out, "o", "", "write output to file instead of stdout")
+}
+
+// sourceFlags configures the candle source and the layered app config
+// (file, HYPERTERMINAL_* environment, then flags)
+type sourceFlags struct {
+	config     *config.Flags
+	composites string
+	// cfg is the configuration loaded by open
+	cfg config.Config
+}
+
+func (s *sourceFlags) register(fs *flag.FlagSet) {
+	s.config = config.RegisterFlags(fs)
+	fs.StringVar(&s.composites, "composites", "", "directory of composite strategy specs to register")
+}
+
+// loadComposites registers the composite strategies in -composites
+func (s *sourceFlags) loadComposites() error {
+	if s.composites == "" {
+		return nil
+	}
+	return composite.NewStore(s.composites).Load()
+}
+
+// open loads the config, registers composite strategies and creates the
+// data source for the configured network
+func (s *sourceFlags) open() (*data.Source, error) {
+	cfg, err := s.config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	s.cfg = cfg
+	if err := s.loadComposites(); err != nil {
+		return nil, err
+	}
+	source := data.NewSource(cfg.APIURL())
+	if cfg.RedisURL != "" {
+		source.SetRedis(redis.NewClient(&redis.Options{Addr: cfg.RedisURL}))
+	}
+	return source, nil
+}
+
+// strategyFlags selects a strategy, its market and params
+type strategyFlags struct {
+	id       string
+	params   string
+	symbols  string
+	interval string
+	limit    int
+}
+
+func (s *strategyFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&s.id, "strategy", "", "strategy id (see: hyperterm strategies)")
+	fs.StringVar(&s.params, "params", "", "strategy params as a JSON object; empty uses defaults")
+	fs.StringVar(&s.symbols, "symbol", "BTC", "symbol, or comma-separated symbols for multi-asset strategies")
+	fs.StringVar(&s.interval, "interval", "1h", "candle interval")
+	fs.IntVar(&s.limit, "limit", 1000, "bars to evaluate, excluding warm-up")
+}
+
+// parsedParams decodes the -params flag
+func (s *strategyFlags) parsedParams() (map[string]any, error) {
+	params := map[string]any{}
+	if s.params == "" {
+		return params, nil
+	}
+	if err := json.Unmarshal([]byte(s.params), &params); err != nil {
+		return nil, fmt.Errorf("invalid -params: %w", err)
+	}
+	return params, nil
+}
+
+// symbolList splits the -symbol flag
+func (s *strategyFlags) symbolList() []string {
+	var symbols []string
+	for _, symbol := range strings.Split(s.symbols, ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// symbol returns the only symbol of the -symbol flag, for commands that run
+// single-symbol strategies
+func (s *strategyFlags) symbol() (string, error) {
+	symbols := s.symbolList()
+	if len(symbols) != 1 {
+		return "", fmt.Errorf("-symbol must name one symbol, got %q", s.symbols)
+	}
+	return symbols[0], nil
+}
+
+// strategy validates the flags and returns an initialized strategy
+func (s *strategyFlags) strategy() (strategy.Strategy, map[string]any, error) {
+	if s.id == "" {
+		return nil, nil, fmt.Errorf("-strategy is required")
+	}
+	if len(s.symbolList()) == 0 {
+		return nil, nil, fmt.Errorf("-symbol is required")
+	}
+	params, err := s.parsedParams()
+	if err != nil {
+		return nil, nil, err
+	}
+	strat, err := strategy.Get(s.id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := strat.ValidateParams(params); err != nil {
+		return nil, nil, fmt.Errorf("invalid params: %w", err)
+	}
+	if err := strat.Initialize(params); err != nil {
+		return nil, nil, fmt.Errorf("init failed: %w", err)
+	}
+	return strat, params, nil
+}
+
+// executionFlags sets the execution config
+type executionFlags struct {
+	size       float64
+	direction  string
+	takeProfit float64
+	stopLoss   float64
+}
+
+func (e *executionFlags) register(fs *flag.FlagSet) {
+	fs.Float64Var(&e.size, "size", 1, "position size")
+	fs.StringVar(&e.direction, "direction", "both", "trade direction: long, short or both")
+	fs.Float64Var(&e.takeProfit, "tp", 0, "take profit percent; 0 disables")
+	fs.Float64Var(&e.stopLoss, "sl", 0, "stop loss percent; 0 disables")
+}
+
+func (e *executionFlags) config() (engine.ExecutionConfig, error) {
+	switch e.direction {
+	case "long", "short", "both":
+	default:
+		return engine.ExecutionConfig{}, fmt.Errorf("invalid -direction %q", e.direction)
+	}
+	return engine.ExecutionConfig{
+		PositionSize:      e.size,
+		TradeDirection:    e.direction,
+		TakeProfitPercent: e.takeProfit,
+		StopLossPercent:   e.stopLoss,
+	}, nil
+}
+
+// gridFlag collects repeated -grid name=v1,v2,... flags
+type gridFlag engine.ParamGrid
+
+func (g gridFlag) String() string {
+	return fmt.Sprint(map[string][]any(g))
+}
+
+func (g gridFlag) Set(value string) error {
+	name, list, ok := strings.Cut(value, "=")
+	if !ok || name == "" || list == "" {
+		return fmt.Errorf("expected name=v1,v2,... got %q", value)
+	}
+	for _, raw := range strings.Split(list, ",") {
+		g[name] = append(g[name], parseValue(strings.TrimSpace(raw)))
+	}
+	return nil
+}
+
+// parseValue interprets a grid value as a number or boolean when it looks
+// like one; strategies coerce the rest from strings
+func parseValue(raw string) any {
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(raw); err == nil {
+		return b
+	}
+	return raw
+}
@@ -0,0 +1,75 @@
+// Command hyperterm is a headless CLI for the terminal's strategy engine.
+//
+// Usage:
+//
+//	hyperterm <command> [flags]
+//
+// Commands:
+//
+//	backtest      backtest a strategy on historical candles
+//	optimize      grid-search strategy params
+//	walkforward   optimize and test params on rolling windows
+//	fetch         download candles
+//	run           run strategies live or paper trading until interrupted
+//	replay        replay history through the live engine and compare with a backtest
+//	divergence    compare journaled live trades with a backtest of the same period
+//	strategies    list registered strategies
+//	wallet        show, import or approve signing keys
+//
+// Every command accepts -format text|json|csv and -o file. Run
+// "hyperterm <command> -h" for its flags.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command is one CLI subcommand
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"backtest", "backtest a strategy on historical candles", runBacktest},
+	{"optimize", "grid-search strategy params", runOptimize},
+	{"walkforward", "optimize and test params on rolling windows", runWalkForward},
+	{"fetch", "download candles", runFetch},
+	{"run", "run strategies live or paper trading until interrupted", runLive},
+	{"replay", "replay history through the live engine and compare with a backtest", runReplay},
+	{"divergence", "compare journaled live trades with a backtest of the same period", runDivergence},
+	{"strategies", "list registered strategies", runStrategies},
+	{"wallet", "show, import or approve signing keys", runWallet},
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "help" {
+		printUsage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "hyperterm %s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "hyperterm: unknown command %q\n\n", os.Args[1])
+	printUsage()
+	os.Exit(2)
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: hyperterm <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", cmd.name, cmd.usage)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// report is a command result that can be written in every output format
+type report struct {
+	// value is encoded as JSON
+	value any
+	// header and rows are written as CSV
+	header []string
+	rows   [][]string
+	// text writes the human-readable form
+	text func(w io.Writer)
+}
+
+// write writes r in the selected format
+func (o *outputFlags) write(r report) error {
+	w := io.Writer(os.Stdout)
+	if o.out != "" {
+		f, err := os.Create(o.out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch o.format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r.value)
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write(r.header); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(r.rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case "text":
+		r.text(w)
+		return nil
+	default:
+		return fmt.Errorf("unknown -format %q", o.format)
+	}
+}
+
+// checkFormat rejects unknown formats before any work is done
+func (o *outputFlags) checkFormat() error {
+	switch o.format {
+	case "text", "json", "csv":
+		return nil
+	default:
+		return fmt.Errorf("unknown -format %q", o.format)
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatTime(ms int64) string {
+	if ms == 0 {
+		return ""
+	}
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"terminal/internal/engine"
+)
+
+// replayResult is the JSON form of "hyperterm replay": the replay and the
+// backtest of the same candles, and the first trade where they differ
+type replayResult struct {
+	Replay          *engine.BacktestResult `json:"replay"`
+	Backtest        *engine.BacktestResult `json:"backtest"`
+	FirstDifference int                    `json:"firstDifference"`
+}
+
+// runReplay implements "hyperterm replay". It feeds historical candles to a
+// strategy through the live engine on a simulated clock and compares the
+// trades with a backtest of the same candles.
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	var out outputFlags
+	var src sourceFlags
+	var strat strategyFlags
+	var exec executionFlags
+	out.register(fs)
+	src.register(fs)
+	strat.register(fs)
+	exec.register(fs)
+	speed := fs.Float64("speed", 0, "simulated time per real time, e.g. 60 replays an hour a minute; 0 runs as fast as possible")
+	fs.Parse(args)
+
+	if err := out.checkFormat(); err != nil {
+		return err
+	}
+	config, err := exec.config()
+	if err != nil {
+		return err
+	}
+	source, err := src.open()
+	if err != nil {
+		return err
+	}
+	s, params, err := strat.strategy()
+	if err != nil {
+		return err
+	}
+	symbol, err := strat.symbol()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	source.SetContext(ctx)
+
+	replay := &engine.Replay{Speed: *speed}
+	replayed, err := replay.Run(ctx, source, strat.id, symbol, strat.interval, strat.limit, params, config)
+	if err != nil {
+		return err
+	}
+	backtest, err := engine.NewBacktester().RunStrategy(source, s, symbol, strat.interval, strat.limit, config)
+	if err != nil {
+		return err
+	}
+	result := replayResult{
+		Replay:          replayed,
+		Backtest:        backtest,
+		FirstDifference: engine.FirstDifference(replayed.Positions, backtest.Positions),
+	}
+
+	return out.write(report{
+		value:  result,
+		header: positionHeader,
+		rows:   positionRows(replayed.Positions),
+		text: func(w io.Writer) {
+			fmt.Fprintf(w, "%s v%s replayed on %s %s\n", replayed.StrategyName, replayed.StrategyVersion, symbol, strat.interval)
+			writeSummary(w, replayed.Summary())
+			if result.FirstDifference < 0 {
+				fmt.Fprintf(w, "matches the backtest trade for trade\n")
+				return
+			}
+			fmt.Fprintf(w, "differs from the backtest at trade %d (%d trades replayed, %d backtested)\n",
+				result.FirstDifference+1, len(replayed.Positions), len(backtest.Positions))
+		},
+	})
+}
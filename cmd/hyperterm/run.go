@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"terminal/internal/config"
+	"terminal/internal/engine"
+	"terminal/internal/events"
+	"terminal/internal/exchange"
+	"terminal/internal/journal"
+	"terminal/internal/logging"
+	"terminal/internal/position"
+	"terminal/internal/risk"
+	"terminal/internal/strategy"
+)
+
+// runLive implements "hyperterm run". It paper trades against a simulated
+// exchange unless -live is set, and stops every strategy on SIGINT/SIGTERM.
+func runLive(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	var out outputFlags
+	var src sourceFlags
+	var strat strategyFlags
+	var exec executionFlags
+	out.register(fs)
+	src.register(fs)
+	strat.register(fs)
+	exec.register(fs)
+	id := fs.String("id", "", "id of the running strategy; defaults to strategy-symbol-interval")
+	live := fs.Bool("live", false, "trade on the configured network with the key in -secret instead of paper trading")
+	accountID := fs.String("account", config.MainAccount, "account to trade: main or a configured sub-account or vault")
+	balance := fs.Float64("balance", 10000, "paper trading balance")
+	fs.Parse(args)
+
+	if err := out.checkFormat(); err != nil {
+		return err
+	}
+	execConfig, err := exec.config()
+	if err != nil {
+		return err
+	}
+	source, err := src.open()
+	if err != nil {
+		return err
+	}
+	s, params, err := strat.strategy()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	source.SetContext(ctx)
+
+	cfg := src.cfg
+	account, err := cfg.Account(*accountID)
+	if err != nil {
+		return err
+	}
+	adapter, err := newAdapter(ctx, cfg, account, *live, *balance)
+	if err != nil {
+		return err
+	}
+	limits := risk.NewManager(cfg.Risk.Limits())
+	manager := position.NewManager(limits.Guard(account.ID, adapter))
+	manager.SetLeverage(cfg.AccountLeverage(account))
+	eng := engine.NewEngine(source, nil)
+	bus := events.NewBus()
+	eng.SetEvents(bus)
+	eng.AddAccount(account.ID, manager)
+	limits.OnHalt(func(reason string, flatten bool) {
+		bus.Publish("", events.TradingHalted{Reason: reason})
+		if flatten {
+			stop()
+		}
+	})
+	if cfg.Log.Dir != "" {
+		closeLogs := logging.Setup(cfg.Log.Dir, cfg.LogLevel())
+		defer closeLogs()
+	}
+	stopLog := bus.Handle(1024, events.LogSink(slog.Default()))
+	defer stopLog()
+	if cfg.Log.Journal != "" {
+		j, err := journal.Open(cfg.Log.Journal)
+		if err != nil {
+			return err
+		}
+		defer j.Close()
+		stopJournal := bus.HandleAll(4096, func(e events.Event) {
+			if err := j.Record(e); err != nil {
+				slog.Error("failed to write trade journal", "error", err)
+			}
+		})
+		defer stopJournal()
+	}
+
+	symbols := strat.symbolList()
+	runID := *id
+	if runID == "" {
+		runID = fmt.Sprintf("%s-%s-%s", strat.id, symbols[0], strat.interval)
+	}
+	if _, ok := s.(strategy.MultiAssetStrategy); ok {
+		err = eng.StartBasketStrategy(runID, account.ID, strat.id, symbols, strat.interval, params, execConfig)
+	} else {
+		err = eng.StartStrategy(runID, account.ID, strat.id, symbols[0], strat.interval, params, execConfig)
+	}
+	if err != nil {
+		return err
+	}
+
+	mode := "paper"
+	if *live {
+		mode = fmt.Sprintf("live on %s account %s", cfg.Network, account.ID)
+	}
+	fmt.Fprintf(os.Stderr, "Running %s (%s); press Ctrl-C to stop\n", runID, mode)
+	<-ctx.Done()
+
+	summaries := eng.FlattenAll()
+
+	rows := make([][]string, 0, len(summaries))
+	for _, summary := range summaries {
+		for _, pos := range summary.Closed {
+			rows = append(rows, []string{
+				summary.ID, pos.Symbol, pos.Side, formatFloat(pos.Size), formatFloat(pos.EntryPrice),
+				formatFloat(pos.ExitPrice), formatFloat(pos.PnL), summary.Error,
+			})
+		}
+		if len(summary.Closed) == 0 {
+			rows = append(rows, []string{summary.ID, summary.Symbol, "", "", "", "", "0", summary.Error})
+		}
+	}
+	return out.write(report{
+		value:  summaries,
+		header: []string{"id", "symbol", "side", "size", "entryPrice", "exitPrice", "pnl", "error"},
+		rows:   rows,
+		text: func(w io.Writer) {
+			for _, summary := range summaries {
+				if len(summary.Closed) == 0 {
+					fmt.Fprintf(w, "%s: stopped flat\n", summary.ID)
+				}
+				for _, pos := range summary.Closed {
+					fmt.Fprintf(w, "%s: closed %s %.4f %s from %.2f at %.2f, PnL %.2f\n",
+						summary.ID, pos.Side, pos.Size, pos.Symbol, pos.EntryPrice, pos.ExitPrice, pos.PnL)
+				}
+				if summary.Error != "" {
+					fmt.Fprintf(w, "%s: %s\n", summary.ID, summary.Error)
+				}
+			}
+		},
+	})
+}
+
+// newAdapter returns the Hyperliquid adapter of account for live trading or
+// a simulated exchange for paper trading
+func newAdapter(ctx context.Context, cfg config.Config, account config.AccountConfig, live bool, balance float64) (exchange.Adapter, error) {
+	if !live {
+		return exchange.NewMockAdapter(balance), nil
+	}
+	if cfg.Locked() {
+		passphrase, err := readPassphrase(fmt.Sprintf("Passphrase for %s: ", cfg.Keystore))
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.Unlock(passphrase); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.ReadOnly() {
+		return nil, fmt.Errorf("live trading needs a keystore or a private key in %s: %w", cfg.SecretPath, exchange.ErrReadOnly)
+	}
+	if vault := account.VaultAddress(); vault != "" {
+		return exchange.NewHyperliquidVaultAdapter(ctx, cfg.PrivateKey, vault, cfg.APIURL()), nil
+	}
+	return exchange.NewHyperliquidAdapter(ctx, cfg.PrivateKey, cfg.Address, cfg.APIURL()), nil
+}
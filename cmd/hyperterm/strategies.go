@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"terminal/internal/strategy"
+)
+
+// runStrategies implements "hyperterm strategies [list]"
+func runStrategies(args []string) error {
+	if len(args) > 0 && args[0] == "list" {
+		args = args[1:]
+	}
+	fs := flag.NewFlagSet("strategies", flag.ExitOnError)
+	var out outputFlags
+	var src sourceFlags
+	out.register(fs)
+	src.register(fs)
+	fs.Parse(args)
+
+	if err := out.checkFormat(); err != nil {
+		return err
+	}
+	if err := src.loadComposites(); err != nil {
+		return err
+	}
+
+	metas := strategy.List()
+	rows := make([][]string, 0, len(metas))
+	for _, meta := range metas {
+		rows = append(rows, []string{
+			meta.ID, meta.Name, meta.Version, fmt.Sprint(meta.MultiAsset), paramNames(meta),
+		})
+	}
+
+	return out.write(report{
+		value:  metas,
+		header: []string{"id", "name", "version", "multiAsset", "params"},
+		rows:   rows,
+		text: func(w io.Writer) {
+			for _, meta := range metas {
+				kind := ""
+				if meta.MultiAsset {
+					kind = " [multi-asset]"
+				}
+				fmt.Fprintf(w, "%-20s %s v%s%s\n", meta.ID, meta.Name, meta.Version, kind)
+				for _, def := range meta.Parameters {
+					fmt.Fprintf(w, "  %-18s %-8s default=%v\n", def.Name, def.Type, def.DefaultValue)
+				}
+			}
+		},
+	})
+}
+
+// paramNames joins a strategy's parameter names for CSV output
+func paramNames(meta strategy.Metadata) string {
+	names := make([]string, 0, len(meta.Parameters))
+	for _, def := range meta.Parameters {
+		names = append(names, def.Name)
+	}
+	return strings.Join(names, ";")
+}
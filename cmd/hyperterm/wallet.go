@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+	"golang.org/x/term"
+
+	"terminal/internal/config"
+	"terminal/internal/exchange"
+	"terminal/internal/wallet"
+)
+
+// walletInfo describes the configured signing key and account
+type walletInfo struct {
+	Network string `json:"network,omitempty"`
+	// Address is the account traded and queried
+	Address string `json:"address"`
+	// Signer is the address of the signing key; it differs from Address
+	// for agent wallets and is empty in read-only mode
+	Signer   string `json:"signer"`
+	Mode     string `json:"mode"`
+	Keystore string `json:"keystore,omitempty"`
+	// Approved reports whether an agent wallet is approved by the account
+	Approved *bool `json:"approved,omitempty"`
+}
+
+// runWallet implements "hyperterm wallet <show|import|approve-agent>"
+func runWallet(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: hyperterm wallet <show|import|approve-agent> [flags]")
+	}
+	switch args[0] {
+	case "show":
+		return walletShow(args[1:])
+	case "import":
+		return walletImport(args[1:])
+	case "approve-agent":
+		return walletApproveAgent(args[1:])
+	default:
+		return fmt.Errorf("unknown wallet command %q", args[0])
+	}
+}
+
+// walletShow prints the configured account and signing key, and whether an
+// agent wallet is approved
+func walletShow(args []string) error {
+	fs := flag.NewFlagSet("wallet show", flag.ExitOnError)
+	var out outputFlags
+	out.register(fs)
+	flags := config.RegisterFlags(fs)
+	fs.Parse(args)
+
+	if err := out.checkFormat(); err != nil {
+		return err
+	}
+	cfg, err := loadUnlocked(flags)
+	if err != nil {
+		return err
+	}
+
+	info := walletInfo{
+		Network:  cfg.Network,
+		Address:  cfg.Address,
+		Signer:   cfg.SignerAddress,
+		Mode:     "account",
+		Keystore: cfg.Keystore,
+	}
+	switch {
+	case cfg.ReadOnly():
+		info.Mode = "read-only"
+	case cfg.Agent():
+		info.Mode = "agent"
+		adapter := exchange.NewHyperliquidAdapter(context.Background(), cfg.PrivateKey, cfg.Address, cfg.APIURL())
+		approved, err := adapter.AgentApproved()
+		if err != nil {
+			return err
+		}
+		info.Approved = &approved
+	}
+	return out.write(walletReport(info))
+}
+
+// walletImport encrypts a plaintext hex key file into a keystore
+func walletImport(args []string) error {
+	fs := flag.NewFlagSet("wallet import", flag.ExitOnError)
+	var out outputFlags
+	out.register(fs)
+	from := fs.String("from", ".secret", "plaintext file holding the hex private key")
+	to := fs.String("to", filepath.Join(config.Dir(), "keystore.json"), "keystore file to create")
+	fs.Parse(args)
+
+	if err := out.checkFormat(); err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(*from)
+	if err != nil {
+		return err
+	}
+	key, err := wallet.ParseHexKey(string(raw))
+	if err != nil {
+		return fmt.Errorf("invalid private key in %s: %w", *from, err)
+	}
+	passphrase, err := newPassphrase()
+	if err != nil {
+		return err
+	}
+	if err := wallet.WriteKeystore(*to, key, passphrase); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %s. Set keystore = %q in the config file and delete %s.\n", *to, *to, *from)
+	return out.write(walletReport(walletInfo{
+		Address:  wallet.Address(key),
+		Signer:   wallet.Address(key),
+		Mode:     "account",
+		Keystore: *to,
+	}))
+}
+
+// walletApproveAgent approves a new agent wallet with the configured account
+// key and stores the agent key in a keystore. The agent can place orders for
+// the account but not withdraw funds.
+func walletApproveAgent(args []string) error {
+	fs := flag.NewFlagSet("wallet approve-agent", flag.ExitOnError)
+	var out outputFlags
+	out.register(fs)
+	flags := config.RegisterFlags(fs)
+	name := fs.String("name", "hyperterminal", "agent name shown on Hyperliquid")
+	to := fs.String("to", filepath.Join(config.Dir(), "agent.json"), "keystore file to create for the agent key")
+	fs.Parse(args)
+
+	if err := out.checkFormat(); err != nil {
+		return err
+	}
+	cfg, err := loadUnlocked(flags)
+	if err != nil {
+		return err
+	}
+	if cfg.ReadOnly() {
+		return fmt.Errorf("approving an agent needs the account key: %w", exchange.ErrReadOnly)
+	}
+	if cfg.Agent() {
+		return fmt.Errorf("the configured key is an agent wallet; approve agents with the account key")
+	}
+	if _, err := os.Stat(*to); err == nil {
+		return fmt.Errorf("%s already exists", *to)
+	}
+
+	ctx := context.Background()
+	client := hyperliquid.NewExchange(ctx, cfg.PrivateKey, cfg.APIURL(), nil, "", "", nil, hyperliquid.ExchangeOptClientOptions())
+	resp, agentHex, err := client.ApproveAgent(ctx, name)
+	if err != nil {
+		return fmt.Errorf("approve agent: %w", err)
+	}
+	if resp.Status != "ok" {
+		return fmt.Errorf("approve agent: %s %s", resp.Status, resp.Error)
+	}
+	agentKey, err := wallet.ParseHexKey(agentHex)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Approved agent %s for %s on %s. Choose a passphrase for its keystore.\n",
+		wallet.Address(agentKey), cfg.Address, cfg.Network)
+	passphrase, err := newPassphrase()
+	if err != nil {
+		return err
+	}
+	if err := wallet.WriteKeystore(*to, agentKey, passphrase); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %s. Set address = %q and keystore = %q in the config file.\n", *to, cfg.Address, *to)
+	approved := true
+	return out.write(walletReport(walletInfo{
+		Network:  cfg.Network,
+		Address:  cfg.Address,
+		Signer:   wallet.Address(agentKey),
+		Mode:     "agent",
+		Keystore: *to,
+		Approved: &approved,
+	}))
+}
+
+// loadUnlocked loads the config and prompts for the keystore passphrase when
+// the keystore is still locked
+func loadUnlocked(flags *config.Flags) (config.Config, error) {
+	cfg, err := flags.Load()
+	if err != nil {
+		return cfg, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if cfg.Locked() {
+		passphrase, err := readPassphrase(fmt.Sprintf("Passphrase for %s: ", cfg.Keystore))
+		if err != nil {
+			return cfg, err
+		}
+		if err := cfg.Unlock(passphrase); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+// newPassphrase prompts for a new passphrase twice
+func newPassphrase() (string, error) {
+	passphrase, err := readPassphrase("New keystore passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	confirm, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase != confirm {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// stdin buffers piped input across passphrase prompts
+var stdin = bufio.NewReader(os.Stdin)
+
+// readPassphrase reads a passphrase from the terminal without echo, or a
+// line from stdin when it is not a terminal
+func readPassphrase(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := stdin.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("read passphrase: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	raw, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	return string(raw), nil
+}
+
+func walletReport(info walletInfo) report {
+	approved := ""
+	if info.Approved != nil {
+		approved = fmt.Sprint(*info.Approved)
+	}
+	return report{
+		value:  info,
+		header: []string{"network", "address", "signer", "mode", "keystore", "approved"},
+		rows:   [][]string{{info.Network, info.Address, info.Signer, info.Mode, info.Keystore, approved}},
+		text: func(w io.Writer) {
+			if info.Network != "" {
+				fmt.Fprintf(w, "Network:  %s\n", info.Network)
+			}
+			fmt.Fprintf(w, "Account:  %s\n", info.Address)
+			fmt.Fprintf(w, "Signer:   %s\n", info.Signer)
+			fmt.Fprintf(w, "Mode:     %s\n", info.Mode)
+			if info.Keystore != "" {
+				fmt.Fprintf(w, "Keystore: %s\n", info.Keystore)
+			}
+			if approved != "" {
+				fmt.Fprintf(w, "Approved: %s\n", approved)
+			}
+		},
+	}
+}
@@ -0,0 +1,201 @@
+// Command repaint replays candles bar by bar through registered strategies
+// and reports every signal or plotted value that changes after the fact.
+// It exits with status 1 when any strategy repaints.
+//
+// Usage:
+//
+//	repaint [-strategy id] [-params json] [-symbols BTC[,ETH]] [-interval 1h]
+//	        [-fixtures dir | -limit n] [-composites dir] [-max n] [-json]
+//
+// Without -strategy every registered strategy is checked. Candles come from
+// fixture files named SYMBOL_interval.json when -fixtures is set, otherwise
+// from Hyperliquid.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"terminal/internal/data"
+	"terminal/internal/engine"
+	"terminal/internal/strategy"
+	"terminal/internal/strategy/composite"
+	"terminal/internal/strategy/repaint"
+
+	_ "terminal/internal/strategy/builtin"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+var (
+	strategyID = flag.String("strategy", "", "strategy id to check; empty checks every registered strategy")
+	paramsJSON = flag.String("params", "", "strategy params as a JSON object; empty uses defaults")
+	symbols    = flag.String("symbols", "", "comma-separated symbols; defaults to BTC, or BTC,ETH for multi-asset strategies")
+	interval   = flag.String("interval", "1h", "candle interval")
+	fixtures   = flag.String("fixtures", "", "directory of SYMBOL_interval.json candle fixtures")
+	limit      = flag.Int("limit", 500, "bars to replay after warm-up when fetching from Hyperliquid")
+	composites = flag.String("composites", "", "directory of composite strategy specs to register")
+	maxChanges = flag.Int("max", 20, "changes to print per strategy; 0 prints all")
+	asJSON     = flag.Bool("json", false, "print reports as JSON")
+)
+
+func main() {
+	flag.Parse()
+
+	repaints, err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "repaint:", err)
+		os.Exit(2)
+	}
+	if repaints {
+		os.Exit(1)
+	}
+}
+
+// run checks the selected strategies and reports whether any repaints
+func run() (bool, error) {
+	if *composites != "" {
+		if err := composite.NewStore(*composites).Load(); err != nil {
+			return false, err
+		}
+	}
+
+	var params map[string]any
+	if *paramsJSON != "" {
+		if err := json.Unmarshal([]byte(*paramsJSON), &params); err != nil {
+			return false, fmt.Errorf("invalid -params: %w", err)
+		}
+	}
+
+	var metas []strategy.Metadata
+	if *strategyID != "" {
+		strat, err := strategy.Get(*strategyID)
+		if err != nil {
+			return false, err
+		}
+		metas = append(metas, strategy.Describe(strat))
+	} else {
+		metas = strategy.List()
+	}
+
+	var source *data.Source
+	if *fixtures == "" {
+		source = data.NewSource(hyperliquid.MainnetAPIURL)
+	}
+
+	var reports []*repaint.Report
+	repaints := false
+	for _, meta := range metas {
+		report, err := check(source, meta, params)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", meta.ID, err)
+		}
+		if report.Repaints() {
+			repaints = true
+		}
+		if *asJSON {
+			reports = append(reports, report)
+			continue
+		}
+		report.Write(os.Stdout, *maxChanges)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reports); err != nil {
+			return false, err
+		}
+	}
+	return repaints, nil
+}
+
+// check loads candles for meta and replays the strategy over them
+func check(source *data.Source, meta strategy.Metadata, params map[string]any) (*repaint.Report, error) {
+	legs := []string{"BTC"}
+	if meta.MultiAsset {
+		legs = []string{"BTC", "ETH"}
+	}
+	if *symbols != "" {
+		legs = strings.Split(*symbols, ",")
+	}
+
+	if meta.MultiAsset {
+		basket, err := loadBasket(source, meta, legs)
+		if err != nil {
+			return nil, err
+		}
+		return repaint.DetectBasket(meta.ID, params, basket)
+	}
+
+	ctx, err := loadContext(source, meta, legs[0])
+	if err != nil {
+		return nil, err
+	}
+	return repaint.DetectContext(meta.ID, params, ctx)
+}
+
+// loadContext loads primary candles and declared feeds for symbol
+func loadContext(source *data.Source, meta strategy.Metadata, symbol string) (*strategy.DataContext, error) {
+	if source != nil {
+		candles, err := source.FetchHistoricalCandles(symbol, *interval, *limit+meta.WarmupBars)
+		if err != nil {
+			return nil, err
+		}
+		return engine.LoadDataContext(source, meta, symbol, *interval, candles, time.Now())
+	}
+
+	candles, err := loadFixture(symbol, *interval)
+	if err != nil {
+		return nil, err
+	}
+	ctx := strategy.NewDataContext(symbol, *interval, candles)
+	for _, feed := range meta.Feeds {
+		feedSymbol := feed.Symbol
+		if feedSymbol == "" {
+			feedSymbol = symbol
+		}
+		feedCandles, err := loadFixture(feedSymbol, feed.Interval)
+		if err != nil {
+			return nil, err
+		}
+		ctx.AddFeed(feedSymbol, feed.Interval, feedCandles)
+	}
+	return ctx, nil
+}
+
+// loadBasket loads and aligns candles for every leg
+func loadBasket(source *data.Source, meta strategy.Metadata, legs []string) (*strategy.Basket, error) {
+	if source != nil {
+		return engine.LoadBasket(source, legs, *interval, *limit+meta.WarmupBars)
+	}
+
+	series := make(map[string][]hyperliquid.Candle, len(legs))
+	for _, symbol := range legs {
+		candles, err := loadFixture(symbol, *interval)
+		if err != nil {
+			return nil, err
+		}
+		series[symbol] = candles
+	}
+	return strategy.NewBasket(*interval, legs, series), nil
+}
+
+// loadFixture reads SYMBOL_interval.json from the fixtures directory
+func loadFixture(symbol, interval string) ([]hyperliquid.Candle, error) {
+	path := filepath.Join(*fixtures, symbol+"_"+interval+".json")
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var candles []hyperliquid.Candle
+	if err := json.Unmarshal(raw, &candles); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return candles, nil
+}
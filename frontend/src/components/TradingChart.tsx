@@ -1,19 +1,28 @@
 import {
+  AreaSeries,
   CandlestickSeries,
   ColorType,
   createChart,
+  HistogramSeries,
   LineSeries,
+  LineStyle,
   Time,
   LineData,
+  LineWidth,
   IChartApi,
+  IPriceLine,
   ISeriesApi,
+  ISeriesMarkersPluginApi,
+  SeriesMarker,
+  SeriesType,
+  WhitespaceData,
   createSeriesMarkers,
 } from "lightweight-charts";
 import { useEffect, useRef, useState, useMemo, useCallback } from "react";
 import { useShallow } from "zustand/react/shallow";
 import { useChartStore } from "../store/chartStore";
 import { useVisualizationStore } from "../store/visualizationStore";
-import { hyperliquid } from "@/../wailsjs/go/models";
+import { hyperliquid, strategy } from "@/../wailsjs/go/models";
 import { TradingStrategyManager } from "@/lib/TradingStrategyManager";
 import {
   ContextMenu,
@@ -27,11 +36,201 @@ interface TradingChartProps {
   intervalSeconds: number;
 }
 
+// Overlays drawn by renderVisualization, removed before the next render.
+// priceLines are the ones on the candle series, which stays.
+interface RenderedOverlays {
+  series: ISeriesApi<SeriesType>[];
+  priceLines: IPriceLine[];
+  markers: ISeriesMarkersPluginApi<Time>[];
+}
+
+const lineStyles: Record<string, LineStyle> = {
+  solid: LineStyle.Solid,
+  dashed: LineStyle.Dashed,
+  dotted: LineStyle.Dotted,
+};
+
+const markerPositions: Record<string, SeriesMarker<Time>["position"]> = {
+  above: "aboveBar",
+  below: "belowBar",
+  at: "atPriceMiddle",
+};
+
+const tableCorners: Record<string, React.CSSProperties> = {
+  topLeft: { top: 8, left: 8 },
+  topRight: { top: 8, right: 64 },
+  bottomLeft: { bottom: 32, left: 8 },
+  bottomRight: { bottom: 32, right: 64 },
+};
+
+// renderVisualization draws a version 2 visualization: series in their
+// panes, levels as price lines, segments and boxes as line series and
+// markers on the first series of their pane. Fills and tables are not
+// drawn on the chart; tables are overlaid by the component.
+const renderVisualization = (
+  chart: IChartApi,
+  candleSeries: ISeriesApi<"Candlestick">,
+  vis: strategy.Visualization,
+  candles: hyperliquid.Candle[]
+): RenderedOverlays => {
+  const rendered: RenderedOverlays = { series: [], priceLines: [], markers: [] };
+  const timeAt = (i: number) => (candles[i].t / 1000) as Time;
+  const inRange = (i: number) => i >= 0 && i < candles.length;
+
+  // The main pane is 0 and the others follow in order
+  const paneIndex = new Map<string, number>([["main", 0]]);
+  (vis.panes || []).forEach((pane, i) => paneIndex.set(pane.id, i + 1));
+  const panes = new Map((vis.panes || []).map((pane) => [pane.id, pane]));
+
+  // hosts holds the first series of each pane for price lines and markers
+  const hosts = new Map<string, ISeriesApi<SeriesType>>([["main", candleSeries]]);
+  const add = <T extends SeriesType>(series: ISeriesApi<T>, pane: string) => {
+    const generic = series as unknown as ISeriesApi<SeriesType>;
+    rendered.series.push(generic);
+    if (!hosts.has(pane)) hosts.set(pane, generic);
+    return series;
+  };
+  const autoscale = (pane: string) => {
+    const p = panes.get(pane);
+    if (p?.min === undefined || p?.max === undefined) return {};
+    const range = { minValue: p.min, maxValue: p.max };
+    return { autoscaleInfoProvider: () => ({ priceRange: range }) };
+  };
+  const lineAt = (pane: string, color: string | undefined, style?: string, width?: number) =>
+    add(
+      chart.addSeries(
+        LineSeries,
+        {
+          color: color || "#ffffff",
+          lineWidth: (width || 1) as LineWidth,
+          lineStyle: lineStyles[style || "solid"],
+          priceLineVisible: false,
+          lastValueVisible: false,
+          crosshairMarkerVisible: false,
+          ...autoscale(pane),
+        },
+        paneIndex.get(pane) ?? 0
+      ),
+      pane
+    );
+
+  for (const s of vis.series || []) {
+    const pane = paneIndex.get(s.pane) ?? 0;
+    const options = {
+      title: s.name,
+      color: s.color || "#ffffff",
+      priceLineVisible: false,
+      lastValueVisible: false,
+      ...autoscale(s.pane),
+    };
+    const data: (LineData | WhitespaceData)[] = [];
+    (s.values || []).forEach((value, i) => {
+      if (!inRange(i)) return;
+      if (value === null || value === undefined) {
+        data.push({ time: timeAt(i) });
+        return;
+      }
+      const color = s.colors?.[i];
+      data.push(color ? { time: timeAt(i), value, color } : { time: timeAt(i), value });
+    });
+
+    if (s.kind === "histogram") {
+      add(chart.addSeries(HistogramSeries, options, pane), s.pane).setData(data);
+    } else if (s.kind === "area") {
+      const area = { lineColor: options.color, topColor: `${options.color}44`, bottomColor: `${options.color}00` };
+      add(chart.addSeries(AreaSeries, { ...options, ...area }, pane), s.pane).setData(
+        data.map((d) => ("value" in d && d.color ? { time: d.time, value: d.value, lineColor: d.color } : d))
+      );
+    } else {
+      const line = { lineWidth: (s.lineWidth || 2) as LineWidth };
+      add(chart.addSeries(LineSeries, { ...options, ...line }, pane), s.pane).setData(data);
+    }
+  }
+
+  for (const seg of vis.segments || []) {
+    if (!inRange(seg.startIndex) || !inRange(seg.endIndex)) continue;
+    const points = [
+      { time: timeAt(seg.startIndex), value: seg.startValue },
+      { time: timeAt(seg.endIndex), value: seg.endValue },
+    ].sort((a, b) => (a.time as number) - (b.time as number));
+    lineAt(seg.pane, seg.color, seg.style, seg.width).setData(
+      points[0].time === points[1].time ? [points[0]] : points
+    );
+  }
+
+  // Boxes are drawn by their top and bottom edges
+  for (const box of vis.boxes || []) {
+    if (!inRange(box.startIndex) || !inRange(box.endIndex)) continue;
+    const color = box.borderColor || box.color;
+    for (const value of [box.top, box.bottom]) {
+      const edge: LineData[] = [];
+      for (let i = Math.min(box.startIndex, box.endIndex); i <= Math.max(box.startIndex, box.endIndex); i++) {
+        edge.push({ time: timeAt(i), value });
+      }
+      const series = lineAt(box.pane, color);
+      series.setData(edge);
+      if (box.text && value === box.top) {
+        rendered.markers.push(
+          createSeriesMarkers(series, [
+            { time: edge[0].time, position: "aboveBar", shape: "square", size: 0, color: color || "#ffffff", text: box.text },
+          ])
+        );
+      }
+    }
+  }
+
+  for (const level of vis.levels || []) {
+    const host = hosts.get(level.pane) || (paneIndex.has(level.pane) ? lineAt(level.pane, "transparent") : undefined);
+    if (!host) continue;
+    const line = host.createPriceLine({
+      price: level.value,
+      color: level.color || "#888888",
+      lineWidth: 1,
+      lineStyle: lineStyles[level.style || "solid"],
+      axisLabelVisible: true,
+      title: level.label || "",
+    });
+    // Lines on other series go with them
+    if (host === candleSeries) rendered.priceLines.push(line);
+  }
+
+  const markersByPane = new Map<string, SeriesMarker<Time>[]>();
+  for (const marker of vis.markers || []) {
+    if (!inRange(marker.index) || !paneIndex.has(marker.pane)) continue;
+    const position = markerPositions[marker.position || "above"] || "aboveBar";
+    const shape =
+      marker.shape === "arrowUp" || marker.shape === "arrowDown" || marker.shape === "square"
+        ? marker.shape
+        : "circle";
+    const list = markersByPane.get(marker.pane) || [];
+    list.push({
+      time: timeAt(marker.index),
+      position,
+      shape,
+      color: marker.color || "#ffffff",
+      text: marker.text,
+      // Labels show only their text
+      ...(marker.shape === "label" ? { size: 0 } : {}),
+      ...(position === "atPriceMiddle" ? { price: marker.value || 0 } : {}),
+    } as SeriesMarker<Time>);
+    markersByPane.set(marker.pane, list);
+  }
+  markersByPane.forEach((list, pane) => {
+    const host = hosts.get(pane) || lineAt(pane, "transparent");
+    list.sort((a, b) => (a.time as number) - (b.time as number));
+    rendered.markers.push(createSeriesMarkers(host, list));
+  });
+
+  return rendered;
+};
+
 export const TradingChart = ({ intervalSeconds }: TradingChartProps) => {
   const chartRef = useRef<HTMLDivElement>(null);
   const chartInstanceRef = useRef<IChartApi | null>(null);
   const candleSeriesRef = useRef<ISeriesApi<"Candlestick"> | null>(null);
   const trendLineSeriesRef = useRef<ISeriesApi<"Line">[]>([]);
+  const overlaysRef = useRef<RenderedOverlays | null>(null);
+  const prevVisualization = useRef<strategy.Visualization | null>(null);
   const [clickedPrice, setClickedPrice] = useState<number | null>(null);
   const prevCandlesLength = useRef(0);
   const prevStrategyHash = useRef("");
@@ -63,6 +262,13 @@ export const TradingChart = ({ intervalSeconds }: TradingChartProps) => {
     }));
   }, [candles]);
 
+  // Version 2 visualizations are drawn by the generic renderer; older
+  // results only carry the max-trend fields
+  const visualization =
+    strategyOutput?.visualization && strategyOutput.visualization.version >= 2
+      ? strategyOutput.visualization
+      : null;
+
   const strategyHash = useMemo(() => {
     if (
       !strategyOutput ||
@@ -184,6 +390,8 @@ export const TradingChart = ({ intervalSeconds }: TradingChartProps) => {
         chartInstanceRef.current = null;
         candleSeriesRef.current = null;
         trendLineSeriesRef.current = [];
+        overlaysRef.current = null;
+        prevVisualization.current = null;
       }
     };
   }, [intervalSeconds, symbol]);
@@ -217,6 +425,25 @@ export const TradingChart = ({ intervalSeconds }: TradingChartProps) => {
     prevCandlesLength.current = formattedData.length;
   }, [formattedData]);
 
+  useEffect(() => {
+    const chart = chartInstanceRef.current;
+    const candleSeries = candleSeriesRef.current;
+    if (!chart || !candleSeries) return;
+    if (visualization === prevVisualization.current) return;
+
+    const overlays = overlaysRef.current;
+    if (overlays) {
+      overlays.markers.forEach((markers) => markers.detach());
+      overlays.priceLines.forEach((line) => candleSeries.removePriceLine(line));
+      overlays.series.forEach((series) => chart.removeSeries(series));
+      overlaysRef.current = null;
+    }
+    prevVisualization.current = visualization;
+    if (!visualization || !candles || candles.length === 0) return;
+
+    overlaysRef.current = renderVisualization(chart, candleSeries, visualization, candles);
+  }, [visualization, candles]);
+
   useEffect(() => {
     if (!chartInstanceRef.current || !strategyOutput) return;
     if (!strategyOutput.Directions || !strategyOutput.TrendLines) return;
@@ -228,7 +455,7 @@ export const TradingChart = ({ intervalSeconds }: TradingChartProps) => {
     });
     trendLineSeriesRef.current = [];
 
-    if (strategyOutput.Directions.length === 0) {
+    if (visualization || strategyOutput.Directions.length === 0) {
       prevStrategyHash.current = strategyHash;
       return;
     }
@@ -344,7 +571,7 @@ export const TradingChart = ({ intervalSeconds }: TradingChartProps) => {
 
     chartInstanceRef.current.timeScale().fitContent();
     prevStrategyHash.current = strategyHash;
-  }, [strategyOutput, strategyHash, candles]);
+  }, [strategyOutput, strategyHash, candles, visualization]);
 
   // Memoized event handlers
   const handleChartClick = useCallback((e: React.MouseEvent) => {
@@ -371,11 +598,42 @@ export const TradingChart = ({ intervalSeconds }: TradingChartProps) => {
   return (
     <ContextMenu>
       <ContextMenuTrigger asChild>
-        <div
-          ref={chartRef}
-          style={{ width: "100%", height: "100%" }}
-          onClick={handleChartClick}
-        />
+        <div style={{ position: "relative", width: "100%", height: "100%" }}>
+          <div
+            ref={chartRef}
+            style={{ width: "100%", height: "100%" }}
+            onClick={handleChartClick}
+          />
+          {visualization?.tables?.map((table) => (
+            <table
+              key={table.id}
+              className="absolute z-10 bg-black/60 text-xs text-white pointer-events-none"
+              style={tableCorners[table.position || "topRight"] || tableCorners.topRight}
+            >
+              {table.title && <caption className="text-left px-1">{table.title}</caption>}
+              <thead>
+                <tr>
+                  {table.columns.map((column, i) => (
+                    <th key={i} className="px-1 text-left font-normal text-white/60">
+                      {column}
+                    </th>
+                  ))}
+                </tr>
+              </thead>
+              <tbody>
+                {table.rows.map((row, i) => (
+                  <tr key={i}>
+                    {row.map((cell, j) => (
+                      <td key={j} className="px-1">
+                        {cell}
+                      </td>
+                    ))}
+                  </tr>
+                ))}
+              </tbody>
+            </table>
+          ))}
+        </div>
       </ContextMenuTrigger>
       <ContextMenuContent className="w-48">
         <ContextMenuItem onClick={resetChart}>Reset Chart</ContextMenuItem>
@@ -5,6 +5,7 @@ import { Button } from "@/components/ui/button";
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
 import { X } from "lucide-react";
 import { TradingStrategyManager } from "@/lib/TradingStrategyManager";
+import { useEngineEvents } from "@/hooks/useEngineEvents";
 
 interface ActiveStrategy {
     id: string;
@@ -164,15 +165,18 @@ export function ActiveStrategiesTab() {
         }
     }, []);
 
-    // Smart polling with visibility check
+    // Refetch when the engine reports a change instead of polling
+    useEngineEvents(
+        ['candleClosed', 'positionOpened', 'positionUpdated', 'positionClosed', 'strategyStateChanged', 'strategyError'],
+        fetchStrategies
+    );
+
     useEffect(() => {
         // Initial fetch
         fetchStrategies();
 
-        // Poll every 15 seconds instead of 5 (reduced frequency)
-        const interval = setInterval(fetchStrategies, 15000);
-
-        // Also fetch when tab becomes visible
+        // Also fetch when tab becomes visible, catching up on events
+        // skipped while hidden
         const handleVisibilityChange = () => {
             if (!document.hidden) {
                 fetchStrategies();
@@ -181,7 +185,6 @@ export function ActiveStrategiesTab() {
         document.addEventListener('visibilitychange', handleVisibilityChange);
 
         return () => {
-            clearInterval(interval);
             document.removeEventListener('visibilitychange', handleVisibilityChange);
         };
     }, [fetchStrategies]);
@@ -0,0 +1,41 @@
+import { useEffect, useRef } from 'react';
+import { EventsOn } from '@/../wailsjs/runtime/runtime';
+
+// Engine event kinds, emitted by the app as "engine:<kind>"
+export type EngineEventKind =
+    | 'candleClosed'
+    | 'signalGenerated'
+    | 'orderSubmitted'
+    | 'orderFilled'
+    | 'positionOpened'
+    | 'positionUpdated'
+    | 'positionClosed'
+    | 'strategyError'
+    | 'strategyStateChanged'
+    | 'tradingHalted';
+
+// useEngineEvents calls onEvent when any of the given engine events arrives.
+// Bursts, e.g. a fill followed by its position update, are coalesced into
+// one call after delayMs.
+export function useEngineEvents(kinds: EngineEventKind[], onEvent: () => void, delayMs: number = 250) {
+    const onEventRef = useRef(onEvent);
+    onEventRef.current = onEvent;
+    const key = kinds.join(',');
+
+    useEffect(() => {
+        let timer: ReturnType<typeof setTimeout> | null = null;
+        const schedule = () => {
+            if (timer) return;
+            timer = setTimeout(() => {
+                timer = null;
+                onEventRef.current();
+            }, delayMs);
+        };
+
+        const unsubscribers = key.split(',').map((kind) => EventsOn(`engine:${kind}`, schedule));
+        return () => {
+            unsubscribers.forEach((off) => off());
+            if (timer) clearTimeout(timer);
+        };
+    }, [key, delayMs]);
+}
@@ -1,6 +1,7 @@
 import { useState, useEffect, useCallback, useRef } from 'react';
 import { GetPortfolioSummary, GetWalletAddress } from '@/../wailsjs/go/app/App';
 import { exchange } from "@/../wailsjs/go/models";
+import { useEngineEvents } from './useEngineEvents';
 
 export function usePortfolio() {
     const [portfolio, setPortfolio] = useState<exchange.PortfolioSummary | null>(null);
@@ -27,7 +28,7 @@ export function usePortfolio() {
             const addr = await GetWalletAddress();
             setAddress(addr);
 
-            const data = await GetPortfolioSummary();
+            const data = await GetPortfolioSummary(""); // main account
 
             // Only update state if data actually changed
             const newDataStr = JSON.stringify(data);
@@ -43,10 +44,14 @@ export function usePortfolio() {
         }
     }, []);
 
+    // Positions opened and closed by strategies show up right away
+    useEngineEvents(['orderFilled', 'positionOpened', 'positionClosed'], fetchPortfolio);
+
     useEffect(() => {
         fetchPortfolio();
 
-        // Refresh every 30 seconds instead of 10 (reduced frequency)
+        // Prices, funding and trades made outside the app are not engine
+        // events, so those are still refreshed every 30 seconds
         const interval = setInterval(fetchPortfolio, 30000);
 
         // Also fetch when tab becomes visible
@@ -1,5 +1,5 @@
 import { FetchCandles, StrategyBacktest, StrategyRun, StopLiveStrategy, GetRunningStrategies } from '@/../wailsjs/go/app/App';
-import { engine } from '@/../wailsjs/go/models';
+import { engine, strategy } from '@/../wailsjs/go/models';
 import { useChartStore } from '@/store/chartStore';
 
 export class TradingStrategyManager {
@@ -70,6 +70,7 @@ export class TradingStrategyManager {
 
         // Also create sliced nested visualization if it exists
         const slicedVisualization = output.visualization ? {
+            ...this.sliceVisualization(output.visualization, start, end),
             trendLines: slicedTrendLines,
             trendColors: slicedTrendColors,
             directions: slicedDirections,
@@ -107,6 +108,42 @@ export class TradingStrategyManager {
         });
     }
 
+    // sliceVisualization keeps the version 2 overlays within [start, end),
+    // shifting their candle indices like strategy.Visualization.Trim
+    private sliceVisualization(vis: strategy.Visualization, start: number, end: number) {
+        const last = end - start - 1;
+        const clip = (i: number) => Math.min(Math.max(i - start, 0), last);
+        const overlaps = (from: number, to: number) => to >= start && from < end;
+
+        return {
+            version: vis.version,
+            panes: vis.panes,
+            series: vis.series?.map((s) => ({
+                ...s,
+                values: s.values?.slice(start, end) || [],
+                colors: s.colors?.slice(start, end),
+            })),
+            levels: vis.levels,
+            segments: vis.segments
+                ?.filter((seg) => overlaps(seg.startIndex, seg.endIndex))
+                .map((seg) => {
+                    // Interpolate the clipped end points
+                    const valueAt = (i: number) => seg.endIndex === seg.startIndex ? seg.startValue :
+                        seg.startValue + (seg.endValue - seg.startValue) * (i - seg.startIndex) / (seg.endIndex - seg.startIndex);
+                    const from = clip(seg.startIndex), to = clip(seg.endIndex);
+                    return { ...seg, startIndex: from, startValue: valueAt(from + start), endIndex: to, endValue: valueAt(to + start) };
+                }),
+            boxes: vis.boxes
+                ?.filter((box) => overlaps(box.startIndex, box.endIndex))
+                .map((box) => ({ ...box, startIndex: clip(box.startIndex), endIndex: clip(box.endIndex) })),
+            fills: vis.fills?.map((f) => ({ ...f, colors: f.colors?.slice(start, end) })),
+            markers: vis.markers
+                ?.filter((m) => m.index >= start && m.index < end)
+                .map((m) => ({ ...m, index: m.index - start })),
+            tables: vis.tables,
+        };
+    }
+
     async applyStrategy(
         symbol: string,
         interval: string,
@@ -197,11 +234,11 @@ export class TradingStrategyManager {
             StopLossPercent: stopLossPercent || 0,
         };
 
-        return StrategyRun(id, strategyId, symbol, interval, strategyParams, config);
+        return StrategyRun(id, "", strategyId, symbol, interval, strategyParams, config); // "" is the main account
     }
 
     async stopLiveStrategy(id: string): Promise<void> {
-        return StopLiveStrategy(id);
+        return StopLiveStrategy(id, false);
     }
 
     async getRunningStrategies(): Promise<any[]> {
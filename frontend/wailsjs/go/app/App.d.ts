@@ -10,11 +10,11 @@ export function FetchCandles(arg1:string,arg2:string,arg3:number):Promise<hyperl
 
 export function FetchCandlesBefore(arg1:string,arg2:string,arg3:number,arg4:number):Promise<hyperliquid.Candles>;
 
-export function GetActivePositions():Promise<Array<exchange.ActivePosition>>;
+export function GetActivePositions(arg1:string):Promise<Array<exchange.ActivePosition>>;
 
 export function GetAvailableStrategies():Promise<Array<strategy.Metadata>>;
 
-export function GetPortfolioSummary():Promise<exchange.PortfolioSummary>;
+export function GetPortfolioSummary(arg1:string):Promise<exchange.PortfolioSummary>;
 
 export function GetRunningStrategies():Promise<Array<engine.RunningStrategyInfo>>;
 
@@ -26,8 +26,8 @@ export function InvalidateCache():Promise<void>;
 
 export function InvalidateCacheForSymbol(arg1:string):Promise<void>;
 
-export function StopLiveStrategy(arg1:string):Promise<void>;
+export function StopLiveStrategy(arg1:string,arg2:boolean):Promise<void>;
 
 export function StrategyBacktest(arg1:string,arg2:string,arg3:string,arg4:number,arg5:Record<string, any>,arg6:position.ExecutionConfig):Promise<engine.BacktestResult>;
 
-export function StrategyRun(arg1:string,arg2:string,arg3:string,arg4:string,arg5:Record<string, any>,arg6:position.ExecutionConfig):Promise<void>;
+export function StrategyRun(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:Record<string, any>,arg7:position.ExecutionConfig):Promise<void>;
@@ -10,16 +10,16 @@ export function FetchCandlesBefore(arg1, arg2, arg3, arg4) {
   return window['go']['app']['App']['FetchCandlesBefore'](arg1, arg2, arg3, arg4);
 }
 
-export function GetActivePositions() {
-  return window['go']['app']['App']['GetActivePositions']();
+export function GetActivePositions(arg1) {
+  return window['go']['app']['App']['GetActivePositions'](arg1);
 }
 
 export function GetAvailableStrategies() {
   return window['go']['app']['App']['GetAvailableStrategies']();
 }
 
-export function GetPortfolioSummary() {
-  return window['go']['app']['App']['GetPortfolioSummary']();
+export function GetPortfolioSummary(arg1) {
+  return window['go']['app']['App']['GetPortfolioSummary'](arg1);
 }
 
 export function GetRunningStrategies() {
@@ -42,14 +42,14 @@ export function InvalidateCacheForSymbol(arg1) {
   return window['go']['app']['App']['InvalidateCacheForSymbol'](arg1);
 }
 
-export function StopLiveStrategy(arg1) {
-  return window['go']['app']['App']['StopLiveStrategy'](arg1);
+export function StopLiveStrategy(arg1, arg2) {
+  return window['go']['app']['App']['StopLiveStrategy'](arg1, arg2);
 }
 
 export function StrategyBacktest(arg1, arg2, arg3, arg4, arg5, arg6) {
   return window['go']['app']['App']['StrategyBacktest'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
-export function StrategyRun(arg1, arg2, arg3, arg4, arg5, arg6) {
-  return window['go']['app']['App']['StrategyRun'](arg1, arg2, arg3, arg4, arg5, arg6);
+export function StrategyRun(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['app']['App']['StrategyRun'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
 }
@@ -474,7 +474,198 @@ export namespace strategy {
 	}
 	
 	
+	export class Box {
+	    pane: string;
+	    startIndex: number;
+	    endIndex: number;
+	    top: number;
+	    bottom: number;
+	    color?: string;
+	    borderColor?: string;
+	    text?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Box(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pane = source["pane"];
+	        this.startIndex = source["startIndex"];
+	        this.endIndex = source["endIndex"];
+	        this.top = source["top"];
+	        this.bottom = source["bottom"];
+	        this.color = source["color"];
+	        this.borderColor = source["borderColor"];
+	        this.text = source["text"];
+	    }
+	}
+	export class Fill {
+	    from: string;
+	    to: string;
+	    color?: string;
+	    colors?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Fill(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.color = source["color"];
+	        this.colors = source["colors"];
+	    }
+	}
+	export class Level {
+	    pane: string;
+	    value: number;
+	    color?: string;
+	    style?: string;
+	    label?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Level(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pane = source["pane"];
+	        this.value = source["value"];
+	        this.color = source["color"];
+	        this.style = source["style"];
+	        this.label = source["label"];
+	    }
+	}
+	export class Marker {
+	    pane: string;
+	    index: number;
+	    value?: number;
+	    shape: string;
+	    position?: string;
+	    color?: string;
+	    text?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Marker(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pane = source["pane"];
+	        this.index = source["index"];
+	        this.value = source["value"];
+	        this.shape = source["shape"];
+	        this.position = source["position"];
+	        this.color = source["color"];
+	        this.text = source["text"];
+	    }
+	}
+	export class Pane {
+	    id: string;
+	    title: string;
+	    height?: number;
+	    min?: number;
+	    max?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Pane(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.height = source["height"];
+	        this.min = source["min"];
+	        this.max = source["max"];
+	    }
+	}
+	export class Segment {
+	    pane: string;
+	    startIndex: number;
+	    startValue: number;
+	    endIndex: number;
+	    endValue: number;
+	    color?: string;
+	    style?: string;
+	    width?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Segment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pane = source["pane"];
+	        this.startIndex = source["startIndex"];
+	        this.startValue = source["startValue"];
+	        this.endIndex = source["endIndex"];
+	        this.endValue = source["endValue"];
+	        this.color = source["color"];
+	        this.style = source["style"];
+	        this.width = source["width"];
+	    }
+	}
+	export class Series {
+	    id: string;
+	    name: string;
+	    pane: string;
+	    kind: string;
+	    color?: string;
+	    colors?: string[];
+	    lineWidth?: number;
+	    values: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Series(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.pane = source["pane"];
+	        this.kind = source["kind"];
+	        this.color = source["color"];
+	        this.colors = source["colors"];
+	        this.lineWidth = source["lineWidth"];
+	        this.values = source["values"];
+	    }
+	}
+	export class Table {
+	    id: string;
+	    title?: string;
+	    pane: string;
+	    position?: string;
+	    columns: string[];
+	    rows: string[][];
+	
+	    static createFrom(source: any = {}) {
+	        return new Table(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.pane = source["pane"];
+	        this.position = source["position"];
+	        this.columns = source["columns"];
+	        this.rows = source["rows"];
+	    }
+	}
 	export class Visualization {
+	    version: number;
+	    panes?: Pane[];
+	    series?: Series[];
+	    levels?: Level[];
+	    segments?: Segment[];
+	    boxes?: Box[];
+	    fills?: Fill[];
+	    markers?: Marker[];
+	    tables?: Table[];
 	    trendLines: number[];
 	    trendColors: string[];
 	    directions: number[];
@@ -487,6 +678,15 @@ export namespace strategy {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.panes = this.convertValues(source["panes"], Pane);
+	        this.series = this.convertValues(source["series"], Series);
+	        this.levels = this.convertValues(source["levels"], Level);
+	        this.segments = this.convertValues(source["segments"], Segment);
+	        this.boxes = this.convertValues(source["boxes"], Box);
+	        this.fills = this.convertValues(source["fills"], Fill);
+	        this.markers = this.convertValues(source["markers"], Marker);
+	        this.tables = this.convertValues(source["tables"], Table);
 	        this.trendLines = source["trendLines"];
 	        this.trendColors = source["trendColors"];
 	        this.directions = source["directions"];
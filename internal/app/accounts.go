@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"terminal/internal/exchange"
+	"terminal/internal/position"
+)
+
+// account is a named trading account with its own adapter and position
+// manager
+type account struct {
+	info      exchange.AccountInfo
+	adapter   *exchange.HyperliquidAdapter
+	positions *position.Manager
+}
+
+// openAccounts creates an adapter and position manager for the main account
+// and every configured sub-account and vault. Orders pass through the risk
+// manager.
+func (a *App) openAccounts(ctx context.Context) {
+	conf := a.config()
+	for _, cfg := range conf.AccountList() {
+		acc := &account{info: exchange.AccountInfo{
+			ID:       cfg.ID,
+			Kind:     exchange.AccountMain,
+			Address:  conf.AccountAddress(cfg),
+			Leverage: conf.AccountLeverage(cfg),
+		}}
+		switch {
+		case cfg.Vault != "":
+			acc.info.Kind = exchange.AccountVault
+		case cfg.SubAccount != "":
+			acc.info.Kind = exchange.AccountSubAccount
+		}
+
+		if vault := cfg.VaultAddress(); vault != "" {
+			acc.adapter = exchange.NewHyperliquidVaultAdapter(ctx, conf.PrivateKey, vault, conf.APIURL())
+		} else {
+			acc.adapter = exchange.NewHyperliquidAdapter(ctx, conf.PrivateKey, conf.Address, conf.APIURL())
+		}
+		acc.positions = position.NewManager(a.risk.Guard(cfg.ID, acc.adapter))
+		acc.positions.SetLeverage(acc.info.Leverage)
+		a.accounts = append(a.accounts, acc)
+	}
+}
+
+// main returns the main account
+func (a *App) main() *account {
+	return a.accounts[0]
+}
+
+// account returns the account with id; "" is the main account
+func (a *App) account(id string) (*account, error) {
+	if id == "" {
+		return a.main(), nil
+	}
+	for _, acc := range a.accounts {
+		if acc.info.ID == id {
+			return acc, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown account %q", id)
+}
+
+// accountPortfolio queries the portfolio of one account
+func accountPortfolio(acc *account) exchange.AccountPortfolio {
+	portfolio := exchange.AccountPortfolio{Account: acc.info.ID, Address: acc.info.Address}
+	summary, err := acc.adapter.GetPortfolio()
+	if err != nil {
+		portfolio.Error = err.Error()
+	} else {
+		portfolio.Summary = summary
+	}
+	return portfolio
+}
@@ -2,72 +2,248 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	hyperliquid "github.com/sonirico/go-hyperliquid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 
 	"terminal/internal/config"
 	"terminal/internal/data"
 	"terminal/internal/engine"
+	"terminal/internal/events"
 	"terminal/internal/exchange"
-	"terminal/internal/position"
+	"terminal/internal/journal"
+	"terminal/internal/logging"
+	"terminal/internal/preset"
+	"terminal/internal/risk"
+	"terminal/internal/server"
 	"terminal/internal/strategy"
+	"terminal/internal/strategy/composite"
 
-	// Import maxtrend to register it
-	_ "terminal/internal/strategy/maxtrend"
+	// Import strategies to register them
+	_ "terminal/internal/strategy/builtin"
 )
 
 // App is the main application struct for Wails bindings
 type App struct {
-	ctx         context.Context
-	rdb         *redis.Client
-	source      *data.Source
-	exchange    exchange.Adapter
-	eng         *engine.Engine
-	positionMgr *position.Manager
-	backtester  *engine.Backtester
-	cfg         config.Config
-}
-
-// New creates a new App instance
-func New() *App {
-	cfg := config.New()
-	return &App{
-		source: data.NewSource(),
-		rdb: redis.NewClient(&redis.Options{
-			Addr: cfg.RedisURL,
-		}),
+	ctx    context.Context
+	rdb    *redis.Client
+	source *data.Source
+	// accounts are the trading accounts, main account first
+	accounts []*account
+	// risk guards the orders of every account against portfolio limits
+	risk       *risk.Manager
+	eng        *engine.Engine
+	backtester *engine.Backtester
+	composites *composite.Store
+	presets    *preset.Store
+	events     *events.Bus
+	journal    *journal.Journal
+	closeLogs  func() error
+	// subscribers stops the event handlers, flushing queued events
+	subscribers []func()
+	api         *server.Server
+	// cfgMu guards cfg, whose signing key is set when the keystore is
+	// unlocked; read it with config
+	cfgMu sync.RWMutex
+	cfg   config.Config
+}
+
+// New creates a new App instance from a validated configuration
+func New(cfg config.Config) *App {
+	a := &App{
+		source:     data.NewSource(cfg.APIURL()),
 		cfg:        cfg,
 		backtester: engine.NewBacktester(),
+		composites: composite.NewStore(filepath.Join(cfg.DataDir, "composites")),
+		presets:    preset.NewStore(filepath.Join(cfg.DataDir, "presets")),
+		events:     events.NewBus(),
+		risk:       risk.NewManager(cfg.Risk.Limits()),
 	}
+	if cfg.RedisURL != "" {
+		a.rdb = redis.NewClient(&redis.Options{
+			Addr: cfg.RedisURL,
+		})
+	}
+	return a
+}
+
+// config returns a copy of the configuration
+func (a *App) config() config.Config {
+	a.cfgMu.RLock()
+	defer a.cfgMu.RUnlock()
+	return a.cfg
 }
 
 // Startup is called when the app starts
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
+	cfg := a.config()
+	a.closeLogs = logging.Setup(cfg.Log.Dir, cfg.LogLevel())
 	a.source.SetContext(ctx)
 	a.source.SetRedis(a.rdb)
 
-	// Create exchange adapter
-	a.exchange = exchange.NewHyperliquidAdapter(
-		ctx,
-		a.cfg.PrivateKey,
-		a.cfg.Address,
-		a.cfg.URL,
-	)
+	// Create an exchange adapter and position manager per account
+	a.openAccounts(ctx)
+	switch {
+	case cfg.Locked():
+		slog.Warn("keystore locked: live trading is disabled until it is unlocked", "keystore", cfg.Keystore)
+	case cfg.ReadOnly():
+		slog.Warn("read-only mode: live trading is disabled", "network", cfg.Network, "address", cfg.Address)
+	default:
+		go a.checkSigner()
+	}
+
+	// Create engine and forward its activity to the log, the UI and any
+	// notification webhook
+	a.eng = engine.NewEngine(a.source, a.main().positions)
+	a.eng.SetEvents(a.events)
+	for _, acc := range a.accounts[1:] {
+		a.eng.AddAccount(acc.info.ID, acc.positions)
+	}
+	a.risk.OnHalt(a.onHalt)
+	a.subscribe(1024, events.LogSink(slog.Default()))
+	a.subscribe(1024, func(e events.Event) {
+		runtime.EventsEmit(a.ctx, "engine:"+string(e.Kind), e)
+	})
+	if j, err := journal.Open(cfg.Log.Journal); err != nil {
+		slog.Error("failed to open trade journal", "error", err)
+	} else {
+		a.journal = j
+		// The journal is the record of what happened, so it sees every
+		// event even if that holds up the engine
+		a.subscribers = append(a.subscribers, a.events.HandleAll(4096, func(e events.Event) {
+			if err := j.Record(e); err != nil {
+				slog.Error("failed to write trade journal", logging.KeyStrategy, e.Strategy, "error", err)
+			}
+		}))
+	}
+	if cfg.API.WebhookURL != "" {
+		a.subscribe(256, events.Webhook(cfg.API.WebhookURL,
+			events.KindPositionOpened,
+			events.KindPositionClosed,
+			events.KindStrategyError,
+			events.KindStrategyStateChanged,
+			events.KindTradingHalted,
+		))
+	}
+
+	// Register saved composite strategies
+	if err := a.composites.Load(); err != nil {
+		slog.Error("failed to load composite strategies", "error", err)
+	}
+
+	if cfg.API.Addr != "" {
+		a.startAPI()
+	}
+}
+
+// checkSigner logs which key signs orders and warns when an agent wallet
+// has not been approved by the account
+func (a *App) checkSigner() {
+	cfg := a.config()
+	if !cfg.Agent() {
+		slog.Info("trading with the account key", "network", cfg.Network, "address", cfg.Address)
+		return
+	}
+	slog.Info("trading with agent wallet", "network", cfg.Network,
+		"address", cfg.Address, "agent", cfg.SignerAddress)
+	approved, err := a.main().adapter.AgentApproved()
+	if err != nil {
+		slog.Warn("could not verify agent wallet approval", "error", err)
+	} else if !approved {
+		slog.Error("agent wallet is not approved by the account; orders will be rejected",
+			"address", cfg.Address, "agent", cfg.SignerAddress)
+	}
+}
+
+// onHalt reports a trading halt and, when asked to, stops every strategy,
+// closing their positions
+func (a *App) onHalt(reason string, flatten bool) {
+	a.events.Publish("", events.TradingHalted{Reason: reason})
+	if flatten {
+		a.eng.StopAllStrategies()
+	}
+}
 
-	// Create position manager
-	a.positionMgr = position.NewManager(a.exchange)
+// subscribe handles engine events until shutdown
+func (a *App) subscribe(buffer int, fn func(events.Event)) {
+	a.subscribers = append(a.subscribers, a.events.Handle(buffer, fn))
+}
 
-	// Create engine
-	a.eng = engine.NewEngine(a.source, a.positionMgr)
+// startAPI serves the app over the local HTTP/WebSocket API
+func (a *App) startAPI() {
+	cfg := a.config()
+	token := cfg.API.Token
+	if token == "" {
+		buf := make([]byte, 16)
+		rand.Read(buf)
+		token = hex.EncodeToString(buf)
+		path, err := writeToken(cfg.DataDir, token)
+		if err != nil {
+			slog.Error("failed to save the generated API token, set one to use the API", "error", err)
+			return
+		}
+		slog.Info("generated API token", "path", path)
+	}
+	api := server.New(a, a.events, token)
+	if err := api.Start(cfg.API.Addr); err != nil {
+		slog.Error("failed to start API server", "error", err)
+		return
+	}
+	a.api = api
+	slog.Info("API server listening", "url", "http://"+cfg.API.Addr)
+}
+
+// writeToken saves a generated API token to a file only the user can read,
+// so it stays out of the logs
+func writeToken(dir, token string) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "api-token")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	// The mode is only applied to new files
+	if err := file.Chmod(0o600); err != nil {
+		return "", err
+	}
+	if _, err := file.WriteString(token + "\n"); err != nil {
+		return "", err
+	}
+	return path, file.Close()
 }
 
 // Shutdown is called when the app is closing
 func (a *App) Shutdown(ctx context.Context) {
 	a.eng.StopAllStrategies()
+	if a.api != nil {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := a.api.Shutdown(ctx); err != nil {
+			slog.Error("API server shutdown failed", "error", err)
+		}
+	}
+	for _, stop := range a.subscribers {
+		stop()
+	}
+	if a.journal != nil {
+		a.journal.Close()
+	}
+	if a.closeLogs != nil {
+		a.closeLogs()
+	}
 }
 
 // ============================================================================
@@ -85,10 +261,161 @@ func (a *App) GetStrategyParams(strategyID string) (*strategy.Metadata, error) {
 	if err != nil {
 		return nil, err
 	}
-	meta := strat.GetMetadata()
+	meta := strategy.Describe(strat)
 	return &meta, nil
 }
 
+// GetCompositeStrategies returns the definitions of all composite strategies
+func (a *App) GetCompositeStrategies() []composite.Spec {
+	return composite.List()
+}
+
+// SaveCompositeStrategy registers a composite strategy and persists it
+func (a *App) SaveCompositeStrategy(spec composite.Spec) error {
+	return a.composites.Save(spec)
+}
+
+// DeleteCompositeStrategy unregisters a composite strategy and removes it
+func (a *App) DeleteCompositeStrategy(id string) error {
+	return a.composites.Delete(id)
+}
+
+// ============================================================================
+// Preset Endpoints
+// ============================================================================
+
+// GetPresets returns all saved strategy presets
+func (a *App) GetPresets() ([]preset.Preset, error) {
+	return a.presets.List()
+}
+
+// SavePreset validates and stores a preset under its name
+func (a *App) SavePreset(p preset.Preset) (preset.Preset, error) {
+	return a.presets.Save(p)
+}
+
+// DeletePreset removes a saved preset
+func (a *App) DeletePreset(name string) error {
+	return a.presets.Delete(name)
+}
+
+// CheckPreset reports whether a preset matches the current version of its
+// strategy and what a migration would change
+func (a *App) CheckPreset(name string) (preset.Migration, error) {
+	p, err := a.presets.Get(name)
+	if err != nil {
+		return preset.Migration{}, err
+	}
+	return preset.Check(p)
+}
+
+// MigratePreset updates a saved preset to the current version of its strategy
+func (a *App) MigratePreset(name string) (preset.Preset, error) {
+	p, err := a.presets.Get(name)
+	if err != nil {
+		return p, err
+	}
+	migrated, err := preset.Migrate(p)
+	if err != nil {
+		return p, err
+	}
+	return a.presets.Save(migrated)
+}
+
+// ApplyPreset starts a live strategy from a saved preset on account; ""
+// is the main account. Presets saved for an older strategy version are
+// migrated in memory when compatible.
+func (a *App) ApplyPreset(id string, account string, name string) error {
+	p, err := a.presets.Get(name)
+	if err != nil {
+		return err
+	}
+	migrated, err := preset.Migrate(p)
+	if err != nil {
+		return err
+	}
+	if migrated.StrategyVersion != p.StrategyVersion {
+		slog.Info("applying migrated preset", "preset", name, "strategyId", p.StrategyID,
+			"savedVersion", p.StrategyVersion, "version", migrated.StrategyVersion)
+	}
+	return a.StrategyRun(id, account, migrated.StrategyID, migrated.Symbol, migrated.Interval, migrated.Params, migrated.Config)
+}
+
+// ExportPresets returns the named presets as JSON; no names exports all
+func (a *App) ExportPresets(names []string) (string, error) {
+	var presets []preset.Preset
+	if len(names) == 0 {
+		all, err := a.presets.List()
+		if err != nil {
+			return "", err
+		}
+		presets = all
+	}
+	for _, name := range names {
+		p, err := a.presets.Get(name)
+		if err != nil {
+			return "", err
+		}
+		presets = append(presets, p)
+	}
+	raw, err := preset.Export(presets)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+// ImportPresets stores presets from JSON produced by ExportPresets
+func (a *App) ImportPresets(raw string) ([]preset.Preset, error) {
+	return a.presets.Import([]byte(raw))
+}
+
+// ============================================================================
+// Trade Journal Endpoints
+// ============================================================================
+
+// QueryJournal returns trade journal entries matching the filter, oldest
+// first
+func (a *App) QueryJournal(filter journal.Filter) ([]journal.Entry, error) {
+	if a.journal == nil {
+		return nil, fmt.Errorf("trade journal is not available")
+	}
+	return a.journal.Query(filter)
+}
+
+// StrategyDivergence compares the journaled trades of a live strategy with
+// a backtest of the period it ran. The strategy, market, params and config
+// of a strategy that is still running default to its current ones.
+func (a *App) StrategyDivergence(cfg engine.DivergenceConfig) (*engine.DivergenceReport, error) {
+	if a.journal == nil {
+		return nil, fmt.Errorf("trade journal is not available")
+	}
+	if cfg.StrategyID == "" {
+		for _, info := range a.eng.GetRunningStrategies() {
+			if info.ID != cfg.ID {
+				continue
+			}
+			if len(info.Symbols) > 0 {
+				return nil, fmt.Errorf("divergence of multi-asset strategy %s is not supported", info.StrategyID)
+			}
+			cfg.StrategyID = info.StrategyID
+			cfg.Symbol = info.Symbol
+			cfg.Interval = info.Interval
+			cfg.Params = info.Params
+			cfg.Execution = info.Config
+		}
+		if cfg.StrategyID == "" {
+			return nil, fmt.Errorf("strategy %s is not running; give its strategy, symbol and interval", cfg.ID)
+		}
+	}
+
+	entries, err := a.journal.Query(journal.Filter{Strategy: cfg.ID, Symbol: cfg.Symbol})
+	if err != nil {
+		return nil, err
+	}
+	return a.backtester.Divergence(a.source, entries, cfg)
+}
+
 // ============================================================================
 // Candle Data Endpoints
 // ============================================================================
@@ -107,18 +434,30 @@ func (a *App) FetchCandlesBefore(symbol string, interval string, limit int, befo
 // Strategy Execution Endpoints
 // ============================================================================
 
-// StrategyRun starts a live strategy
+// StrategyRun starts a live strategy trading account; "" is the main
+// account
 func (a *App) StrategyRun(
 	id string,
+	account string,
 	strategyID string,
 	symbol string,
 	interval string,
 	params map[string]any,
 	config engine.ExecutionConfig,
 ) error {
-	log.Printf("Strategy Run: id=%s strategyID=%s symbol=%s interval=%s params=%v config=%+v\n",
-		id, strategyID, symbol, interval, params, config)
-	return a.eng.StartStrategy(id, strategyID, symbol, interval, params, config)
+	if a.config().ReadOnly() {
+		return exchange.ErrReadOnly
+	}
+	if err := a.risk.Err(); err != nil {
+		return err
+	}
+	acc, err := a.account(account)
+	if err != nil {
+		return err
+	}
+	slog.Info("strategy run", logging.KeyStrategy, id, "account", acc.info.ID, "strategyId", strategyID,
+		logging.KeySymbol, symbol, "interval", interval, "params", params, "config", config)
+	return a.eng.StartStrategy(id, acc.info.ID, strategyID, symbol, interval, params, config)
 }
 
 // StrategyBacktest runs a backtest
@@ -144,28 +483,65 @@ func (a *App) StrategyBacktest(
 		return nil, fmt.Errorf("init failed: %w", err)
 	}
 
-	// Fetch candles
-	candles, err := a.source.FetchHistoricalCandles(symbol, interval, limit)
+	// Fetch history including warm-up, then backtest the requested span
+	return a.backtester.RunStrategy(a.source, strat, symbol, interval, limit, config)
+}
+
+// StrategyRunBasket starts a live multi-asset strategy on several symbols
+// trading account; "" is the main account
+func (a *App) StrategyRunBasket(
+	id string,
+	account string,
+	strategyID string,
+	symbols []string,
+	interval string,
+	params map[string]any,
+	config engine.ExecutionConfig,
+) error {
+	if a.config().ReadOnly() {
+		return exchange.ErrReadOnly
+	}
+	if err := a.risk.Err(); err != nil {
+		return err
+	}
+	acc, err := a.account(account)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	slog.Info("strategy run basket", logging.KeyStrategy, id, "account", acc.info.ID, "strategyId", strategyID,
+		"symbols", symbols, "interval", interval, "params", params, "config", config)
+	return a.eng.StartBasketStrategy(id, acc.info.ID, strategyID, symbols, interval, params, config)
+}
 
-	// Generate signals and visualization from strategy
-	signals := strat.GenerateSignals(candles)
-	visualization := strat.GetVisualization(candles)
+// StrategyBacktestBasket runs a backtest for a multi-asset strategy
+func (a *App) StrategyBacktestBasket(
+	strategyID string,
+	symbols []string,
+	interval string,
+	limit int,
+	params map[string]any,
+	config engine.ExecutionConfig,
+) (*engine.BacktestResult, error) {
+	// Get strategy from registry
+	strat, err := strategy.Get(strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("unknown strategy: %w", err)
+	}
+	multi, ok := strat.(strategy.MultiAssetStrategy)
+	if !ok {
+		return nil, fmt.Errorf("strategy %s is not a multi-asset strategy", strategyID)
+	}
 
-	// Get strategy metadata
-	meta := strat.GetMetadata()
+	// Validate and initialize
+	if err := strat.ValidateParams(params); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+	if err := strat.Initialize(params); err != nil {
+		return nil, fmt.Errorf("init failed: %w", err)
+	}
 
-	// Run backtest using the engine's backtester
-	return a.backtester.Run(
-		candles,
-		signals,
-		visualization,
-		config,
-		meta.Name,
-		meta.Version,
-	), nil
+	// Fetch and align candles for every leg, then backtest the requested span
+	return a.backtester.RunBasketStrategy(a.source, multi, symbols, interval, limit, config)
 }
 
 // GetRunningStrategies returns info about all running strategies
@@ -173,28 +549,164 @@ func (a *App) GetRunningStrategies() []engine.RunningStrategyInfo {
 	return a.eng.GetRunningStrategies()
 }
 
-// StopLiveStrategy stops a running strategy
-func (a *App) StopLiveStrategy(name string) error {
-	return a.eng.StopStrategy(name)
+// StopLiveStrategy stops a running strategy, closing its position unless
+// keepPosition is set
+func (a *App) StopLiveStrategy(name string, keepPosition bool) error {
+	return a.eng.StopStrategy(name, keepPosition)
+}
+
+// FlattenAllStrategies stops every running strategy, closing its positions,
+// and reports the PnL each one realized
+func (a *App) FlattenAllStrategies() []engine.StopSummary {
+	return a.eng.FlattenAll()
+}
+
+// PauseLiveStrategy makes a running strategy ignore signals while keeping
+// its position and TP/SL
+func (a *App) PauseLiveStrategy(id string) error {
+	return a.eng.PauseStrategy(id)
+}
+
+// ResumeLiveStrategy makes a paused strategy act on signals again
+func (a *App) ResumeLiveStrategy(id string) error {
+	return a.eng.ResumeStrategy(id)
+}
+
+// UpdateLiveStrategy changes the params and, unless config is nil, the
+// execution config of a running strategy at its next bar. Only the given
+// params change.
+func (a *App) UpdateLiveStrategy(id string, params map[string]any, config *engine.ExecutionConfig) error {
+	slog.Info("strategy update", logging.KeyStrategy, id, "params", params, "config", config)
+	return a.eng.UpdateStrategy(id, params, config)
+}
+
+// GetStrategyChanges returns the params and config history of a running
+// strategy
+func (a *App) GetStrategyChanges(id string) ([]engine.ParamChange, error) {
+	return a.eng.GetStrategyChanges(id)
+}
+
+// GetStrategyHistory returns the trades and PnL ledger of a running strategy
+func (a *App) GetStrategyHistory(id string) (*engine.StrategyHistory, error) {
+	return a.eng.GetStrategyHistory(id)
+}
+
+// ============================================================================
+// Risk Endpoints
+// ============================================================================
+
+// GetRiskStatus returns exposure, PnL and the limits across all accounts
+func (a *App) GetRiskStatus() risk.Status {
+	return a.risk.Status()
+}
+
+// KillSwitch halts trading so new orders are rejected. With flatten, every
+// running strategy is stopped and its positions closed.
+func (a *App) KillSwitch(flatten bool) {
+	a.risk.Halt("kill switch", false)
+	if flatten {
+		a.eng.StopAllStrategies()
+	}
+}
+
+// ResumeTrading accepts new orders again after a halt. Strategies stopped
+// by the halt must be started again.
+func (a *App) ResumeTrading() {
+	a.risk.Resume()
 }
 
 // ============================================================================
 // Account/Portfolio Endpoints
 // ============================================================================
 
-// GetWalletAddress returns the wallet address
+// IsReadOnly reports whether the app runs without a private key, so live
+// trading is disabled
+func (a *App) IsReadOnly() bool {
+	return a.config().ReadOnly()
+}
+
+// UnlockWallet decrypts the configured keystore and enables live trading
+func (a *App) UnlockWallet(passphrase string) error {
+	a.cfgMu.Lock()
+	cfg := a.cfg
+	if !cfg.Locked() {
+		a.cfgMu.Unlock()
+		return fmt.Errorf("no locked keystore to unlock")
+	}
+	if err := cfg.Unlock(passphrase); err != nil {
+		a.cfgMu.Unlock()
+		return err
+	}
+	a.cfg = cfg
+	a.cfgMu.Unlock()
+
+	for _, acc := range a.accounts {
+		acc.adapter.SetSigner(cfg.PrivateKey)
+	}
+	go a.checkSigner()
+	return nil
+}
+
+// GetSignerAddress returns the address of the key signing orders, which is
+// an agent wallet when it differs from the wallet address, or "" when
+// read-only
+func (a *App) GetSignerAddress() string {
+	return a.main().adapter.SignerAddress()
+}
+
+// GetNetwork returns the Hyperliquid network the app is connected to
+func (a *App) GetNetwork() string {
+	return a.config().Network
+}
+
+// GetWalletAddress returns the address of the main account
 func (a *App) GetWalletAddress() string {
-	return a.exchange.GetAddress()
+	return a.main().adapter.GetAddress()
 }
 
-// GetPortfolioSummary returns the portfolio summary
-func (a *App) GetPortfolioSummary() (*exchange.PortfolioSummary, error) {
-	return a.exchange.GetPortfolio()
+// GetAccounts returns the trading accounts, main account first
+func (a *App) GetAccounts() []exchange.AccountInfo {
+	accounts := make([]exchange.AccountInfo, len(a.accounts))
+	for i, acc := range a.accounts {
+		accounts[i] = acc.info
+	}
+	return accounts
 }
 
-// GetActivePositions returns all active positions
-func (a *App) GetActivePositions() ([]exchange.ActivePosition, error) {
-	return a.exchange.GetPositions()
+// GetPortfolioSummary returns the portfolio summary of account; "" is the
+// main account
+func (a *App) GetPortfolioSummary(account string) (*exchange.PortfolioSummary, error) {
+	acc, err := a.account(account)
+	if err != nil {
+		return nil, err
+	}
+	return acc.adapter.GetPortfolio()
+}
+
+// GetActivePositions returns all active positions of account; "" is the
+// main account
+func (a *App) GetActivePositions(account string) ([]exchange.ActivePosition, error) {
+	acc, err := a.account(account)
+	if err != nil {
+		return nil, err
+	}
+	return acc.adapter.GetPositions()
+}
+
+// GetAggregatedPortfolio returns balances and positions summed across all
+// accounts. Accounts that cannot be queried are reported with an error.
+func (a *App) GetAggregatedPortfolio() exchange.AggregatedPortfolio {
+	portfolios := make([]exchange.AccountPortfolio, len(a.accounts))
+	var wg sync.WaitGroup
+	for i, acc := range a.accounts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			portfolios[i] = accountPortfolio(acc)
+		}()
+	}
+	wg.Wait()
+	return exchange.AggregatePortfolios(portfolios)
 }
 
 // ============================================================================
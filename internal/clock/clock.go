@@ -0,0 +1,127 @@
+// Package clock abstracts the time source of the live engine so it can be
+// driven by a simulated clock when replaying history.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and creates tickers
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks on C until stopped
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the wall clock
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// Sim is a simulated clock that only moves when Set or Advance is called.
+// Like time.Ticker, its tickers drop ticks their reader is not ready for.
+type Sim struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*simTicker
+}
+
+// NewSim creates a simulated clock reading start
+func NewSim(start time.Time) *Sim {
+	return &Sim{now: start}
+}
+
+// Now returns the simulated time
+func (s *Sim) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Set moves the clock to t, firing every ticker due by then. The clock
+// never moves backwards.
+func (s *Sim) Set(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !t.After(s.now) {
+		return
+	}
+	s.now = t
+
+	// Fire in deadline order so readers of several tickers see them in turn
+	sort.Slice(s.tickers, func(i, j int) bool { return s.tickers[i].next.Before(s.tickers[j].next) })
+	for _, ticker := range s.tickers {
+		ticker.fire(t)
+	}
+}
+
+// Advance moves the clock forward by d
+func (s *Sim) Advance(d time.Duration) {
+	s.Set(s.Now().Add(d))
+}
+
+// NewTicker returns a ticker firing every d of simulated time
+func (s *Sim) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ticker := &simTicker{sim: s, c: make(chan time.Time, 1), period: d, next: s.now.Add(d)}
+	s.tickers = append(s.tickers, ticker)
+	return ticker
+}
+
+type simTicker struct {
+	sim    *Sim
+	c      chan time.Time
+	period time.Duration
+	next   time.Time
+}
+
+func (t *simTicker) C() <-chan time.Time { return t.c }
+
+// fire sends one tick if the ticker is due at now and schedules the next
+// deadline after now
+func (t *simTicker) fire(now time.Time) {
+	if now.Before(t.next) {
+		return
+	}
+	select {
+	case t.c <- t.next:
+	default:
+	}
+	missed := now.Sub(t.next) / t.period
+	t.next = t.next.Add((missed + 1) * t.period)
+}
+
+func (t *simTicker) Stop() {
+	t.sim.mu.Lock()
+	defer t.sim.mu.Unlock()
+	for i, ticker := range t.sim.tickers {
+		if ticker == t {
+			t.sim.tickers = append(t.sim.tickers[:i], t.sim.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Verify Sim implements Clock
+var _ Clock = (*Sim)(nil)
@@ -2,45 +2,434 @@ package config
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"os"
+	"path/filepath"
+	"strings"
 
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/BurntSushi/toml"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/sonirico/go-hyperliquid"
+	"gopkg.in/yaml.v3"
+
+	"terminal/internal/risk"
+	"terminal/internal/wallet"
+)
+
+// Networks
+const (
+	NetworkMainnet = "mainnet"
+	NetworkTestnet = "testnet"
+	NetworkCustom  = "custom"
 )
 
-// Config holds application configuration
+// Config holds application configuration. It is built in layers: defaults,
+// then the config file, then HYPERTERMINAL_* environment variables, then
+// command line flags.
 type Config struct {
-	URL        string
-	PrivateKey *ecdsa.PrivateKey
-	Address    string
-	RedisURL   string
+	// Network selects the Hyperliquid API for both market data and trading;
+	// custom uses URL
+	Network string `toml:"network" yaml:"network"`
+	URL     string `toml:"api_url" yaml:"api_url"`
+
+	// SecretPath is a plaintext file holding a hex private key. Prefer
+	// Keystore; SecretPath is only read when no keystore is configured.
+	SecretPath string `toml:"secret_path" yaml:"secret_path"`
+	// Keystore is an encrypted go-ethereum keystore file holding the signing
+	// key. It is unlocked at startup with HYPERTERMINAL_KEYSTORE_PASSWORD or
+	// the contents of KeystorePasswordFile, or later with Unlock.
+	Keystore             string `toml:"keystore" yaml:"keystore"`
+	KeystorePasswordFile string `toml:"keystore_password_file" yaml:"keystore_password_file"`
+	// Address is the account traded and queried for balances and positions.
+	// It defaults to the address of the signing key. When it differs, the
+	// key is a Hyperliquid agent (API) wallet approved by Address, which can
+	// trade but not withdraw. Address may also be set alone to watch an
+	// account without trading.
+	Address string `toml:"address" yaml:"address"`
+	// PrivateKey signs orders; nil means read-only mode. SignerAddress is
+	// its address.
+	PrivateKey    *ecdsa.PrivateKey `toml:"-" yaml:"-"`
+	SignerAddress string            `toml:"-" yaml:"-"`
+
+	// RedisURL is the candle cache; empty disables caching
+	RedisURL string `toml:"redis_url" yaml:"redis_url"`
+	// DataDir stores composite strategies and presets
+	DataDir string `toml:"data_dir" yaml:"data_dir"`
+
+	// Leverage is the default leverage for new positions
+	Leverage int `toml:"leverage" yaml:"leverage"`
+
+	// Accounts are named sub-accounts and vaults traded with the same key
+	// in addition to the main account at Address
+	Accounts []AccountConfig `toml:"accounts" yaml:"accounts"`
+
+	Risk RiskConfig `toml:"risk" yaml:"risk"`
+	Log  LogConfig  `toml:"log" yaml:"log"`
+	API  APIConfig  `toml:"api" yaml:"api"`
 }
 
-// New creates a new Config by reading the private key from .secret file
-func New() Config {
-	pk, err := os.ReadFile(".secret")
-	if err != nil {
-		panic(fmt.Errorf("failed to read .secret file: %w", err))
+// MainAccount is the id of the account at Address
+const MainAccount = "main"
+
+// AccountConfig is a named trading account. Sub-accounts and vaults are
+// traded by signing orders with the main key on behalf of their address.
+type AccountConfig struct {
+	ID string `toml:"id" yaml:"id"`
+	// SubAccount or Vault is the address traded and queried; exactly one is
+	// set except for the main account
+	SubAccount string `toml:"sub_account" yaml:"sub_account"`
+	Vault      string `toml:"vault" yaml:"vault"`
+	// Leverage overrides the default leverage when set
+	Leverage int `toml:"leverage" yaml:"leverage"`
+}
+
+// VaultAddress returns the sub-account or vault address orders are signed
+// for, or "" for the main account
+func (a AccountConfig) VaultAddress() string {
+	if a.Vault != "" {
+		return a.Vault
 	}
-	privateKey, err := crypto.HexToECDSA(string(pk))
-	if err != nil {
-		panic(fmt.Errorf("invalid private key: %w", err))
+	return a.SubAccount
+}
+
+// RiskConfig limits live trading across all accounts. Zero disables a
+// limit.
+type RiskConfig struct {
+	// MaxGrossNotional and MaxNetNotional cap the summed and the long minus
+	// short notional of all positions
+	MaxGrossNotional float64 `toml:"max_gross_notional" yaml:"max_gross_notional"`
+	MaxNetNotional   float64 `toml:"max_net_notional" yaml:"max_net_notional"`
+	// MaxPositionSize caps the size held in one symbol
+	MaxPositionSize float64 `toml:"max_position_size" yaml:"max_position_size"`
+	// MaxLeverage caps the leverage of new positions
+	MaxLeverage int `toml:"max_leverage" yaml:"max_leverage"`
+	// MaxOpenPositions caps the positions open across all strategies
+	MaxOpenPositions int `toml:"max_open_positions" yaml:"max_open_positions"`
+	// MaxDailyLoss and MaxDrawdown halt trading once the loss for the UTC
+	// day, or from the PnL high-water mark, reaches them
+	MaxDailyLoss float64 `toml:"max_daily_loss" yaml:"max_daily_loss"`
+	MaxDrawdown  float64 `toml:"max_drawdown" yaml:"max_drawdown"`
+	// FlattenOnBreach closes all positions and stops all strategies when a
+	// loss limit halts trading
+	FlattenOnBreach bool `toml:"flatten_on_breach" yaml:"flatten_on_breach"`
+}
+
+// Limits returns the limits enforced by the risk manager
+func (r RiskConfig) Limits() risk.Limits {
+	return risk.Limits{
+		MaxGrossNotional: r.MaxGrossNotional,
+		MaxNetNotional:   r.MaxNetNotional,
+		MaxPositionSize:  r.MaxPositionSize,
+		MaxOpenPositions: r.MaxOpenPositions,
+		MaxLeverage:      r.MaxLeverage,
+		MaxDailyLoss:     r.MaxDailyLoss,
+		MaxDrawdown:      r.MaxDrawdown,
+		FlattenOnBreach:  r.FlattenOnBreach,
 	}
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		panic(fmt.Errorf("failed to cast public key to ECDSA"))
+}
+
+// LogConfig sets where logs and the trade journal are written
+type LogConfig struct {
+	Dir     string `toml:"dir" yaml:"dir"`
+	Level   string `toml:"level" yaml:"level"`
+	Journal string `toml:"journal" yaml:"journal"`
+}
+
+// APIConfig controls the local API server and notifications
+type APIConfig struct {
+	// Addr enables the local API server on a loopback address such as
+	// 127.0.0.1:8787. Token authenticates clients; one is generated at
+	// startup when empty.
+	Addr  string `toml:"addr" yaml:"addr"`
+	Token string `toml:"token" yaml:"token"`
+	// WebhookURL receives position, strategy and halt notifications when set
+	WebhookURL string `toml:"webhook_url" yaml:"webhook_url"`
+}
+
+// Dir returns the directory holding the config file and app data
+func Dir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
 	}
+	return filepath.Join(dir, "hyperterminal")
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() Config {
+	dir := Dir()
 	return Config{
-		URL:        hyperliquid.TestnetAPIURL,
-		PrivateKey: privateKey,
-		Address:    crypto.PubkeyToAddress(*publicKeyECDSA).Hex(),
+		Network:    NetworkTestnet,
+		SecretPath: ".secret",
 		RedisURL:   "localhost:6379",
+		DataDir:    dir,
+		Leverage:   10,
+		Log: LogConfig{
+			Dir:     filepath.Join(dir, "logs"),
+			Level:   "info",
+			Journal: filepath.Join(dir, "journal.jsonl"),
+		},
+	}
+}
+
+// Load builds the configuration. path names the config file; when empty,
+// HYPERTERMINAL_CONFIG or config.toml (or config.yaml) in Dir is used if it
+// exists. Files ending in .yaml or .yml are read as YAML, others as TOML.
+// overrides maps setting names to values and takes precedence over
+// everything else.
+func Load(path string, overrides map[string]string) (Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv("HYPERTERMINAL_CONFIG")
+	}
+	if path == "" {
+		path = defaultFile()
+	}
+	if path != "" {
+		if err := decodeFile(path, &cfg); err != nil {
+			return cfg, fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env()); ok {
+			if err := s.set(&cfg, value); err != nil {
+				return cfg, fmt.Errorf("%s: %w", s.env(), err)
+			}
+		}
+	}
+	for name, value := range overrides {
+		s, ok := lookupSetting(name)
+		if !ok {
+			return cfg, fmt.Errorf("unknown setting %q", name)
+		}
+		if err := s.set(&cfg, value); err != nil {
+			return cfg, fmt.Errorf("-%s: %w", name, err)
+		}
+	}
+
+	if err := cfg.loadKey(); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// defaultFile returns the first config file present in Dir, or ""
+func defaultFile() string {
+	for _, name := range []string{"config.toml", "config.yaml", "config.yml"} {
+		path := filepath.Join(Dir(), name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// decodeFile reads a TOML file, or YAML for .yaml and .yml files, over cfg
+func decodeFile(path string, cfg *Config) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(raw, cfg)
+	default:
+		return toml.Unmarshal(raw, cfg)
 	}
 }
 
-// SetSourceURL sets the API URL
-func (c *Config) SetSourceURL(url string) {
-	c.URL = url
+// loadKey reads the signing key. A keystore without a passphrase stays
+// locked, and a missing plaintext key file leaves the config in read-only
+// mode; unreadable or invalid keys are errors.
+func (c *Config) loadKey() error {
+	if c.Keystore != "" {
+		if _, err := os.Stat(c.Keystore); err != nil {
+			return fmt.Errorf("keystore: %w", err)
+		}
+		passphrase, ok := os.LookupEnv("HYPERTERMINAL_KEYSTORE_PASSWORD")
+		if !ok && c.KeystorePasswordFile != "" {
+			raw, err := os.ReadFile(c.KeystorePasswordFile)
+			if err != nil {
+				return fmt.Errorf("keystore password: %w", err)
+			}
+			passphrase, ok = strings.TrimRight(string(raw), "\r\n"), true
+		}
+		if !ok {
+			return nil
+		}
+		return c.Unlock(passphrase)
+	}
+
+	if c.SecretPath == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(c.SecretPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", c.SecretPath, err)
+	}
+	privateKey, err := wallet.ParseHexKey(string(raw))
+	if err != nil {
+		return fmt.Errorf("invalid private key in %s: %w", c.SecretPath, err)
+	}
+	c.setSigner(privateKey)
+	return nil
+}
+
+// Unlock decrypts the keystore with passphrase and makes its key the signer
+func (c *Config) Unlock(passphrase string) error {
+	if c.Keystore == "" {
+		return fmt.Errorf("no keystore configured")
+	}
+	privateKey, err := wallet.ReadKeystore(c.Keystore, passphrase)
+	if err != nil {
+		return err
+	}
+	c.setSigner(privateKey)
+	return nil
+}
+
+func (c *Config) setSigner(privateKey *ecdsa.PrivateKey) {
+	c.PrivateKey = privateKey
+	c.SignerAddress = wallet.Address(privateKey)
+	if c.Address == "" {
+		c.Address = c.SignerAddress
+	}
+}
+
+// ReadOnly reports whether trading is disabled for lack of credentials
+func (c Config) ReadOnly() bool {
+	return c.PrivateKey == nil
+}
+
+// Locked reports whether a keystore is configured but not unlocked yet
+func (c Config) Locked() bool {
+	return c.Keystore != "" && c.PrivateKey == nil
+}
+
+// Agent reports whether the signing key is an agent wallet trading for
+// another account
+func (c Config) Agent() bool {
+	return c.PrivateKey != nil && !strings.EqualFold(c.SignerAddress, c.Address)
+}
+
+// AccountList returns the main account followed by the configured ones
+func (c Config) AccountList() []AccountConfig {
+	return append([]AccountConfig{{ID: MainAccount}}, c.Accounts...)
+}
+
+// Account returns the account with id; "" is the main account
+func (c Config) Account(id string) (AccountConfig, error) {
+	if id == "" {
+		id = MainAccount
+	}
+	for _, account := range c.AccountList() {
+		if account.ID == id {
+			return account, nil
+		}
+	}
+	return AccountConfig{}, fmt.Errorf("unknown account %q", id)
+}
+
+// AccountAddress returns the address traded and queried for an account
+func (c Config) AccountAddress(account AccountConfig) string {
+	if vault := account.VaultAddress(); vault != "" {
+		return vault
+	}
+	return c.Address
+}
+
+// AccountLeverage returns the default leverage of an account
+func (c Config) AccountLeverage(account AccountConfig) int {
+	if account.Leverage > 0 {
+		return account.Leverage
+	}
+	return c.Leverage
+}
+
+// APIURL returns the Hyperliquid API URL of the selected network
+func (c Config) APIURL() string {
+	switch c.Network {
+	case NetworkMainnet:
+		return hyperliquid.MainnetAPIURL
+	case NetworkTestnet:
+		return hyperliquid.TestnetAPIURL
+	default:
+		return c.URL
+	}
+}
+
+// LogLevel returns the parsed log level
+func (c Config) LogLevel() slog.Level {
+	var level slog.Level
+	level.UnmarshalText([]byte(c.Log.Level))
+	return level
+}
+
+// Validate reports every invalid setting
+func (c Config) Validate() error {
+	var errs []error
+	switch c.Network {
+	case NetworkMainnet, NetworkTestnet:
+	case NetworkCustom:
+		if c.URL == "" {
+			errs = append(errs, fmt.Errorf("network custom needs api_url"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown network %q: use mainnet, testnet or custom", c.Network))
+	}
+	if c.Leverage < 1 || c.Leverage > 50 {
+		errs = append(errs, fmt.Errorf("leverage must be between 1 and 50, got %d", c.Leverage))
+	}
+	if c.Risk.MaxGrossNotional < 0 || c.Risk.MaxNetNotional < 0 || c.Risk.MaxPositionSize < 0 ||
+		c.Risk.MaxLeverage < 0 || c.Risk.MaxOpenPositions < 0 || c.Risk.MaxDailyLoss < 0 || c.Risk.MaxDrawdown < 0 {
+		errs = append(errs, fmt.Errorf("risk limits must not be negative"))
+	}
+	if c.Risk.MaxLeverage > 0 && c.Leverage > c.Risk.MaxLeverage {
+		errs = append(errs, fmt.Errorf("leverage %d exceeds risk.max_leverage %d", c.Leverage, c.Risk.MaxLeverage))
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		errs = append(errs, fmt.Errorf("log level: %w", err))
+	}
+	if c.Address != "" && !common.IsHexAddress(c.Address) {
+		errs = append(errs, fmt.Errorf("invalid address %q", c.Address))
+	}
+	seen := map[string]bool{MainAccount: true}
+	for i, account := range c.Accounts {
+		switch {
+		case account.ID == "":
+			errs = append(errs, fmt.Errorf("accounts[%d]: id must not be empty", i))
+		case seen[account.ID]:
+			errs = append(errs, fmt.Errorf("accounts[%d]: duplicate id %q", i, account.ID))
+		}
+		seen[account.ID] = true
+		if (account.SubAccount == "") == (account.Vault == "") {
+			errs = append(errs, fmt.Errorf("account %s: set exactly one of sub_account and vault", account.ID))
+		} else if !common.IsHexAddress(account.VaultAddress()) {
+			errs = append(errs, fmt.Errorf("account %s: invalid address %q", account.ID, account.VaultAddress()))
+		}
+		if account.Leverage < 0 || account.Leverage > 50 {
+			errs = append(errs, fmt.Errorf("account %s: leverage must be between 1 and 50, got %d", account.ID, account.Leverage))
+		}
+		if c.Risk.MaxLeverage > 0 && account.Leverage > c.Risk.MaxLeverage {
+			errs = append(errs, fmt.Errorf("account %s: leverage %d exceeds risk.max_leverage %d", account.ID, account.Leverage, c.Risk.MaxLeverage))
+		}
+	}
+	if c.DataDir == "" {
+		errs = append(errs, fmt.Errorf("data_dir must not be empty"))
+	}
+	if c.API.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.API.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("api addr: %w", err))
+		}
+	}
+	return errors.Join(errs...)
 }
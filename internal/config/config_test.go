@@ -0,0 +1,281 @@
+package config
+
+import (
+	"encoding/hex"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+
+	"terminal/internal/wallet"
+)
+
+// isolate clears every HYPERTERMINAL_* variable for the test and points the
+// config file at an empty one in a temporary directory, returned
+func isolate(t *testing.T) string {
+	t.Helper()
+	names := []string{"HYPERTERMINAL_CONFIG", "HYPERTERMINAL_KEYSTORE_PASSWORD"}
+	for _, s := range settings {
+		names = append(names, s.env())
+	}
+	for _, name := range names {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "empty.toml"), "")
+	t.Setenv("HYPERTERMINAL_CONFIG", filepath.Join(dir, "empty.toml"))
+	return dir
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeKeystore writes a cheaply encrypted keystore and returns its key
+func writeKeystore(t *testing.T, path, passphrase string) string {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := keystore.EncryptKey(&keystore.Key{
+		Id:         uuid.New(),
+		Address:    crypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey: key,
+	}, passphrase, keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(t, path, string(raw))
+	return wallet.Address(key)
+}
+
+func TestKeystoreLockedUntilUnlocked(t *testing.T) {
+	dir := isolate(t)
+	path := filepath.Join(dir, "key.json")
+	address := writeKeystore(t, path, "correct horse")
+
+	cfg, err := Load("", map[string]string{"keystore": path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Locked() || !cfg.ReadOnly() || cfg.Address != "" {
+		t.Fatalf("config %+v not locked and read-only without a passphrase", cfg)
+	}
+	if err := cfg.Unlock("wrong horse"); err == nil || !cfg.Locked() {
+		t.Fatalf("unlocked with the wrong passphrase: %v", err)
+	}
+	if err := cfg.Unlock("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Locked() || cfg.ReadOnly() || cfg.Agent() || cfg.Address != address || cfg.SignerAddress != address {
+		t.Errorf("unlocked config %+v, want to trade as %s", cfg, address)
+	}
+
+	t.Setenv("HYPERTERMINAL_KEYSTORE_PASSWORD", "correct horse")
+	if cfg, err := Load("", map[string]string{"keystore": path}); err != nil || cfg.Locked() {
+		t.Errorf("keystore not unlocked at load from the environment: %v", err)
+	}
+}
+
+func TestLayersOverrideInOrder(t *testing.T) {
+	dir := isolate(t)
+	file := filepath.Join(dir, "config.toml")
+	write(t, file, `
+network = "mainnet"
+leverage = 5
+data_dir = "/data"
+
+[risk]
+max_drawdown = 100
+
+[log]
+level = "warn"
+`)
+	load := func(args ...string) Config {
+		t.Helper()
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags := RegisterFlags(fs)
+		if err := fs.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := flags.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	if cfg := load(); cfg.Network != NetworkTestnet || cfg.Leverage != 10 || cfg.DataDir != Dir() {
+		t.Errorf("defaults %+v", cfg)
+	}
+	cfg := load("-config", file)
+	if cfg.Network != NetworkMainnet || cfg.Leverage != 5 || cfg.DataDir != "/data" || cfg.Risk.MaxDrawdown != 100 || cfg.Log.Level != "warn" {
+		t.Errorf("file did not override the defaults: %+v", cfg)
+	}
+	// Settings the file leaves out keep their defaults
+	if cfg.RedisURL != "localhost:6379" {
+		t.Errorf("redis %q, want the default", cfg.RedisURL)
+	}
+
+	t.Setenv("HYPERTERMINAL_LEVERAGE", "7")
+	t.Setenv("HYPERTERMINAL_FLATTEN_ON_BREACH", "true")
+	cfg = load("-config", file)
+	if cfg.Leverage != 7 || !cfg.Risk.FlattenOnBreach || cfg.Network != NetworkMainnet {
+		t.Errorf("environment did not override the file: %+v", cfg)
+	}
+
+	cfg = load("-config", file, "-leverage", "9", "-log-level", "debug")
+	if cfg.Leverage != 9 || cfg.Log.Level != "debug" || !cfg.Risk.FlattenOnBreach || cfg.Risk.MaxDrawdown != 100 {
+		t.Errorf("flags did not override the environment: %+v", cfg)
+	}
+
+	// HYPERTERMINAL_CONFIG names the file when -config is not given
+	t.Setenv("HYPERTERMINAL_CONFIG", file)
+	if cfg := load(); cfg.Network != NetworkMainnet {
+		t.Errorf("HYPERTERMINAL_CONFIG not read: %+v", cfg)
+	}
+
+	t.Setenv("HYPERTERMINAL_LEVERAGE", "lots")
+	if _, err := Load(file, nil); err == nil || !strings.Contains(err.Error(), "HYPERTERMINAL_LEVERAGE") {
+		t.Errorf("invalid environment value: %v", err)
+	}
+	if _, err := Load(file, map[string]string{"colour": "red"}); err == nil {
+		t.Error("unknown setting accepted")
+	}
+}
+
+func TestYAMLFile(t *testing.T) {
+	dir := isolate(t)
+	file := filepath.Join(dir, "config.yaml")
+	write(t, file, `
+network: mainnet
+leverage: 4
+accounts:
+  - id: vault
+    vault: "0x1111111111111111111111111111111111111111"
+    leverage: 2
+`)
+	cfg, err := Load(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Network != NetworkMainnet || cfg.Leverage != 4 || len(cfg.Accounts) != 1 || cfg.Accounts[0].Leverage != 2 {
+		t.Errorf("yaml config %+v", cfg)
+	}
+	if _, err := Load(filepath.Join(dir, "missing.toml"), nil); err == nil {
+		t.Error("loaded a missing config file")
+	}
+	write(t, file, "leverage: [")
+	if _, err := Load(file, nil); err == nil {
+		t.Error("loaded a malformed config file")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	const address = "0x1111111111111111111111111111111111111111"
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   string
+	}{
+		{"unknown network", func(c *Config) { c.Network = "moon" }, "unknown network"},
+		{"custom without url", func(c *Config) { c.Network = NetworkCustom }, "needs api_url"},
+		{"leverage", func(c *Config) { c.Leverage = 0 }, "leverage must be between 1 and 50"},
+		{"negative limit", func(c *Config) { c.Risk.MaxDailyLoss = -1 }, "must not be negative"},
+		{"leverage above limit", func(c *Config) { c.Risk.MaxLeverage = 5 }, "exceeds risk.max_leverage"},
+		{"log level", func(c *Config) { c.Log.Level = "loud" }, "log level"},
+		{"address", func(c *Config) { c.Address = "0x12" }, "invalid address"},
+		{"account without id", func(c *Config) { c.Accounts = []AccountConfig{{Vault: address}} }, "id must not be empty"},
+		{"main account id", func(c *Config) { c.Accounts = []AccountConfig{{ID: MainAccount, Vault: address}} }, "duplicate id"},
+		{"duplicate account", func(c *Config) {
+			c.Accounts = []AccountConfig{{ID: "a", Vault: address}, {ID: "a", SubAccount: address}}
+		}, "duplicate id"},
+		{"vault and sub-account", func(c *Config) {
+			c.Accounts = []AccountConfig{{ID: "a", Vault: address, SubAccount: address}}
+		}, "set exactly one"},
+		{"neither vault nor sub-account", func(c *Config) { c.Accounts = []AccountConfig{{ID: "a"}} }, "set exactly one"},
+		{"account address", func(c *Config) { c.Accounts = []AccountConfig{{ID: "a", Vault: "vault"}} }, "invalid address"},
+		{"account leverage", func(c *Config) { c.Accounts = []AccountConfig{{ID: "a", Vault: address, Leverage: 60}} }, "leverage must be between 1 and 50"},
+		{"account leverage above limit", func(c *Config) {
+			c.Risk.MaxLeverage = 10
+			c.Accounts = []AccountConfig{{ID: "a", Vault: address, Leverage: 20}}
+		}, "exceeds risk.max_leverage"},
+		{"data dir", func(c *Config) { c.DataDir = "" }, "data_dir"},
+		{"api addr", func(c *Config) { c.API.Addr = "localhost" }, "api addr"},
+	}
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("defaults invalid: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.change(&cfg)
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+
+	// Every invalid setting is reported
+	cfg := Default()
+	cfg.Leverage = 0
+	cfg.DataDir = ""
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "leverage") || !strings.Contains(err.Error(), "data_dir") {
+		t.Errorf("got %v, want both errors", err)
+	}
+}
+
+func TestSecretFile(t *testing.T) {
+	dir := isolate(t)
+	secret := filepath.Join(dir, ".secret")
+
+	// A missing key file is read-only mode, not an error
+	cfg, err := Load("", map[string]string{"secret": secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.ReadOnly() || cfg.Locked() || cfg.Agent() || cfg.Address != "" {
+		t.Errorf("config %+v without a key file, want read-only", cfg)
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(t, secret, "0x"+hex.EncodeToString(crypto.FromECDSA(key))+"\n")
+	cfg, err = Load("", map[string]string{"secret": secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ReadOnly() || cfg.Agent() || cfg.Address != wallet.Address(key) {
+		t.Errorf("config %+v, want to trade as %s", cfg, wallet.Address(key))
+	}
+
+	// A key trading for another address is an agent wallet
+	const account = "0x1111111111111111111111111111111111111111"
+	cfg, err = Load("", map[string]string{"secret": secret, "address": account})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Agent() || cfg.Address != account || cfg.SignerAddress != wallet.Address(key) {
+		t.Errorf("config %+v, want an agent for %s", cfg, account)
+	}
+
+	write(t, secret, "not a key")
+	if _, err := Load("", map[string]string{"secret": secret}); err == nil {
+		t.Error("loaded an invalid key")
+	}
+	if _, err := Load("", map[string]string{"keystore": filepath.Join(dir, "missing.json")}); err == nil {
+		t.Error("loaded a missing keystore")
+	}
+}
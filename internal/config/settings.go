@@ -0,0 +1,129 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// setting is a config value that can be overridden by an environment
+// variable and a command line flag of the same name
+type setting struct {
+	name  string
+	usage string
+	set   func(c *Config, value string) error
+	// boolean settings are flags that take no value
+	boolean bool
+}
+
+// env returns the environment variable of the setting, e.g.
+// HYPERTERMINAL_API_ADDR for api-addr
+func (s setting) env() string {
+	return "HYPERTERMINAL_" + strings.ToUpper(strings.ReplaceAll(s.name, "-", "_"))
+}
+
+var settings = []setting{
+	stringSetting("network", "Hyperliquid network: mainnet, testnet or custom", func(c *Config) *string { return &c.Network }),
+	stringSetting("api-url", "Hyperliquid API URL for the custom network", func(c *Config) *string { return &c.URL }),
+	stringSetting("secret", "plaintext file holding the hex private key; missing means read-only mode", func(c *Config) *string { return &c.SecretPath }),
+	stringSetting("keystore", "encrypted keystore file holding the signing key; replaces -secret", func(c *Config) *string { return &c.Keystore }),
+	stringSetting("keystore-password-file", "file holding the keystore passphrase; HYPERTERMINAL_KEYSTORE_PASSWORD also works", func(c *Config) *string { return &c.KeystorePasswordFile }),
+	stringSetting("address", "account address; derived from the key when empty, the approving account for agent wallets", func(c *Config) *string { return &c.Address }),
+	stringSetting("redis", "Redis address for the candle cache; empty disables caching", func(c *Config) *string { return &c.RedisURL }),
+	stringSetting("data-dir", "directory for composite strategies and presets", func(c *Config) *string { return &c.DataDir }),
+	intSetting("leverage", "default leverage for new positions", func(c *Config) *int { return &c.Leverage }),
+	floatSetting("max-gross-notional", "largest summed notional of all positions; 0 disables", func(c *Config) *float64 { return &c.Risk.MaxGrossNotional }),
+	floatSetting("max-net-notional", "largest long minus short notional; 0 disables", func(c *Config) *float64 { return &c.Risk.MaxNetNotional }),
+	floatSetting("max-position-size", "largest size held in one symbol across accounts; 0 disables", func(c *Config) *float64 { return &c.Risk.MaxPositionSize }),
+	intSetting("max-leverage", "highest leverage allowed; 0 disables", func(c *Config) *int { return &c.Risk.MaxLeverage }),
+	intSetting("max-open-positions", "most positions open across all strategies; 0 disables", func(c *Config) *int { return &c.Risk.MaxOpenPositions }),
+	floatSetting("max-daily-loss", "loss per UTC day that halts trading; 0 disables", func(c *Config) *float64 { return &c.Risk.MaxDailyLoss }),
+	floatSetting("max-drawdown", "loss from the PnL high-water mark that halts trading; 0 disables", func(c *Config) *float64 { return &c.Risk.MaxDrawdown }),
+	boolSetting("flatten-on-breach", "close all positions and stop all strategies when a loss limit halts trading", func(c *Config) *bool { return &c.Risk.FlattenOnBreach }),
+	stringSetting("log-dir", "directory for per-strategy log files", func(c *Config) *string { return &c.Log.Dir }),
+	stringSetting("log-level", "log level: debug, info, warn or error", func(c *Config) *string { return &c.Log.Level }),
+	stringSetting("journal", "trade journal file", func(c *Config) *string { return &c.Log.Journal }),
+	stringSetting("api-addr", "loopback address of the local API server; empty disables it", func(c *Config) *string { return &c.API.Addr }),
+	stringSetting("api-token", "local API token; generated at startup when empty", func(c *Config) *string { return &c.API.Token }),
+	stringSetting("webhook-url", "URL receiving position, strategy and halt notifications", func(c *Config) *string { return &c.API.WebhookURL }),
+}
+
+func lookupSetting(name string) (setting, bool) {
+	for _, s := range settings {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+func stringSetting(name, usage string, field func(*Config) *string) setting {
+	return setting{name: name, usage: usage, set: func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}}
+}
+
+func intSetting(name, usage string, field func(*Config) *int) setting {
+	return setting{name: name, usage: usage, set: func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*field(c) = n
+		return nil
+	}}
+}
+
+func floatSetting(name, usage string, field func(*Config) *float64) setting {
+	return setting{name: name, usage: usage, set: func(c *Config, value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		*field(c) = f
+		return nil
+	}}
+}
+
+func boolSetting(name, usage string, field func(*Config) *bool) setting {
+	return setting{name: name, usage: usage, boolean: true, set: func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		*field(c) = b
+		return nil
+	}}
+}
+
+// Flags holds the config flags set on a command line
+type Flags struct {
+	path   string
+	values map[string]string
+}
+
+// RegisterFlags adds -config and a flag per setting to fs. Only flags given
+// on the command line override the file and environment.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{values: map[string]string{}}
+	fs.StringVar(&f.path, "config", "", "config file (TOML or YAML); defaults to $HYPERTERMINAL_CONFIG or config.toml in the app config dir")
+	for _, s := range settings {
+		set := func(value string) error {
+			f.values[s.name] = value
+			return nil
+		}
+		if s.boolean {
+			fs.BoolFunc(s.name, s.usage+" (env "+s.env()+")", set)
+			continue
+		}
+		fs.Func(s.name, s.usage+" (env "+s.env()+")", set)
+	}
+	return f
+}
+
+// Load builds the configuration with the parsed flags applied last
+func (f *Flags) Load() (Config, error) {
+	return Load(f.path, f.values)
+}
@@ -0,0 +1,296 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"terminal/internal/logging"
+)
+
+// FundingRate is one hourly funding payment of a perpetual
+type FundingRate struct {
+	// Time is when the payment was made, in unix milliseconds
+	Time int64 `json:"time"`
+	// Rate is the hourly funding rate; longs pay shorts when it is positive
+	Rate float64 `json:"rate"`
+	// Premium is the premium of the perpetual over the oracle price the
+	// rate was based on
+	Premium float64 `json:"premium"`
+}
+
+// MarketContext is a snapshot of the market of a perpetual
+type MarketContext struct {
+	// Time is when the snapshot was taken, in unix milliseconds
+	Time int64 `json:"time"`
+	// Funding is the predicted rate of the next funding payment
+	Funding      float64 `json:"funding"`
+	Premium      float64 `json:"premium"`
+	OpenInterest float64 `json:"openInterest"`
+	MarkPrice    float64 `json:"markPrice"`
+	OraclePrice  float64 `json:"oraclePrice"`
+}
+
+// fundingPerRequest is the most funding payments the API returns at once
+const fundingPerRequest = 500
+
+// marketRetention is how long market snapshots are kept in Redis
+const marketRetention = 30 * 24 * time.Hour
+
+// fundingRetention is how long cached funding payments of a symbol are kept
+// after they were last fetched
+const fundingRetention = 30 * 24 * time.Hour
+
+// FetchFundingHistory fetches the funding payments of symbol made from start
+// to end, unix milliseconds, oldest first, with caching. The payments of a
+// symbol are cached together with the time they are complete from, so a
+// longer or later range only fetches the payments the cache lacks and
+// merges them in.
+func (s *Source) FetchFundingHistory(symbol string, start, end int64) ([]FundingRate, error) {
+	cached, since := s.cachedFunding(symbol, start)
+
+	// The cache holds every payment from since on up to its latest one, and
+	// payments are made on the hour
+	from := start
+	if since > 0 && since <= start && len(cached) > 0 {
+		last := cached[len(cached)-1].Time
+		if last >= time.UnixMilli(end).Truncate(time.Hour).UnixMilli() {
+			slog.Debug("funding from cache", logging.KeySymbol, symbol, "count", len(cached))
+			return until(cached, end), nil
+		}
+		from = last + 1
+	}
+
+	fetched, err := s.fetchFunding(symbol, from, end)
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("funding fetched", logging.KeySymbol, symbol, "count", len(fetched), "cached", len(cached))
+
+	rates := fetched
+	switch {
+	case from > start:
+		rates = append(cached, fetched...)
+		if len(fetched) > 0 {
+			go s.recordFunding(symbol, fetched, since)
+		}
+	case since == 0 || since <= start || end >= since:
+		go s.recordFunding(symbol, fetched, start)
+	default:
+		// The payments end before the cached ones begin, and the gap
+		// between them would make the cache incomplete
+	}
+	return until(rates, end), nil
+}
+
+// until returns the leading payments made at or before end
+func until(rates []FundingRate, end int64) []FundingRate {
+	n := sort.Search(len(rates), func(i int) bool { return rates[i].Time > end })
+	return rates[:n]
+}
+
+// fetchFunding fetches the funding payments of symbol from start to end,
+// unix milliseconds, oldest first
+func (s *Source) fetchFunding(symbol string, start, end int64) ([]FundingRate, error) {
+	rates := []FundingRate{}
+	for start <= end {
+		ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
+		batch, err := s.info.FundingHistory(ctx, symbol, start, &end)
+		cancel()
+		if err != nil {
+			slog.Debug("funding fetch failed", logging.KeySymbol, symbol, "error", err)
+			return nil, fmt.Errorf("failed to fetch funding history: %w", err)
+		}
+		for _, f := range batch {
+			rates = append(rates, FundingRate{Time: f.Time, Rate: ParseFloat(f.FundingRate), Premium: ParseFloat(f.Premium)})
+		}
+		if len(batch) < fundingPerRequest {
+			break
+		}
+		start = batch[len(batch)-1].Time + 1
+	}
+	return rates, nil
+}
+
+func fundingKeys(symbol string) (payments, since string) {
+	return "funding:" + symbol + ":payments", "funding:" + symbol + ":since"
+}
+
+// cachedFunding reads the cached funding payments of symbol from start on,
+// oldest first, and the time the cache is complete from, zero when nothing
+// is cached
+func (s *Source) cachedFunding(symbol string, start int64) ([]FundingRate, int64) {
+	if !s.cacheEnabled {
+		return nil, 0
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, 2*time.Second)
+	defer cancel()
+	paymentsKey, sinceKey := fundingKeys(symbol)
+	since, err := s.redisClient.Get(ctx, sinceKey).Int64()
+	if err != nil {
+		return nil, 0
+	}
+	members, err := s.redisClient.ZRangeByScore(ctx, paymentsKey, &redis.ZRangeBy{
+		Min: strconv.FormatInt(start, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, 0
+	}
+
+	rates := make([]FundingRate, 0, len(members))
+	for _, member := range members {
+		var rate FundingRate
+		if err := json.Unmarshal([]byte(member), &rate); err != nil {
+			return nil, 0
+		}
+		rates = append(rates, rate)
+	}
+	return rates, since
+}
+
+// recordFunding merges fetched funding payments into the cache of symbol,
+// which is then complete from since on
+func (s *Source) recordFunding(symbol string, rates []FundingRate, since int64) {
+	if !s.cacheEnabled {
+		return
+	}
+	members := make([]redis.Z, 0, len(rates))
+	for _, rate := range rates {
+		data, err := json.Marshal(rate)
+		if err != nil {
+			return
+		}
+		members = append(members, redis.Z{Score: float64(rate.Time), Member: data})
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, 2*time.Second)
+	defer cancel()
+	paymentsKey, sinceKey := fundingKeys(symbol)
+	pipe := s.redisClient.TxPipeline()
+	if len(members) > 0 {
+		pipe.ZAdd(ctx, paymentsKey, members...)
+	}
+	pipe.Set(ctx, sinceKey, since, fundingRetention)
+	pipe.Expire(ctx, paymentsKey, fundingRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.Debug("funding cache write failed", logging.KeySymbol, symbol, "error", err)
+	}
+}
+
+// FetchMarketContext fetches the current funding, open interest, mark and
+// oracle price of symbol. Every snapshot is recorded in Redis for
+// FetchMarketHistory, as the API keeps no history of them.
+func (s *Source) FetchMarketContext(symbol string) (MarketContext, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
+	defer cancel()
+	meta, err := s.info.MetaAndAssetCtxs(ctx)
+	if err != nil {
+		return MarketContext{}, fmt.Errorf("failed to fetch market context: %w", err)
+	}
+
+	for i, asset := range meta.Universe {
+		if asset.Name != symbol || i >= len(meta.Ctxs) {
+			continue
+		}
+		c := meta.Ctxs[i]
+		market := MarketContext{
+			Time:         time.Now().UnixMilli(),
+			Funding:      ParseFloat(c.Funding),
+			Premium:      ParseFloat(c.Premium),
+			OpenInterest: ParseFloat(c.OpenInterest),
+			MarkPrice:    ParseFloat(c.MarkPx),
+			OraclePrice:  ParseFloat(c.OraclePx),
+		}
+		go s.recordMarket(symbol, market)
+		return market, nil
+	}
+	return MarketContext{}, fmt.Errorf("no market context for %s", symbol)
+}
+
+// FetchMarketHistory returns the market snapshots of symbol recorded since
+// the unix millisecond timestamp since, oldest first. Snapshots are only
+// kept while Redis is available, so the history may be empty.
+func (s *Source) FetchMarketHistory(symbol string, since int64) ([]MarketContext, error) {
+	if !s.cacheEnabled {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, 2*time.Second)
+	defer cancel()
+	members, err := s.redisClient.ZRangeByScore(ctx, marketKey(symbol), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read market history: %w", err)
+	}
+
+	markets := make([]MarketContext, 0, len(members))
+	for _, member := range members {
+		var market MarketContext
+		if err := json.Unmarshal([]byte(member), &market); err == nil {
+			markets = append(markets, market)
+		}
+	}
+	return markets, nil
+}
+
+func marketKey(symbol string) string {
+	return "market:" + symbol
+}
+
+// recordMarket stores a market snapshot and drops the ones past retention
+func (s *Source) recordMarket(symbol string, market MarketContext) {
+	if !s.cacheEnabled {
+		return
+	}
+	data, err := json.Marshal(market)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, 500*time.Millisecond)
+	defer cancel()
+	key := marketKey(symbol)
+	pipe := s.redisClient.Pipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(market.Time), Member: data})
+	cutoff := market.Time - marketRetention.Milliseconds()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(cutoff, 10))
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.Debug("market snapshot write failed", logging.KeySymbol, symbol, "error", err)
+	}
+}
+
+// getJSON reads a cached value into v
+func (s *Source) getJSON(key string, v any) bool {
+	if !s.cacheEnabled {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, 500*time.Millisecond)
+	defer cancel()
+	data, err := s.redisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// setJSON caches v for ttl
+func (s *Source) setJSON(key string, v any, ttl time.Duration) {
+	if !s.cacheEnabled {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, 500*time.Millisecond)
+	defer cancel()
+	if err := s.redisClient.Set(ctx, key, data, ttl).Err(); err != nil {
+		slog.Debug("cache write failed", "key", key, "error", err)
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	hyperliquid "github.com/sonirico/go-hyperliquid"
+
+	"terminal/internal/logging"
 )
 
 // ParseFloat converts a string to float64
@@ -25,9 +28,10 @@ type Source struct {
 	cacheEnabled bool
 }
 
-// NewSource creates a new data source
-func NewSource() *Source {
-	info := hyperliquid.NewInfo(context.Background(), hyperliquid.MainnetAPIURL, true, nil, nil)
+// NewSource creates a new data source reading from the Hyperliquid API at
+// apiURL
+func NewSource(apiURL string) *Source {
+	info := hyperliquid.NewInfo(context.Background(), apiURL, true, nil, nil)
 	return &Source{
 		info: info,
 		ctx:  context.Background(),
@@ -45,7 +49,9 @@ func (s *Source) SetRedis(client *redis.Client) {
 	if client != nil {
 		ctx, cancel := context.WithTimeout(s.ctx, 2*time.Second)
 		defer cancel()
-		if err := client.Ping(ctx).Err(); err == nil {
+		if err := client.Ping(ctx).Err(); err != nil {
+			slog.Warn("redis unavailable, candle cache disabled", "error", err)
+		} else {
 			s.cacheEnabled = true
 		}
 	}
@@ -104,12 +110,15 @@ func (s *Source) setToCache(symbol, interval string, limit int, candles []hyperl
 	}
 	ctx, cancel := context.WithTimeout(s.ctx, 500*time.Millisecond)
 	defer cancel()
-	s.redisClient.Set(ctx, key, data, ttl)
+	if err := s.redisClient.Set(ctx, key, data, ttl).Err(); err != nil {
+		slog.Debug("candle cache write failed", logging.KeySymbol, symbol, "interval", interval, "error", err)
+	}
 }
 
 // FetchHistoricalCandles fetches candles with caching
 func (s *Source) FetchHistoricalCandles(symbol string, interval string, limit int) ([]hyperliquid.Candle, error) {
 	if candles, found := s.getFromCache(symbol, interval, limit); found {
+		slog.Debug("candles from cache", logging.KeySymbol, symbol, "interval", interval, "count", len(candles))
 		return candles, nil
 	}
 
@@ -188,9 +197,11 @@ func (s *Source) fetchSingleBatch(symbol string, interval string, limit int, bef
 	)
 
 	if err != nil {
+		slog.Debug("candle fetch failed", logging.KeySymbol, symbol, "interval", interval, "error", err)
 		return nil, fmt.Errorf("failed to fetch candles: %w", err)
 	}
 
+	slog.Debug("candles fetched", logging.KeySymbol, symbol, "interval", interval, "count", len(candles))
 	return candles, nil
 }
 
@@ -233,50 +244,34 @@ func IntervalDuration(interval string) time.Duration {
 	}
 }
 
-// InvalidateCache clears all cached candles
+// InvalidateCache clears all cached candles and funding payments
 func (s *Source) InvalidateCache() error {
-	if !s.cacheEnabled {
-		return nil
-	}
-	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
-	defer cancel()
-
-	var keys []string
-	iter := s.redisClient.Scan(ctx, 0, "candles:*", 100).Iterator()
-	for iter.Next(ctx) {
-		keys = append(keys, iter.Val())
-	}
-	if err := iter.Err(); err != nil {
-		return err
-	}
-
-	if len(keys) > 0 {
-		pipe := s.redisClient.Pipeline()
-		for _, key := range keys {
-			pipe.Del(ctx, key)
-		}
-		_, err := pipe.Exec(ctx)
-		return err
-	}
-	return nil
+	return s.deleteKeys("candles:*", "funding:*")
 }
 
-// InvalidateCacheForSymbol clears cached candles for a specific symbol
+// InvalidateCacheForSymbol clears cached candles and funding payments for a
+// specific symbol
 func (s *Source) InvalidateCacheForSymbol(symbol string) error {
+	return s.deleteKeys(fmt.Sprintf("candles:%s:*", symbol), fmt.Sprintf("funding:%s:*", symbol))
+}
+
+// deleteKeys deletes every cache key matching one of patterns
+func (s *Source) deleteKeys(patterns ...string) error {
 	if !s.cacheEnabled {
 		return nil
 	}
-	pattern := fmt.Sprintf("candles:%s:*", symbol)
 	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
 	defer cancel()
 
 	var keys []string
-	iter := s.redisClient.Scan(ctx, 0, pattern, 100).Iterator()
-	for iter.Next(ctx) {
-		keys = append(keys, iter.Val())
-	}
-	if err := iter.Err(); err != nil {
-		return err
+	for _, pattern := range patterns {
+		iter := s.redisClient.Scan(ctx, 0, pattern, 100).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
 	}
 
 	if len(keys) > 0 {
@@ -1,9 +1,12 @@
 package engine
 
 import (
+	"math"
+	"sort"
 	"strconv"
 	"time"
 
+	"terminal/internal/clock"
 	"terminal/internal/exchange"
 	"terminal/internal/strategy"
 
@@ -12,11 +15,20 @@ import (
 
 // Backtester runs backtests using signals from any strategy
 // This decouples backtesting logic from strategies
-type Backtester struct{}
+type Backtester struct {
+	// clock is the time perp data and divergence periods are taken up to
+	clock clock.Clock
+}
 
 // NewBacktester creates a new backtester
 func NewBacktester() *Backtester {
-	return &Backtester{}
+	return &Backtester{clock: clock.Real}
+}
+
+// SetClock replaces the wall clock, e.g. with a simulated one to backtest
+// as of a past time
+func (b *Backtester) SetClock(clk clock.Clock) {
+	b.clock = clk
 }
 
 // Run executes a backtest with the given signals and configuration
@@ -29,6 +41,192 @@ func (b *Backtester) Run(
 	strategyVersion string,
 ) *BacktestResult {
 	positions := b.simulatePositions(candles, signals, config)
+	return b.buildResult(positions, signals, visualization, strategyName, strategyVersion)
+}
+
+// RunStrategy backtests an initialized strategy over the last limit candles.
+// The strategy's warm-up bars are fetched in addition to limit and excluded
+// from the results, so every reported bar has fully formed indicators.
+func (b *Backtester) RunStrategy(
+	source CandleSource,
+	strat strategy.Strategy,
+	symbol string,
+	interval string,
+	limit int,
+	config ExecutionConfig,
+) (*BacktestResult, error) {
+	meta := strat.GetMetadata()
+	candles, err := source.FetchHistoricalCandles(symbol, interval, limit+meta.WarmupBars)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkHistory(meta, len(candles)); err != nil {
+		return nil, err
+	}
+
+	// Load any extra feeds the strategy declared
+	dataCtx, err := LoadDataContext(source, meta, symbol, interval, candles, b.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	return b.RunContext(strat, dataCtx, limit, config)
+}
+
+// RunContext backtests an initialized strategy over the last limit primary
+// candles of an already loaded context, excluding the warm-up like
+// RunStrategy. A limit <= 0 uses every bar after the warm-up.
+func (b *Backtester) RunContext(
+	strat strategy.Strategy,
+	dataCtx *strategy.DataContext,
+	limit int,
+	config ExecutionConfig,
+) (*BacktestResult, error) {
+	meta := strat.GetMetadata()
+	n := len(dataCtx.Candles)
+	if err := checkHistory(meta, n); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = n
+	}
+
+	signals := strategy.GenerateSignals(strat, dataCtx)
+	visualization := strategy.GetVisualization(strat, dataCtx)
+
+	trim := warmupTrim(meta, n, limit)
+	return b.Run(
+		dataCtx.Candles[trim:],
+		trimSignals(signals, trim),
+		visualization.Trim(trim),
+		config,
+		meta.Name,
+		meta.Version,
+	), nil
+}
+
+// RunBasketStrategy backtests an initialized multi-asset strategy over the
+// last limit aligned bars, excluding the warm-up like RunStrategy
+func (b *Backtester) RunBasketStrategy(
+	source CandleSource,
+	strat strategy.MultiAssetStrategy,
+	symbols []string,
+	interval string,
+	limit int,
+	config ExecutionConfig,
+) (*BacktestResult, error) {
+	meta := strat.GetMetadata()
+	basket, err := LoadBasket(source, symbols, interval, limit+meta.WarmupBars)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkHistory(meta, basket.Len()); err != nil {
+		return nil, err
+	}
+
+	signals := strat.GenerateBasketSignals(basket)
+	visualization := strat.GetBasketVisualization(basket)
+
+	trim := warmupTrim(meta, basket.Len(), limit)
+	return b.RunBasket(
+		basket.Slice(trim),
+		trimSignals(signals, trim),
+		visualization.Trim(trim),
+		config,
+		meta.Name,
+		meta.Version,
+	), nil
+}
+
+// warmupTrim returns how many leading bars to drop: at least the warm-up,
+// and any extra beyond the limit requested
+func warmupTrim(meta strategy.Metadata, n int, limit int) int {
+	return min(max(meta.WarmupBars, n-limit), n)
+}
+
+// trimSignals drops signals before start and shifts the rest so their
+// indices refer to candles[start:]
+func trimSignals(signals []exchange.Signal, start int) []exchange.Signal {
+	trimmed := make([]exchange.Signal, 0, len(signals))
+	for _, signal := range signals {
+		if signal.Index < start {
+			continue
+		}
+		signal.Index -= start
+		trimmed = append(trimmed, signal)
+	}
+	return trimmed
+}
+
+// RunBasket executes a backtest for a multi-asset strategy.
+// Each leg is simulated on its own aligned candles; positions carry their
+// symbol and metrics are computed over all legs together.
+func (b *Backtester) RunBasket(
+	basket *strategy.Basket,
+	signals []exchange.Signal,
+	visualization *strategy.Visualization,
+	config ExecutionConfig,
+	strategyName string,
+	strategyVersion string,
+) *BacktestResult {
+	positions := []exchange.Position{}
+	executed := filterLegGroups(signals, config.TradeDirection)
+	for _, symbol := range basket.Symbols {
+		var legSignals []exchange.Signal
+		for _, signal := range executed {
+			if signal.Symbol == symbol {
+				legSignals = append(legSignals, signal)
+			}
+		}
+		if len(legSignals) == 0 {
+			continue
+		}
+
+		for _, pos := range b.simulatePositions(basket.Candles[symbol], legSignals, config) {
+			pos.Symbol = symbol
+			positions = append(positions, pos)
+		}
+	}
+	sort.SliceStable(positions, func(i, j int) bool {
+		return positions[i].EntryIndex < positions[j].EntryIndex
+	})
+
+	return b.buildResult(positions, signals, visualization, strategyName, strategyVersion)
+}
+
+// filterLegGroups drops the entries and scale-ins of every bar whose leg
+// group has an entry against the trade direction, so the hedge is skipped
+// as a whole like in position.Manager.ExecuteLegs. Exits are kept.
+func filterLegGroups(signals []exchange.Signal, direction string) []exchange.Signal {
+	filtered := make(map[int]bool)
+	for _, signal := range signals {
+		side := signal.Type.Side()
+		if (direction == "long" && side == "short") || (direction == "short" && side == "long") {
+			filtered[signal.Index] = true
+		}
+	}
+	if len(filtered) == 0 {
+		return signals
+	}
+
+	kept := make([]exchange.Signal, 0, len(signals))
+	for _, signal := range signals {
+		opens := signal.Type.IsEntry() || signal.Type == exchange.SignalScaleIn
+		if opens && filtered[signal.Index] {
+			continue
+		}
+		kept = append(kept, signal)
+	}
+	return kept
+}
+
+// buildResult computes metrics for the simulated positions
+func (b *Backtester) buildResult(
+	positions []exchange.Position,
+	signals []exchange.Signal,
+	visualization *strategy.Visualization,
+	strategyName string,
+	strategyVersion string,
+) *BacktestResult {
 	metrics := b.calculateMetrics(positions)
 
 	result := &BacktestResult{
@@ -67,6 +265,8 @@ func (b *Backtester) Run(
 }
 
 // simulatePositions creates positions based on signals
+// Candles are walked bar by bar so that stops and targets attached by
+// signals are checked against each bar's range before that bar's signals.
 func (b *Backtester) simulatePositions(
 	candles []hyperliquid.Candle,
 	signals []exchange.Signal,
@@ -75,24 +275,65 @@ func (b *Backtester) simulatePositions(
 	positions := []exchange.Position{}
 	var currentPosition *exchange.Position
 
-	for _, signal := range signals {
-		if signal.Type != exchange.SignalLong && signal.Type != exchange.SignalShort {
-			continue
+	next := 0
+	for i, candle := range candles {
+		if currentPosition != nil && currentPosition.IsOpen && i > currentPosition.EntryIndex {
+			exitPrice, reason, hit := currentPosition.CheckAttachedExits(
+				parseFloat(candle.Open),
+				parseFloat(candle.High),
+				parseFloat(candle.Low),
+			)
+			if hit {
+				b.closePosition(candles, currentPosition, i, exitPrice, reason)
+				positions = append(positions, *currentPosition)
+				currentPosition = nil
+			}
 		}
 
-		side := "long"
-		if signal.Type == exchange.SignalShort {
-			side = "short"
+		for next < len(signals) && signals[next].Index <= i {
+			signal := signals[next]
+			next++
+			currentPosition, positions = b.applySignal(candles, signal, currentPosition, positions, config)
 		}
+	}
+
+	// Close any remaining position at end of period
+	if currentPosition != nil && currentPosition.IsOpen {
+		lastCandle := candles[len(candles)-1]
+		lastPrice := parseFloat(lastCandle.Close)
+		b.closePosition(candles, currentPosition, len(candles)-1, lastPrice, "End of Period")
+		positions = append(positions, *currentPosition)
+	}
+
+	return positions
+}
+
+// applySignal applies a single signal to the simulated position.
+// It mirrors position.Manager.HandleSignal so backtests match live behaviour.
+func (b *Backtester) applySignal(
+	candles []hyperliquid.Candle,
+	signal exchange.Signal,
+	currentPosition *exchange.Position,
+	positions []exchange.Position,
+	config ExecutionConfig,
+) (*exchange.Position, []exchange.Position) {
+	isOpen := currentPosition != nil && currentPosition.IsOpen
+
+	switch signal.Type {
+	case exchange.SignalLong, exchange.SignalShort:
+		side := signal.Type.Side()
 
 		// Filter by trade direction
 		if (config.TradeDirection == "long" && side == "short") ||
 			(config.TradeDirection == "short" && side == "long") {
-			continue
+			return currentPosition, positions
 		}
 
-		// Close existing position on reversal
-		if currentPosition != nil && currentPosition.IsOpen {
+		if isOpen {
+			if currentPosition.Side == side {
+				return currentPosition, positions
+			}
+			// Close existing position on reversal
 			b.closePosition(candles, currentPosition, signal.Index, signal.Price, "Trend Reversal")
 			positions = append(positions, *currentPosition)
 		}
@@ -103,20 +344,43 @@ func (b *Backtester) simulatePositions(
 			EntryPrice: signal.Price,
 			EntryTime:  signal.Time,
 			Side:       side,
-			Size:       config.PositionSize,
+			Size:       signal.EntrySize(config.PositionSize),
 			IsOpen:     true,
 		}
-	}
+		currentPosition.AttachExits(signal)
 
-	// Close any remaining position at end of period
-	if currentPosition != nil && currentPosition.IsOpen {
-		lastCandle := candles[len(candles)-1]
-		lastPrice := parseFloat(lastCandle.Close)
-		b.closePosition(candles, currentPosition, len(candles)-1, lastPrice, "End of Period")
+	case exchange.SignalScaleIn:
+		if isOpen {
+			currentPosition.AddSize(signal.EntrySize(config.PositionSize), signal.Price)
+			currentPosition.AttachExits(signal)
+		}
+
+	case exchange.SignalExitLong, exchange.SignalExitShort, exchange.SignalFlatten, exchange.SignalPartialExit:
+		if !isOpen || !signal.Type.Closes(currentPosition.Side) {
+			return currentPosition, positions
+		}
+
+		reason := signal.Reason
+		if reason == "" {
+			reason = signal.Type.String()
+		}
+
+		if fraction := signal.ExitFraction(); fraction < 1 {
+			// Record the exited portion as its own trade and keep the rest open
+			closed := *currentPosition
+			closed.Size = currentPosition.Size * fraction
+			b.closePosition(candles, &closed, signal.Index, signal.Price, reason)
+			positions = append(positions, closed)
+			currentPosition.Size -= closed.Size
+			return currentPosition, positions
+		}
+
+		b.closePosition(candles, currentPosition, signal.Index, signal.Price, reason)
 		positions = append(positions, *currentPosition)
+		currentPosition = nil
 	}
 
-	return positions
+	return currentPosition, positions
 }
 
 func (b *Backtester) closePosition(
@@ -172,6 +436,10 @@ func (b *Backtester) calculateMetrics(positions []exchange.Position) backtestMet
 	var winStreak, lossStreak, currentWinStreak, currentLossStreak int
 	var totalHoldTime time.Duration
 	var totalCapitalInvested float64
+	// equity is the cumulative PnL of the closed trades and peak its high
+	var equity, peak float64
+	// returns are the PnL of each trade relative to the capital it tied up
+	var returns []float64
 
 	for _, pos := range positions {
 		if pos.IsOpen {
@@ -183,6 +451,9 @@ func (b *Backtester) calculateMetrics(positions []exchange.Position) backtestMet
 
 		capitalInvested := pos.Size * pos.EntryPrice
 		totalCapitalInvested += capitalInvested
+		if capitalInvested > 0 {
+			returns = append(returns, pos.PnL/capitalInvested)
+		}
 
 		if pos.PnL > 0 {
 			result.winningTrades++
@@ -202,9 +473,9 @@ func (b *Backtester) calculateMetrics(positions []exchange.Position) backtestMet
 			}
 		}
 
-		if pos.MaxDrawdown < result.maxDrawdown {
-			result.maxDrawdown = pos.MaxDrawdown
-		}
+		equity += pos.PnL
+		peak = max(peak, equity)
+		result.maxDrawdown = min(result.maxDrawdown, equity-peak)
 
 		holdTime := time.Duration(pos.ExitTime-pos.EntryTime) * time.Millisecond
 		totalHoldTime += holdTime
@@ -217,6 +488,7 @@ func (b *Backtester) calculateMetrics(positions []exchange.Position) backtestMet
 		avgCapitalInvested := totalCapitalInvested / float64(result.totalTrades)
 		if avgCapitalInvested > 0 {
 			result.totalPnLPercent = (result.totalPnL / avgCapitalInvested) * 100
+			result.maxDrawdownPercent = (result.maxDrawdown / avgCapitalInvested) * 100
 		}
 	}
 
@@ -232,10 +504,34 @@ func (b *Backtester) calculateMetrics(positions []exchange.Position) backtestMet
 
 	result.longestWinStreak = winStreak
 	result.longestLossStreak = lossStreak
+	result.sharpeRatio = sharpeRatio(returns)
 
 	return result
 }
 
+// sharpeRatio returns the mean of per-trade returns over their sample
+// standard deviation, unannualized, or 0 for fewer than two trades or
+// returns that never vary
+func sharpeRatio(returns []float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	if std == 0 {
+		return 0
+	}
+	return mean / std
+}
+
 func parseFloat(s string) float64 {
 	f, _ := strconv.ParseFloat(s, 64)
 	return f
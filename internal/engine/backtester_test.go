@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"terminal/internal/exchange"
+	"terminal/internal/strategy"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+func TestSharpeRatioFromTradeReturns(t *testing.T) {
+	// Returns of 2%, -1% and 5% on 1000 of capital each
+	var trades []exchange.Position
+	for _, pnl := range []float64{20, -10, 50} {
+		trades = append(trades, exchange.Position{Side: "long", Size: 10, EntryPrice: 100, PnL: pnl})
+	}
+	got := NewBacktester().calculateMetrics(trades).sharpeRatio
+	// Mean 0.02 over a sample standard deviation of 0.03
+	if math.Abs(got-2.0/3) > 1e-9 {
+		t.Errorf("sharpe ratio %v, want 2/3", got)
+	}
+
+	if got := NewBacktester().calculateMetrics(trades[:1]).sharpeRatio; got != 0 {
+		t.Errorf("sharpe ratio of a single trade %v, want 0", got)
+	}
+}
+
+func TestFlattenIgnoresSizeFraction(t *testing.T) {
+	candles, _ := (&tickSource{}).FetchHistoricalCandles("BTC", "1m", 5)
+	signals := []exchange.Signal{
+		{Index: 1, Type: exchange.SignalLong, Price: 100, Time: candles[1].Timestamp},
+		{Index: 3, Type: exchange.SignalFlatten, Price: 100, Time: candles[3].Timestamp, SizeFraction: 0.5},
+	}
+	result := NewBacktester().Run(candles, signals, nil, testConfig, "test", "1")
+	if len(result.Positions) != 1 || result.Positions[0].IsOpen || result.Positions[0].Size != testConfig.PositionSize {
+		t.Errorf("flatten at half size left %+v, want the whole position closed", result.Positions)
+	}
+}
+
+func TestRunBasketFiltersWholeGroup(t *testing.T) {
+	candles, _ := (&tickSource{}).FetchHistoricalCandles("BTC", "1m", 5)
+	basket := strategy.NewBasket("1m", []string{"BTC", "ETH"}, map[string][]hyperliquid.Candle{"BTC": candles, "ETH": candles})
+	signals := []exchange.Signal{
+		{Index: 1, Type: exchange.SignalLong, Price: 100, Symbol: "BTC"},
+		{Index: 1, Type: exchange.SignalShort, Price: 100, Symbol: "ETH"},
+		{Index: 2, Type: exchange.SignalLong, Price: 100, Symbol: "ETH"},
+	}
+	config := testConfig
+	config.TradeDirection = "long"
+
+	// The hedge at bar 1 is skipped as a whole, as live
+	result := NewBacktester().RunBasket(basket, signals, nil, config, "test", "1")
+	if len(result.Positions) != 1 || result.Positions[0].Symbol != "ETH" || result.Positions[0].EntryIndex != 2 {
+		t.Errorf("positions %+v, want only the ETH long from bar 2", result.Positions)
+	}
+}
@@ -0,0 +1,314 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+
+	"terminal/internal/data"
+	"terminal/internal/exchange"
+	"terminal/internal/journal"
+	"terminal/internal/strategy"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// Trade match statuses of a divergence report
+const (
+	// TradeMatched trades were made both live and in the backtest
+	TradeMatched = "matched"
+	// TradeMissed trades were made by the backtest only
+	TradeMissed = "missed"
+	// TradeExtra trades were made live only
+	TradeExtra = "extra"
+)
+
+// DivergenceConfig describes a live run to compare with a backtest of the
+// same period
+type DivergenceConfig struct {
+	// ID is the id the strategy ran under, which its journal entries carry
+	ID         string          `json:"id"`
+	StrategyID string          `json:"strategyId"`
+	Symbol     string          `json:"symbol"`
+	Interval   string          `json:"interval"`
+	Params     map[string]any  `json:"params"`
+	Execution  ExecutionConfig `json:"execution"`
+	// From is when the live run started and To when the compared period
+	// ends, in unix milliseconds. From defaults to one bar before the first
+	// journal entry and To to the time of the backtester's clock.
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// FeeRate is the fee charged on the notional of every live fill, e.g.
+	// 0.00045 for 4.5 bps; 0 uses the fees journaled with the fills.
+	// Backtests trade without fees.
+	FeeRate float64 `json:"feeRate"`
+}
+
+// PnLAttribution splits the live PnL into the backtest PnL and what made
+// reality differ from it:
+//
+//	Live = Backtest + Missed + Extra + EntrySlippage + ExitSlippage + Size - Fees
+type PnLAttribution struct {
+	BacktestPnL float64 `json:"backtestPnL"`
+	// Missed is minus the PnL of backtest trades not made live
+	Missed float64 `json:"missed"`
+	// Extra is the PnL before fees of live trades the backtest did not make
+	Extra float64 `json:"extra"`
+	// EntrySlippage and ExitSlippage are what the live fill prices gained
+	// or lost against the backtest prices of matched trades
+	EntrySlippage float64 `json:"entrySlippage"`
+	ExitSlippage  float64 `json:"exitSlippage"`
+	// Size is what trading a different size than the backtest gained or lost
+	Size    float64 `json:"size"`
+	Fees    float64 `json:"fees"`
+	LivePnL float64 `json:"livePnL"`
+}
+
+func (a *PnLAttribution) add(b PnLAttribution) {
+	a.BacktestPnL += b.BacktestPnL
+	a.Missed += b.Missed
+	a.Extra += b.Extra
+	a.EntrySlippage += b.EntrySlippage
+	a.ExitSlippage += b.ExitSlippage
+	a.Size += b.Size
+	a.Fees += b.Fees
+	a.LivePnL += b.LivePnL
+}
+
+// TradeDivergence compares a live trade with the backtest trade it matched
+type TradeDivergence struct {
+	Status   string             `json:"status"`
+	Live     *exchange.Position `json:"live,omitempty"`
+	Backtest *exchange.Position `json:"backtest,omitempty"`
+	// EntrySlippageBps and ExitSlippageBps are how much worse the live fill
+	// was than the backtest price, in basis points; negative is better
+	EntrySlippageBps float64 `json:"entrySlippageBps"`
+	ExitSlippageBps  float64 `json:"exitSlippageBps"`
+	// EntryLatency and ExitLatency are the milliseconds between the close
+	// of the backtest's bar and the live fill. ExitLatency is 0 when the
+	// live trade exited on another bar or is still open.
+	EntryLatency int64 `json:"entryLatencyMs"`
+	ExitLatency  int64 `json:"exitLatencyMs"`
+	// SameExit is set when the live trade exited on the backtest's exit bar
+	// for the same reason
+	SameExit    bool           `json:"sameExit"`
+	Attribution PnLAttribution `json:"attribution"`
+}
+
+// DivergenceReport compares a live run with a backtest of the same period
+type DivergenceReport struct {
+	// Config is the compared run with its period filled in
+	Config   DivergenceConfig  `json:"config"`
+	Backtest *BacktestResult   `json:"backtest"`
+	Trades   []TradeDivergence `json:"trades"`
+
+	Matched int `json:"matched"`
+	Missed  int `json:"missed"`
+	Extra   int `json:"extra"`
+	// Averages over matched trades; exits only count same-bar exits
+	AvgEntrySlippageBps float64        `json:"avgEntrySlippageBps"`
+	AvgExitSlippageBps  float64        `json:"avgExitSlippageBps"`
+	AvgEntryLatency     int64          `json:"avgEntryLatencyMs"`
+	MaxEntryLatency     int64          `json:"maxEntryLatencyMs"`
+	Attribution         PnLAttribution `json:"attribution"`
+}
+
+// Divergence backtests a live run's strategy over the period it ran and
+// matches the backtest trades against the live trades rebuilt from its
+// journal entries. A live trade matches the backtest trade of the same
+// side entered on the bar before its fill; partial exits match in order.
+// Positions still open at the end are marked at the last close.
+func (b *Backtester) Divergence(source CandleSource, entries []journal.Entry, cfg DivergenceConfig) (*DivergenceReport, error) {
+	strat, err := newStrategy(cfg.StrategyID, cfg.Params)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := strat.(strategy.MultiAssetStrategy); ok {
+		return nil, fmt.Errorf("divergence of multi-asset strategy %s is not supported", cfg.StrategyID)
+	}
+
+	var own []journal.Entry
+	filter := journal.Filter{Strategy: cfg.ID, Symbol: cfg.Symbol}
+	for _, e := range entries {
+		if filter.Match(e) {
+			own = append(own, e)
+		}
+	}
+	bar := data.IntervalDuration(cfg.Interval).Milliseconds()
+	if cfg.From == 0 {
+		if len(own) == 0 {
+			return nil, fmt.Errorf("no journal entries for %s on %s", cfg.ID, cfg.Symbol)
+		}
+		cfg.From = own[0].Time - bar
+	}
+	now := b.clock.Now().UnixMilli()
+	if cfg.To == 0 {
+		cfg.To = now
+	}
+	if cfg.To <= cfg.From {
+		return nil, fmt.Errorf("period ends at %d before it starts at %d", cfg.To, cfg.From)
+	}
+
+	// The live run only traded on bars that closed after it started
+	meta := strat.GetMetadata()
+	bars := int((now-cfg.From)/bar) + 2
+	candles, err := source.FetchHistoricalCandles(cfg.Symbol, cfg.Interval, bars+meta.WarmupBars)
+	if err != nil {
+		return nil, err
+	}
+	candles = candles[:sort.Search(len(candles), func(i int) bool { return candles[i].Timestamp > cfg.To })]
+	first := sort.Search(len(candles), func(i int) bool { return candles[i].Timestamp > cfg.From })
+	if first == len(candles) {
+		return nil, fmt.Errorf("no %s %s bars closed between %d and %d", cfg.Symbol, cfg.Interval, cfg.From, cfg.To)
+	}
+	dataCtx, err := LoadDataContext(source, meta, cfg.Symbol, cfg.Interval, candles, b.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	backtest, err := b.RunContext(strat, dataCtx, len(candles)-first, cfg.Execution)
+	if err != nil {
+		return nil, err
+	}
+
+	var live []exchange.Position
+	for _, trade := range journal.Trades(own) {
+		if trade.EntryTime > cfg.From && trade.EntryTime <= cfg.To {
+			live = append(live, trade)
+		}
+	}
+	report := &DivergenceReport{Config: cfg, Backtest: backtest, Trades: []TradeDivergence{}}
+	report.compare(backtest.Positions, live, candles[len(candles)-1], bar, cfg.FeeRate)
+	return report, nil
+}
+
+// compare matches the trades and fills in the report's statistics
+func (r *DivergenceReport) compare(backtest, live []exchange.Position, last hyperliquid.Candle, bar int64, feeRate float64) {
+	matched := make([]bool, len(live))
+	for i := range backtest {
+		bt := &backtest[i]
+		match := -1
+		for j := range live {
+			if !matched[j] && live[j].Side == bt.Side &&
+				live[j].EntryTime >= bt.EntryTime && live[j].EntryTime < bt.EntryTime+bar {
+				match = j
+				break
+			}
+		}
+		if match < 0 {
+			r.Trades = append(r.Trades, TradeDivergence{
+				Status:      TradeMissed,
+				Backtest:    bt,
+				Attribution: PnLAttribution{BacktestPnL: bt.PnL, Missed: -bt.PnL},
+			})
+			continue
+		}
+		matched[match] = true
+		r.Trades = append(r.Trades, matchTrade(bt, markOpen(live[match], last), bar, feeRate))
+	}
+	for j := range live {
+		if matched[j] {
+			continue
+		}
+		trade := markOpen(live[j], last)
+		fees := liveFees(trade, feeRate)
+		r.Trades = append(r.Trades, TradeDivergence{
+			Status:      TradeExtra,
+			Live:        &trade,
+			Attribution: PnLAttribution{Extra: trade.PnL, Fees: fees, LivePnL: trade.PnL - fees},
+		})
+	}
+	sort.SliceStable(r.Trades, func(i, j int) bool { return r.Trades[i].entryTime() < r.Trades[j].entryTime() })
+
+	var exits int
+	for _, t := range r.Trades {
+		r.Attribution.add(t.Attribution)
+		switch t.Status {
+		case TradeMissed:
+			r.Missed++
+		case TradeExtra:
+			r.Extra++
+		default:
+			r.Matched++
+			r.AvgEntrySlippageBps += t.EntrySlippageBps
+			r.AvgEntryLatency += t.EntryLatency
+			r.MaxEntryLatency = max(r.MaxEntryLatency, t.EntryLatency)
+			if t.SameExit {
+				exits++
+				r.AvgExitSlippageBps += t.ExitSlippageBps
+			}
+		}
+	}
+	if r.Matched > 0 {
+		r.AvgEntrySlippageBps /= float64(r.Matched)
+		r.AvgEntryLatency /= int64(r.Matched)
+	}
+	if exits > 0 {
+		r.AvgExitSlippageBps /= float64(exits)
+	}
+}
+
+// matchTrade compares a live trade with the backtest trade it matched
+func matchTrade(bt *exchange.Position, trade exchange.Position, bar int64, feeRate float64) TradeDivergence {
+	dir := 1.0
+	if bt.Side == "short" {
+		dir = -1
+	}
+	fees := liveFees(trade, feeRate)
+	t := TradeDivergence{
+		Status:       TradeMatched,
+		Live:         &trade,
+		Backtest:     bt,
+		EntryLatency: trade.EntryTime - bt.EntryTime,
+		SameExit: trade.ExitTime >= bt.ExitTime && trade.ExitTime < bt.ExitTime+bar &&
+			trade.ExitReason == bt.ExitReason,
+		Attribution: PnLAttribution{
+			BacktestPnL:   bt.PnL,
+			EntrySlippage: dir * (bt.EntryPrice - trade.EntryPrice) * trade.Size,
+			ExitSlippage:  dir * (trade.ExitPrice - bt.ExitPrice) * trade.Size,
+			Fees:          fees,
+			LivePnL:       trade.PnL - fees,
+		},
+	}
+	if bt.Size > 0 {
+		t.Attribution.Size = bt.PnL * (trade.Size/bt.Size - 1)
+	}
+	if bt.EntryPrice > 0 {
+		t.EntrySlippageBps = dir * (trade.EntryPrice - bt.EntryPrice) / bt.EntryPrice * 1e4
+	}
+	if bt.ExitPrice > 0 {
+		t.ExitSlippageBps = dir * (bt.ExitPrice - trade.ExitPrice) / bt.ExitPrice * 1e4
+	}
+	if t.SameExit {
+		t.ExitLatency = trade.ExitTime - bt.ExitTime
+	}
+	return t
+}
+
+// liveFees returns the fees of a live trade at feeRate, or as journaled when
+// feeRate is 0
+func liveFees(trade exchange.Position, feeRate float64) float64 {
+	if feeRate == 0 {
+		return trade.Fees
+	}
+	return feeRate * trade.Size * (trade.EntryPrice + trade.ExitPrice)
+}
+
+// markOpen values a live position still open at the close of the last bar
+func markOpen(trade exchange.Position, last hyperliquid.Candle) exchange.Position {
+	if !trade.IsOpen {
+		return trade
+	}
+	trade.ExitPrice = parseFloat(last.Close)
+	trade.PnL = trade.Size * (trade.ExitPrice - trade.EntryPrice)
+	if trade.Side == "short" {
+		trade.PnL = -trade.PnL
+	}
+	return trade
+}
+
+// entryTime orders the trades of a report by entry
+func (t TradeDivergence) entryTime() int64 {
+	if t.Live != nil {
+		return t.Live.EntryTime
+	}
+	return t.Backtest.EntryTime
+}
@@ -0,0 +1,190 @@
+package engine
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"terminal/internal/clock"
+	"terminal/internal/exchange"
+	"terminal/internal/journal"
+	"terminal/internal/strategy"
+	"terminal/internal/strategy/strategytest"
+)
+
+// journalTrade returns the journal entries of a live position that filled
+// delay ms after the bar closes of its backtest trades, with the entry price
+// moved by slip. The trades share one entry; all but the last are partial
+// exits.
+func journalTrade(id string, trades []exchange.Position, delay int64, slip float64) []journal.Entry {
+	first := trades[0]
+	entry := first.EntryPrice * (1 + slip)
+	var size float64
+	for _, trade := range trades {
+		size += trade.Size
+	}
+	entries := []journal.Entry{
+		{Time: first.EntryTime + delay, Strategy: id, Kind: journal.KindOrder, Symbol: "BTC",
+			Side: first.Side, Size: size, Price: first.EntryPrice},
+		{Time: first.EntryTime + delay, Strategy: id, Kind: journal.KindFill, Symbol: "BTC",
+			Side: first.Side, Size: size, Price: entry},
+		{Time: first.EntryTime + delay, Strategy: id, Kind: journal.KindOpen, Symbol: "BTC",
+			Side: first.Side, Size: size, Price: entry, EntryPrice: entry},
+	}
+	for i, trade := range trades {
+		size -= trade.Size
+		entries = append(entries,
+			journal.Entry{Time: trade.ExitTime + delay, Strategy: id, Kind: journal.KindOrder, Symbol: "BTC",
+				Side: trade.Side, Size: trade.Size, Price: trade.ExitPrice, Reduce: true, Reason: trade.ExitReason},
+			journal.Entry{Time: trade.ExitTime + delay, Strategy: id, Kind: journal.KindFill, Symbol: "BTC",
+				Side: trade.Side, Size: trade.Size, Price: trade.ExitPrice, Reduce: true})
+		kind := journal.KindUpdate
+		if i == len(trades)-1 {
+			kind = journal.KindExit
+		}
+		entries = append(entries, journal.Entry{Time: trade.ExitTime + delay, Strategy: id, Kind: kind, Symbol: "BTC",
+			Side: trade.Side, Size: size, Price: trade.ExitPrice, EntryPrice: entry})
+	}
+	return entries
+}
+
+// positions groups the trades of a backtest by the position they closed
+func positions(trades []exchange.Position) [][]exchange.Position {
+	var groups [][]exchange.Position
+	for i, trade := range trades {
+		if i > 0 && trades[i-1].ExitReason == exchange.SignalPartialExit.String() && trade.EntryTime == trades[i-1].EntryTime {
+			groups[len(groups)-1] = append(groups[len(groups)-1], trade)
+			continue
+		}
+		groups = append(groups, []exchange.Position{trade})
+	}
+	return groups
+}
+
+func TestDivergenceAttributesTrades(t *testing.T) {
+	strategy.Register("test-turns", func() strategy.Strategy { return turns{} })
+	t.Cleanup(func() { strategy.Unregister("test-turns") })
+
+	candles, err := strategytest.LoadCandles("../strategy/strategytest/testdata/candles/BTC_1h.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := fixtureSource(candles)
+	config := ExecutionConfig{PositionSize: 0.1, TradeDirection: "both"}
+	cfg := DivergenceConfig{
+		ID: "live", StrategyID: "test-turns", Symbol: "BTC", Interval: "1h", Execution: config,
+		From: candles[len(candles)-200].Timestamp, To: candles[len(candles)-1].Timestamp, FeeRate: 0.0005,
+	}
+
+	// The backtest the report runs, without a journal to compare with
+	want, err := NewBacktester().Divergence(source, nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bt := want.Backtest.Positions
+	if want.Missed != len(bt) || len(bt) < 10 {
+		t.Fatalf("got %d missed of %d backtest trades, want all of at least 10", want.Missed, len(bt))
+	}
+
+	// Live trades the first positions, one of them with partial exits, 2s
+	// late and 10 bps worse, skips the second and adds one the backtest
+	// never made
+	groups := positions(bt)
+	partial := slices.IndexFunc(groups, func(g []exchange.Position) bool { return len(g) > 1 })
+	if partial < 2 {
+		t.Fatalf("no position with partial exits after the second of %d", len(groups))
+	}
+	var entries []journal.Entry
+	for i := range partial + 1 {
+		if i != 1 {
+			entries = append(entries, journalTrade("live", groups[i], 2000, 0.001*sideSign(groups[i][0].Side))...)
+		}
+	}
+	extra := groups[partial+1][0]
+	extra.Side = map[string]string{"long": "short", "short": "long"}[extra.Side]
+	entries = append(entries, journalTrade("live", []exchange.Position{extra}, 0, 0)...)
+	entries = append(entries, journalTrade("other", groups[1], 0, 0)...)
+
+	report, err := NewBacktester().Divergence(source, entries, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Extra != 1 || report.Matched+report.Missed != len(bt) || report.Matched == 0 {
+		t.Fatalf("got %d matched, %d missed and %d extra of %d backtest trades",
+			report.Matched, report.Missed, report.Extra, len(bt))
+	}
+	var partials int
+	for _, trade := range report.Trades {
+		if trade.Status != TradeMatched {
+			continue
+		}
+		if trade.Backtest.ExitReason == exchange.SignalPartialExit.String() {
+			partials++
+		}
+		if math.Abs(trade.EntrySlippageBps-10) > 1e-6 || trade.EntryLatency != 2000 || !trade.SameExit {
+			t.Errorf("matched trade slipped %v bps after %d ms, same exit %v; want 10 bps after 2000 ms",
+				trade.EntrySlippageBps, trade.EntryLatency, trade.SameExit)
+		}
+	}
+
+	if partials == 0 {
+		t.Error("no partial exit was matched")
+	}
+
+	a := report.Attribution
+	sum := a.BacktestPnL + a.Missed + a.Extra + a.EntrySlippage + a.ExitSlippage + a.Size - a.Fees
+	if !near(sum, a.LivePnL) || a.Fees <= 0 || a.EntrySlippage >= 0 {
+		t.Errorf("attribution %+v does not add up to the live PnL", a)
+	}
+	if !near(a.BacktestPnL, want.Backtest.TotalPnL) {
+		t.Errorf("attributed backtest PnL %v, backtest made %v", a.BacktestPnL, want.Backtest.TotalPnL)
+	}
+}
+
+func TestDivergenceFollowsClock(t *testing.T) {
+	strategy.Register("test-turns", func() strategy.Strategy { return turns{} })
+	t.Cleanup(func() { strategy.Unregister("test-turns") })
+
+	candles, err := strategytest.LoadCandles("../strategy/strategytest/testdata/candles/BTC_1h.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	end := len(candles) - 50
+	cfg := DivergenceConfig{
+		ID: "live", StrategyID: "test-turns", Symbol: "BTC", Interval: "1h",
+		Execution: ExecutionConfig{PositionSize: 0.1, TradeDirection: "both"},
+		From:      candles[end-150].Timestamp,
+	}
+
+	// Without an end the period runs to the clock, which the feed follows
+	sim := clock.NewSim(time.UnixMilli(candles[end-1].Timestamp))
+	feed := NewReplayFeed(sim)
+	feed.Add("BTC", "1h", candles)
+	b := NewBacktester()
+	b.SetClock(sim)
+	report, err := b.Divergence(feed, nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Config.To != sim.Now().UnixMilli() {
+		t.Errorf("period ends at %d, want the clock's %d", report.Config.To, sim.Now().UnixMilli())
+	}
+
+	cfg.To = candles[end-1].Timestamp
+	want, err := NewBacktester().Divergence(fixtureSource(candles[:end]), nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i := FirstDifference(report.Backtest.Positions, want.Backtest.Positions); i >= 0 || len(want.Backtest.Positions) == 0 {
+		t.Errorf("trade %d differs from the backtest up to the clock", i)
+	}
+}
+
+// sideSign is 1 for longs and -1 for shorts
+func sideSign(side string) float64 {
+	if side == "short" {
+		return -1
+	}
+	return 1
+}
@@ -2,266 +2,739 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"terminal/internal/clock"
 	"terminal/internal/data"
+	"terminal/internal/events"
 	"terminal/internal/exchange"
+	"terminal/internal/logging"
 	"terminal/internal/position"
 	"terminal/internal/strategy"
 )
 
+// DefaultAccount is the account strategies trade when none is given
+const DefaultAccount = "main"
+
 // Engine runs live strategies
 type Engine struct {
 	strategies   map[string]*liveStrategyState
 	strategiesMu sync.RWMutex
-	source       *data.Source
-	positionMgr  *position.Manager
+	source       CandleSource
+	// accounts holds the position manager of each trading account
+	accounts map[string]*position.Manager
+	events   *events.Bus
+	clock    clock.Clock
+	// poll overrides how often run loops check for a new bar, a fifth of
+	// the interval by default
+	poll time.Duration
+	// stepped engines start no run loops; a Replay steps their strategies
+	stepped bool
 }
 
 // liveStrategyState holds the runtime state for a live strategy
 type liveStrategyState struct {
 	*LiveStrategy
-	ctx    context.Context
-	cancel context.CancelFunc
+	// positions is the position manager of the strategy's account
+	positions *position.Manager
+	ctx       context.Context
+	cancel    context.CancelFunc
+
+	// mu guards the LiveStrategy and pending. The run loop is the only
+	// writer of the trading state and holds mu while it changes it; other
+	// goroutines hold mu to read or to change the position when stopping.
+	// Changes release it with unlock.
+	mu      sync.Mutex
+	pending *pendingUpdate
+	// info is a snapshot of the state, taken whenever a change releases mu,
+	// so status queries never wait for a bar or an order in flight
+	info atomic.Pointer[RunningStrategyInfo]
+}
+
+// unlock takes a snapshot of the changed state and releases mu
+func (s *liveStrategyState) unlock() {
+	info := s.snapshot()
+	s.info.Store(&info)
+	s.mu.Unlock()
 }
 
-// NewEngine creates a new strategy engine
-func NewEngine(source *data.Source, positionMgr *position.Manager) *Engine {
-	return &Engine{
-		strategies:  make(map[string]*liveStrategyState),
-		source:      source,
-		positionMgr: positionMgr,
+// update runs fn with the state locked, unless the strategy has been
+// stopped in the meantime
+func (s *liveStrategyState) update(fn func()) {
+	s.mu.Lock()
+	defer s.unlock()
+	if s.ctx.Err() != nil {
+		return
 	}
+	fn()
 }
 
-// StartStrategy starts a strategy by its registry ID
+// NewEngine creates a new strategy engine trading DefaultAccount through
+// positionMgr
+func NewEngine(source CandleSource, positionMgr *position.Manager) *Engine {
+	e := &Engine{
+		strategies: make(map[string]*liveStrategyState),
+		source:     source,
+		accounts:   make(map[string]*position.Manager),
+		clock:      clock.Real,
+	}
+	if positionMgr != nil {
+		e.accounts[DefaultAccount] = positionMgr
+	}
+	return e
+}
+
+// AddAccount registers the position manager of another trading account
+func (e *Engine) AddAccount(id string, positionMgr *position.Manager) {
+	positionMgr.SetEvents(e.events)
+	positionMgr.SetClock(e.clock)
+	e.strategiesMu.Lock()
+	e.accounts[id] = positionMgr
+	e.strategiesMu.Unlock()
+}
+
+// SetEvents publishes candles, signals, state changes and failures of live
+// strategies to bus, and orders and positions through the position managers
+func (e *Engine) SetEvents(bus *events.Bus) {
+	e.events = bus
+	for _, positionMgr := range e.accounts {
+		positionMgr.SetEvents(bus)
+	}
+}
+
+// SetClock makes the engine and its position managers tell time and poll
+// for new bars with clk instead of the wall clock
+func (e *Engine) SetClock(clk clock.Clock) {
+	e.clock = clk
+	for _, positionMgr := range e.accounts {
+		positionMgr.SetClock(clk)
+	}
+}
+
+// publishState reports a change in a live strategy's state
+func (e *Engine) publishState(live *LiveStrategy, state string) {
+	e.events.Publish(live.ID, events.StrategyStateChanged{
+		State:      state,
+		StrategyID: live.Strategy.GetMetadata().ID,
+		Symbol:     live.Symbol,
+		Interval:   live.Interval,
+	})
+}
+
+// StartStrategy starts a strategy by its registry ID trading account; an
+// empty account is DefaultAccount
 func (e *Engine) StartStrategy(
 	id string,
+	account string,
 	strategyID string,
 	symbol string,
 	interval string,
 	params map[string]any,
 	config ExecutionConfig,
 ) error {
+	strat, err := newStrategy(strategyID, params)
+	if err != nil {
+		return err
+	}
+
+	return e.launch(&LiveStrategy{
+		ID:        id,
+		Account:   account,
+		Strategy:  strat,
+		Params:    maps.Clone(params),
+		Config:    config,
+		Symbol:    symbol,
+		Interval:  interval,
+		IsRunning: true,
+	})
+}
+
+// StartBasketStrategy starts a multi-asset strategy on several symbols
+// trading account
+func (e *Engine) StartBasketStrategy(
+	id string,
+	account string,
+	strategyID string,
+	symbols []string,
+	interval string,
+	params map[string]any,
+	config ExecutionConfig,
+) error {
+	if len(symbols) < 2 {
+		return fmt.Errorf("multi-asset strategy needs at least 2 symbols, got %d", len(symbols))
+	}
+
+	strat, err := newStrategy(strategyID, params)
+	if err != nil {
+		return err
+	}
+	if _, ok := strat.(strategy.MultiAssetStrategy); !ok {
+		return fmt.Errorf("strategy %s is not a multi-asset strategy", strategyID)
+	}
+
+	return e.launch(&LiveStrategy{
+		ID:        id,
+		Account:   account,
+		Strategy:  strat,
+		Params:    maps.Clone(params),
+		Config:    config,
+		Symbol:    strings.Join(symbols, "/"),
+		Symbols:   symbols,
+		Interval:  interval,
+		IsRunning: true,
+		Legs:      make(map[string]*exchange.Position),
+	})
+}
+
+// newStrategy creates, validates and initializes a strategy from the registry
+func newStrategy(strategyID string, params map[string]any) (strategy.Strategy, error) {
 	// Get strategy from registry
 	strat, err := strategy.Get(strategyID)
 	if err != nil {
-		return fmt.Errorf("unknown strategy %s: %w", strategyID, err)
+		return nil, fmt.Errorf("unknown strategy %s: %w", strategyID, err)
 	}
 
 	// Validate and initialize
 	if err := strat.ValidateParams(params); err != nil {
-		return fmt.Errorf("invalid params: %w", err)
+		return nil, fmt.Errorf("invalid params: %w", err)
 	}
 	if err := strat.Initialize(params); err != nil {
-		return fmt.Errorf("init failed: %w", err)
+		return nil, fmt.Errorf("init failed: %w", err)
+	}
+	return strat, nil
+}
+
+// launch registers a live strategy and starts its run loop
+func (e *Engine) launch(live *LiveStrategy) error {
+	if live.Account == "" {
+		live.Account = DefaultAccount
+	}
+	if err := live.Config.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Fetch history up front so missing data is reported to the caller
+	if err := e.prime(live); err != nil {
+		return err
 	}
 
 	e.strategiesMu.Lock()
 	defer e.strategiesMu.Unlock()
 
-	if _, exists := e.strategies[id]; exists {
-		return fmt.Errorf("strategy %s already running", id)
+	if _, exists := e.strategies[live.ID]; exists {
+		return fmt.Errorf("strategy %s already running", live.ID)
+	}
+	positions, ok := e.accounts[live.Account]
+	if !ok && len(e.accounts) > 0 {
+		return fmt.Errorf("unknown account %s", live.Account)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	live := &LiveStrategy{
-		ID:        id,
-		Strategy:  strat,
-		Config:    config,
-		Symbol:    symbol,
-		Interval:  interval,
-		IsRunning: true,
-	}
-
 	state := &liveStrategyState{
 		LiveStrategy: live,
+		positions:    positions,
 		ctx:          ctx,
 		cancel:       cancel,
 	}
+	info := state.snapshot()
+	state.info.Store(&info)
+
+	e.strategies[live.ID] = state
+	e.publishState(live, events.StateStarted)
+	if !e.stepped {
+		go e.run(state)
+	}
+
+	return nil
+}
 
-	e.strategies[id] = state
-	go e.run(state)
+// prime checks that enough history is available for every symbol and
+// records the latest candle so the run loop only reacts to new ones
+func (e *Engine) prime(live *LiveStrategy) error {
+	meta := live.Strategy.GetMetadata()
+	symbols := []string{live.Symbol}
+	if live.IsBasket() {
+		symbols = live.Symbols
+	}
 
+	for i, symbol := range symbols {
+		candles, err := e.source.FetchHistoricalCandles(symbol, live.Interval, historyBars(meta))
+		if err != nil {
+			return fmt.Errorf("failed to fetch initial candles for %s: %w", symbol, err)
+		}
+		if err := checkHistory(meta, len(candles)); err != nil {
+			return fmt.Errorf("%s %s: %w", symbol, live.Interval, err)
+		}
+		if i == 0 {
+			live.LastCandleTime = candles[len(candles)-1].Timestamp
+		}
+	}
 	return nil
 }
 
-// StopStrategy stops a running strategy
-func (e *Engine) StopStrategy(id string) error {
+// StopStrategy stops a running strategy, closing its position and legs
+// unless keepPosition is set
+func (e *Engine) StopStrategy(id string, keepPosition bool) error {
+	_, err := e.stop(id, keepPosition, "Strategy Stopped")
+	return err
+}
+
+// stop stops a running strategy and reports what closing it for reason
+// realized
+func (e *Engine) stop(id string, keepPosition bool, reason string) (StopSummary, error) {
 	e.strategiesMu.Lock()
 	state, exists := e.strategies[id]
 	if !exists {
 		e.strategiesMu.Unlock()
-		return fmt.Errorf("strategy %s not found", id)
+		return StopSummary{ID: id}, fmt.Errorf("strategy %s not found", id)
 	}
-
-	state.IsRunning = false
-	state.cancel()
 	delete(e.strategies, id)
 	e.strategiesMu.Unlock()
 
-	// Close position outside lock
-	if state.Position != nil && state.Position.IsOpen && e.positionMgr != nil {
-		currentPrice := state.Position.EntryPrice
-		e.positionMgr.ClosePosition(state.LiveStrategy, currentPrice, "Strategy Stopped")
+	// Once cancelled the run loop makes no more changes, so the position
+	// can be closed here while holding the state lock
+	state.cancel()
+	state.mu.Lock()
+	defer state.unlock()
+	state.IsRunning = false
+
+	summary := StopSummary{ID: id, Account: state.Account, Symbol: state.Symbol}
+	if keepPosition {
+		slog.Info("strategy stopped, position left open", logging.KeyStrategy, id)
+		return summary, nil
+	}
+	if state.positions == nil {
+		return summary, nil
 	}
 
-	return nil
+	if state.Position != nil && state.Position.IsOpen {
+		state.positions.ClosePosition(state.LiveStrategy, e.exitPrice(state, state.Symbol), reason)
+		summary.add(state.Position)
+	}
+	var err error
+	if state.IsBasket() {
+		prices := make(map[string]float64, len(state.Legs))
+		for symbol, leg := range state.Legs {
+			if leg.IsOpen {
+				prices[symbol] = e.exitPrice(state, symbol)
+			}
+		}
+		if err = state.positions.CloseLegs(state.LiveStrategy, state.Symbols, prices, reason); err != nil {
+			err = fmt.Errorf("failed to close legs: %w", err)
+		}
+		for _, symbol := range state.Symbols {
+			if _, closing := prices[symbol]; closing {
+				summary.add(state.Legs[symbol])
+			}
+		}
+	}
+	if err == nil && state.Position != nil && state.Position.IsOpen {
+		err = fmt.Errorf("failed to close %s position", state.Symbol)
+	}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	return summary, err
+}
+
+// exitPrice returns the price to close a position in symbol at when
+// stopping a strategy: the exchange's mid price, else the latest close. The
+// position records the actual fill instead when the exchange reports it.
+func (e *Engine) exitPrice(state *liveStrategyState, symbol string) float64 {
+	price, err := state.positions.Quote(symbol)
+	if err == nil && price > 0 {
+		return price
+	}
+	candles, fetchErr := e.source.FetchHistoricalCandles(symbol, state.Interval, 1)
+	if fetchErr == nil && len(candles) > 0 {
+		return parseFloat(candles[len(candles)-1].Close)
+	}
+	slog.Warn("no price to close at", logging.KeyStrategy, state.ID, logging.KeySymbol, symbol,
+		"quoteError", err, "candleError", fetchErr)
+	if leg := state.GetLeg(symbol); leg != nil {
+		return leg.EntryPrice
+	}
+	return state.Position.EntryPrice
 }
 
-// GetRunningStrategies returns info about all running strategies
+// GetRunningStrategies returns info about all running strategies, as of
+// the last change each made
 func (e *Engine) GetRunningStrategies() []RunningStrategyInfo {
 	e.strategiesMu.RLock()
-	defer e.strategiesMu.RUnlock()
-
-	result := make([]RunningStrategyInfo, 0, len(e.strategies))
-	for _, state := range e.strategies {
-		meta := state.Strategy.GetMetadata()
-		info := RunningStrategyInfo{
-			ID:           state.ID,
-			StrategyID:   meta.ID,
-			StrategyName: meta.Name,
-			Symbol:       state.Symbol,
-			Interval:     state.Interval,
-			IsRunning:    state.IsRunning,
-			Config:       state.Config,
-		}
-
-		if state.Position != nil {
-			info.HasPosition = true
-			info.PositionSide = state.Position.Side
-			info.PositionSize = state.Position.Size
-			info.EntryPrice = state.Position.EntryPrice
-		}
+	states := slices.Collect(maps.Values(e.strategies))
+	e.strategiesMu.RUnlock()
 
+	result := make([]RunningStrategyInfo, 0, len(states))
+	for _, state := range states {
+		info := *state.info.Load()
+		info.Params = maps.Clone(info.Params)
+		info.Legs = slices.Clone(info.Legs)
 		result = append(result, info)
 	}
 	return result
 }
 
+// snapshot returns the info of the strategy; mu must be held
+func (s *liveStrategyState) snapshot() RunningStrategyInfo {
+	meta := s.Strategy.GetMetadata()
+	info := RunningStrategyInfo{
+		ID:            s.ID,
+		Account:       s.Account,
+		StrategyID:    meta.ID,
+		StrategyName:  meta.Name,
+		Symbol:        s.Symbol,
+		Interval:      s.Interval,
+		IsRunning:     s.IsRunning,
+		Paused:        s.Paused,
+		Params:        maps.Clone(s.Params),
+		Config:        s.Config,
+		PendingUpdate: s.pending != nil,
+		Error:         s.Error,
+	}
+
+	if s.Position != nil {
+		info.HasPosition = true
+		info.PositionSide = s.Position.Side
+		info.PositionSize = s.Position.Size
+		info.EntryPrice = s.Position.EntryPrice
+	}
+
+	if s.IsBasket() {
+		info.Symbols = s.Symbols
+		for _, symbol := range s.Symbols {
+			if leg := s.Legs[symbol]; leg != nil && leg.IsOpen {
+				info.Legs = append(info.Legs, *leg)
+			}
+		}
+		info.HasPosition = len(info.Legs) > 0
+	}
+	info.Ledger = s.ledger()
+	return info
+}
+
 // StopAllStrategies stops all running strategies
 func (e *Engine) StopAllStrategies() {
+	e.FlattenAll()
+}
+
+// FlattenAll stops all running strategies, closing their positions and
+// legs, and reports what each realized
+func (e *Engine) FlattenAll() []StopSummary {
 	e.strategiesMu.RLock()
 	ids := make([]string, 0, len(e.strategies))
 	for id := range e.strategies {
 		ids = append(ids, id)
 	}
 	e.strategiesMu.RUnlock()
+	slices.Sort(ids)
 
+	summaries := make([]StopSummary, 0, len(ids))
 	for _, id := range ids {
-		e.StopStrategy(id)
+		summary, err := e.stop(id, false, "Strategy Stopped")
+		if err != nil {
+			slog.Error("failed to flatten strategy", logging.KeyStrategy, id, "error", err)
+		}
+		summaries = append(summaries, summary)
 	}
+	return summaries
 }
 
-// run executes a live strategy
+// run executes a live strategy until it is stopped or panics
 func (e *Engine) run(state *liveStrategyState) {
 	defer state.cancel()
 
-	interval := data.IntervalDuration(state.Interval)
-	ticker := time.NewTicker(interval / 5)
-	defer ticker.Stop()
-
-	// Initial candle fetch
-	candles, err := e.source.FetchHistoricalCandles(state.Symbol, state.Interval, 200)
-	if err != nil {
-		fmt.Printf("[%s] Failed to fetch initial candles: %v\n", state.ID, err)
-		return
-	}
-
-	if len(candles) > 0 {
-		state.LastCandleTime = candles[len(candles)-1].Timestamp
+	poll := e.poll
+	if poll == 0 {
+		poll = data.IntervalDuration(state.Interval) / 5
 	}
-
-	meta := state.Strategy.GetMetadata()
-	fmt.Printf("[%s] Started %s on %s %s\n", state.ID, meta.Name, state.Symbol, state.Interval)
+	ticker := e.clock.NewTicker(poll)
+	defer ticker.Stop()
 
 	for {
 		select {
 		case <-state.ctx.Done():
-			fmt.Printf("[%s] Strategy stopped\n", state.ID)
+			e.publishState(state.LiveStrategy, events.StateStopped)
 			return
-		case <-ticker.C:
-			if err := e.processCandle(state); err != nil {
-				continue
+		case <-ticker.C():
+			err := e.step(state)
+			var panicked *PanicError
+			if errors.As(err, &panicked) {
+				e.fail(state, panicked)
+				return
+			}
+			if err != nil {
+				e.events.Publish(state.ID, events.StrategyError{Message: err.Error()})
 			}
 		}
 	}
 }
 
+// step processes one poll of a live strategy, turning a panic in the
+// strategy or the engine into a *PanicError
+func (e *Engine) step(state *liveStrategyState) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	if state.IsBasket() {
+		return e.processBasket(state)
+	}
+	return e.processCandle(state)
+}
+
+// fail moves a strategy that panicked into the error state. It stays listed
+// with its position untouched until it is stopped.
+func (e *Engine) fail(state *liveStrategyState, panicked *PanicError) {
+	slog.Error("strategy panicked", logging.KeyStrategy, state.ID, logging.KeySymbol, state.Symbol,
+		"panic", fmt.Sprint(panicked.Value), "stack", string(panicked.Stack))
+	state.mu.Lock()
+	state.IsRunning = false
+	state.Error = panicked.Error()
+	state.unlock()
+	e.events.Publish(state.ID, events.StrategyError{Message: panicked.Error()})
+	e.publishState(state.LiveStrategy, events.StateFailed)
+}
+
 // processCandle processes a new candle
 func (e *Engine) processCandle(state *liveStrategyState) error {
-	candles, err := e.source.FetchHistoricalCandles(state.Symbol, state.Interval, 250)
+	meta := state.Strategy.GetMetadata()
+	candles, err := e.source.FetchHistoricalCandles(state.Symbol, state.Interval, historyBars(meta))
 	if err != nil {
 		return err
 	}
 
-	if len(candles) == 0 {
-		return fmt.Errorf("no candles")
+	if err := checkHistory(meta, len(candles)); err != nil {
+		return err
 	}
 
 	latest := candles[len(candles)-1]
+	state.update(func() {
+		state.mark(state.Symbol, parseFloat(latest.Close))
+		if state.positions != nil {
+			state.positions.AccrueFunding(state.Position, state.Symbol, parseFloat(latest.Close))
+		}
+	})
 	if latest.Timestamp <= state.LastCandleTime {
 		// Check TP/SL even without new candle
-		if e.positionMgr != nil {
-			e.positionMgr.CheckTPSL(state.LiveStrategy, parseFloat(latest.Close))
+		if state.positions != nil {
+			state.update(func() {
+				state.positions.CheckTPSL(state.LiveStrategy, parseFloat(latest.Close))
+			})
 		}
 		return nil
 	}
 
-	fmt.Printf("[%s] New candle: O=%s H=%s L=%s C=%s @ %s\n",
-		state.ID,
-		latest.Open,
-		latest.High,
-		latest.Low,
-		latest.Close,
-		time.Unix(latest.Timestamp/1000, 0).Format("15:04:05"),
-	)
+	state.update(func() { state.LastCandleTime = latest.Timestamp })
+	e.events.Publish(state.ID, events.CandleClosed{Symbol: state.Symbol, Interval: state.Interval, Candle: latest})
+	if state.positions != nil {
+		state.positions.Observe(state.Symbol, parseFloat(latest.Close))
+		// Stops and targets of a position entered before this bar are
+		// checked against its range before its signals, as in a backtest
+		state.update(func() {
+			if state.Position != nil && state.Position.EntryTime < latest.Time {
+				state.positions.CheckExits(state.LiveStrategy,
+					parseFloat(latest.Open), parseFloat(latest.High), parseFloat(latest.Low))
+			}
+		})
+	}
+	if e.applyUpdate(state, latest.Timestamp) {
+		// The new params may need more history
+		meta = state.Strategy.GetMetadata()
+		if candles, err = e.source.FetchHistoricalCandles(state.Symbol, state.Interval, historyBars(meta)); err != nil {
+			return err
+		}
+		if err := checkHistory(meta, len(candles)); err != nil {
+			return err
+		}
+	}
 
-	state.LastCandleTime = latest.Timestamp
+	dataCtx, err := LoadDataContext(e.source, meta, state.Symbol, state.Interval, candles, e.clock.Now())
+	if err != nil {
+		return fmt.Errorf("failed to load feeds: %w", err)
+	}
 
 	// Generate signals
-	signals := state.Strategy.GenerateSignals(candles)
+	signals := strategy.GenerateSignals(state.Strategy, dataCtx)
 
 	// Cache visualization
-	state.LastVisualization = state.Strategy.GetVisualization(candles)
+	visualization := strategy.GetVisualization(state.Strategy, dataCtx)
+	state.update(func() { state.LastVisualization = visualization })
 
 	if len(signals) == 0 {
 		e.logTrendDirection(state)
 		return nil
 	}
 
-	// Check if latest signal is on current candle
-	lastSignal := signals[len(signals)-1]
+	// Act on the signals on the current candle, in order
 	lastIdx := len(candles) - 1
+	var current []exchange.Signal
+	for _, signal := range signals {
+		if signal.Index == lastIdx {
+			current = append(current, signal)
+		}
+	}
+	if len(current) == 0 {
+		e.logTrendDirection(state)
+		return nil
+	}
 
-	if lastSignal.Index == lastIdx {
-		if lastSignal.Type == exchange.SignalLong {
-			fmt.Printf("[%s] LONG SIGNAL at %.2f\n", state.ID, lastSignal.Price)
-		} else if lastSignal.Type == exchange.SignalShort {
-			fmt.Printf("[%s] SHORT SIGNAL at %.2f\n", state.ID, lastSignal.Price)
+	for _, signal := range current {
+		if signal.Type != exchange.SignalNone {
+			e.events.Publish(state.ID, events.SignalGenerated{Symbol: state.Symbol, Signal: signal})
 		}
+	}
+
+	// Use position manager to handle signals
+	if state.positions != nil {
+		state.update(func() {
+			for _, signal := range current {
+				if state.Paused {
+					slog.Info("strategy paused, ignoring signal", logging.KeyStrategy, state.ID,
+						logging.KeySymbol, state.Symbol, "type", signal.Type.String())
+					continue
+				}
+				state.positions.HandleSignal(state.LiveStrategy, signal, parseFloat(latest.Close))
+			}
+		})
+	}
+
+	return nil
+}
+
+// processBasket processes a new aligned bar for a multi-asset strategy
+func (e *Engine) processBasket(state *liveStrategyState) error {
+	meta := state.Strategy.GetMetadata()
+	basket, err := LoadBasket(e.source, state.Symbols, state.Interval, historyBars(meta))
+	if err != nil {
+		return err
+	}
+	if err := checkHistory(meta, basket.Len()); err != nil {
+		return err
+	}
 
-		// Use position manager to handle signal
-		if e.positionMgr != nil {
-			e.positionMgr.HandleSignal(state.LiveStrategy, lastSignal, parseFloat(latest.Close))
+	lastIdx := basket.Len() - 1
+	latest := basket.Times[lastIdx]
+	state.update(func() {
+		for _, symbol := range state.Symbols {
+			price := parseFloat(basket.Candles[symbol][lastIdx].Close)
+			state.mark(symbol, price)
+			if state.positions != nil {
+				state.positions.AccrueFunding(state.Legs[symbol], symbol, price)
+			}
 		}
-	} else {
-		e.logTrendDirection(state)
+	})
+	if latest <= state.LastCandleTime {
+		// Check TP/SL of every leg even without new bar
+		if state.positions != nil {
+			state.update(func() {
+				for _, symbol := range state.Symbols {
+					price := parseFloat(basket.Candles[symbol][lastIdx].Close)
+					err = errors.Join(err, state.positions.CheckLegTPSL(state.LiveStrategy, symbol, price))
+				}
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("leg exit failed: %w", err)
+		}
+		return nil
+	}
+
+	state.update(func() { state.LastCandleTime = latest })
+	for _, symbol := range state.Symbols {
+		e.events.Publish(state.ID, events.CandleClosed{
+			Symbol:   symbol,
+			Interval: state.Interval,
+			Candle:   basket.Candles[symbol][lastIdx],
+		})
+	}
+	if state.positions != nil {
+		// Stops and targets of legs entered before this bar are checked
+		// against its range before its signals, as in a backtest
+		state.update(func() {
+			for _, symbol := range state.Symbols {
+				candle := basket.Candles[symbol][lastIdx]
+				state.positions.Observe(symbol, parseFloat(candle.Close))
+				if leg := state.Legs[symbol]; leg != nil && leg.EntryTime < candle.Time {
+					err = errors.Join(err, state.positions.CheckLegExits(state.LiveStrategy, symbol,
+						parseFloat(candle.Open), parseFloat(candle.High), parseFloat(candle.Low)))
+				}
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("leg exit failed: %w", err)
+		}
+	}
+	if e.applyUpdate(state, latest) {
+		// The new params may need more history
+		meta = state.Strategy.GetMetadata()
+		if basket, err = LoadBasket(e.source, state.Symbols, state.Interval, historyBars(meta)); err != nil {
+			return err
+		}
+		if err := checkHistory(meta, basket.Len()); err != nil {
+			return err
+		}
+		lastIdx = basket.Len() - 1
+	}
+
+	strat := state.Strategy.(strategy.MultiAssetStrategy)
+	signals := strat.GenerateBasketSignals(basket)
+	visualization := strat.GetBasketVisualization(basket)
+	state.update(func() { state.LastVisualization = visualization })
+
+	// Signals on the current bar form one leg group
+	var group []exchange.Signal
+	for _, signal := range signals {
+		if signal.Index == lastIdx {
+			group = append(group, signal)
+		}
+	}
+	for _, signal := range group {
+		e.events.Publish(state.ID, events.SignalGenerated{Symbol: signal.Symbol, Signal: signal})
+	}
+	if state.positions == nil {
+		return nil
+	}
+
+	prices := make(map[string]float64, len(state.Symbols))
+	for _, symbol := range state.Symbols {
+		prices[symbol] = parseFloat(basket.Candles[symbol][lastIdx].Close)
+		state.positions.Observe(symbol, prices[symbol])
+	}
+	if len(group) == 0 {
+		return nil
 	}
 
+	state.update(func() {
+		if state.Paused {
+			slog.Info("strategy paused, ignoring leg group", logging.KeyStrategy, state.ID, "legs", len(group))
+			return
+		}
+		err = state.positions.ExecuteLegs(state.LiveStrategy, group, prices)
+	})
+	if err != nil {
+		return fmt.Errorf("leg group failed: %w", err)
+	}
 	return nil
 }
 
 func (e *Engine) logTrendDirection(state *liveStrategyState) {
 	if state.LastVisualization != nil && len(state.LastVisualization.Directions) > 0 {
 		lastDir := state.LastVisualization.Directions[len(state.LastVisualization.Directions)-1]
+		trend := "short"
 		if lastDir == -1 {
-			fmt.Printf("[%s] Trend: LONG\n", state.ID)
-		} else {
-			fmt.Printf("[%s] Trend: SHORT\n", state.ID)
+			trend = "long"
 		}
+		slog.Debug("trend", logging.KeyStrategy, state.ID, logging.KeySymbol, state.Symbol, "direction", trend)
 	}
 }
@@ -0,0 +1,444 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"terminal/internal/exchange"
+	"terminal/internal/position"
+	"terminal/internal/strategy"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// tickSource serves a new bar on every fetch, so each poll of a run loop
+// sees a fresh candle
+type tickSource struct {
+	mu   sync.Mutex
+	tick int64
+}
+
+func (s *tickSource) FetchHistoricalCandles(symbol string, interval string, limit int) ([]hyperliquid.Candle, error) {
+	s.mu.Lock()
+	s.tick++
+	tick := s.tick
+	s.mu.Unlock()
+
+	candles := make([]hyperliquid.Candle, limit)
+	for i := range candles {
+		ts := (tick*int64(limit) + int64(i)) * 60_000
+		price := strconv.FormatFloat(100+float64((tick+int64(i))%7), 'f', 2, 64)
+		candles[i] = hyperliquid.Candle{
+			Symbol: symbol, Interval: interval, Time: ts, Timestamp: ts + 59_999,
+			Open: price, High: price, Low: price, Close: price, Volume: "1",
+		}
+	}
+	return candles, nil
+}
+
+// flip signals on the latest bar, alternating long and short
+type flip struct{ long bool }
+
+func (s *flip) GetMetadata() strategy.Metadata {
+	return strategy.Metadata{ID: "test-flip", Name: "Flip", Version: "1.0", Lookback: 10}
+}
+
+func (s *flip) ValidateParams(map[string]any) error { return nil }
+
+func (s *flip) Initialize(map[string]any) error { return nil }
+
+func (s *flip) GenerateSignals(candles []hyperliquid.Candle) []exchange.Signal {
+	s.long = !s.long
+	signal := exchange.Signal{Index: len(candles) - 1, Type: exchange.SignalShort}
+	if s.long {
+		signal.Type = exchange.SignalLong
+	}
+	return []exchange.Signal{signal}
+}
+
+func (s *flip) GetVisualization([]hyperliquid.Candle) *strategy.Visualization {
+	return strategy.NewVisualization()
+}
+
+// panicky panics on its first live bar
+type panicky struct{ flip }
+
+func (s *panicky) GetMetadata() strategy.Metadata {
+	return strategy.Metadata{ID: "test-panic", Name: "Panic", Version: "1.0", Lookback: 10}
+}
+
+func (s *panicky) GenerateSignals([]hyperliquid.Candle) []exchange.Signal {
+	var signals []exchange.Signal
+	return signals[:1]
+}
+
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	strategy.Register("test-flip", func() strategy.Strategy { return &flip{} })
+	strategy.Register("test-panic", func() strategy.Strategy { return &panicky{} })
+	t.Cleanup(func() {
+		strategy.Unregister("test-flip")
+		strategy.Unregister("test-panic")
+	})
+
+	e := NewEngine(&tickSource{}, position.NewManager(exchange.NewMockAdapter(10_000)))
+	e.poll = time.Millisecond
+	t.Cleanup(e.StopAllStrategies)
+	return e
+}
+
+var testConfig = ExecutionConfig{PositionSize: 1, TradeDirection: "both"}
+
+func TestConcurrentStartStopQuery(t *testing.T) {
+	e := newTestEngine(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := range 8 {
+		wg.Go(func() {
+			id := fmt.Sprintf("flip-%d", i)
+			if err := e.StartStrategy(id, "", "test-flip", fmt.Sprintf("SYM%d", i), "1m", nil, testConfig); err != nil {
+				errs <- err
+				return
+			}
+			for j := range 20 {
+				switch j % 4 {
+				case 0:
+					e.PauseStrategy(id)
+				case 1:
+					e.ResumeStrategy(id)
+				case 2:
+					if err := e.UpdateStrategy(id, map[string]any{"n": j}, nil); err != nil {
+						errs <- err
+					}
+				case 3:
+					e.GetStrategyChanges(id)
+				}
+				time.Sleep(time.Millisecond)
+			}
+			if err := e.StopStrategy(id, i%2 == 0); err != nil {
+				errs <- err
+			}
+		})
+	}
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Go(func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			for _, info := range e.GetRunningStrategies() {
+				if info.Error != "" {
+					errs <- fmt.Errorf("%s failed: %s", info.ID, info.Error)
+				}
+			}
+		}
+	})
+
+	wg.Wait()
+	close(done)
+	readers.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if running := e.GetRunningStrategies(); len(running) != 0 {
+		t.Errorf("%d strategies still running after stop", len(running))
+	}
+}
+
+func TestPanicIsolation(t *testing.T) {
+	e := newTestEngine(t)
+
+	if err := e.StartStrategy("flip", "", "test-flip", "BTC", "1m", nil, testConfig); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.StartStrategy("panic", "", "test-panic", "ETH", "1m", nil, testConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := waitFor(t, func() bool {
+		info, ok := runningInfo(e, "panic")
+		return ok && !info.IsRunning
+	})
+	if !failed {
+		t.Fatal("panicking strategy was not moved to the error state")
+	}
+	info, _ := runningInfo(e, "panic")
+	if info.Error == "" {
+		t.Error("failed strategy reports no error")
+	}
+
+	// The other strategy keeps trading
+	before, _ := runningInfo(e, "flip")
+	flipped := waitFor(t, func() bool {
+		after, _ := runningInfo(e, "flip")
+		return after.IsRunning && after.PositionSide != before.PositionSide
+	})
+	if !flipped {
+		t.Error("healthy strategy stopped trading after another panicked")
+	}
+
+	if err := e.StopStrategy("panic", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := runningInfo(e, "panic"); ok {
+		t.Error("failed strategy still listed after stop")
+	}
+}
+
+// fillAt is a mock exchange whose close orders fill at a fixed price
+type fillAt struct {
+	*exchange.MockAdapter
+	price float64
+}
+
+func (f *fillAt) ClosePositionFill(symbol string, size float64) (float64, error) {
+	if _, err := f.MockAdapter.ClosePositionFill(symbol, size); err != nil {
+		return 0, err
+	}
+	return f.price, nil
+}
+
+func TestFlattenAllRecordsFill(t *testing.T) {
+	e := newTestEngine(t)
+	e.accounts[DefaultAccount] = position.NewManager(&fillAt{MockAdapter: exchange.NewMockAdapter(10_000), price: 200})
+
+	if err := e.StartStrategy("flip", "", "test-flip", "BTC", "1m", nil, testConfig); err != nil {
+		t.Fatal(err)
+	}
+	if !waitFor(t, func() bool { info, _ := runningInfo(e, "flip"); return info.HasPosition }) {
+		t.Fatal("strategy opened no position")
+	}
+
+	summaries := e.FlattenAll()
+	if len(summaries) != 1 || len(summaries[0].Closed) != 1 {
+		t.Fatalf("want one closed position, got %+v", summaries)
+	}
+	pos := summaries[0].Closed[0]
+	if pos.ExitPrice != 200 {
+		t.Errorf("exit price %v, want the fill price 200", pos.ExitPrice)
+	}
+	want := (200 - pos.EntryPrice) * pos.Size
+	if pos.Side == "short" {
+		want = -want
+	}
+	if pos.PnL != want || summaries[0].RealizedPnL != want {
+		t.Errorf("realized PnL %v (position %v), want %v", summaries[0].RealizedPnL, pos.PnL, want)
+	}
+	if len(e.GetRunningStrategies()) != 0 {
+		t.Error("strategies still running after flatten")
+	}
+}
+
+// stalling is a mock exchange whose orders for symbol wait until released
+type stalling struct {
+	*exchange.MockAdapter
+	symbol  string
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *stalling) OpenPosition(symbol string, side string, size float64, leverage int) (*exchange.Position, error) {
+	if symbol == s.symbol {
+		select {
+		case s.entered <- struct{}{}:
+		default:
+		}
+		<-s.release
+	}
+	return s.MockAdapter.OpenPosition(symbol, side, size, leverage)
+}
+
+func TestSlowOrderDoesNotStallQueries(t *testing.T) {
+	e := newTestEngine(t)
+	adapter := &stalling{
+		MockAdapter: exchange.NewMockAdapter(10_000),
+		symbol:      "BTC",
+		entered:     make(chan struct{}, 1),
+		release:     make(chan struct{}),
+	}
+	e.accounts[DefaultAccount] = position.NewManager(adapter)
+	if err := e.StartStrategy("slow", "", "test-flip", "BTC", "1m", nil, testConfig); err != nil {
+		t.Fatal(err)
+	}
+	<-adapter.entered
+	defer close(adapter.release)
+
+	done := make(chan error)
+	go func() {
+		if infos := e.GetRunningStrategies(); len(infos) != 1 {
+			done <- fmt.Errorf("%d strategies listed, want 1", len(infos))
+			return
+		}
+		done <- e.StartStrategy("other", "", "test-flip", "ETH", "1m", nil, testConfig)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("query or launch waited for an order in flight")
+	}
+}
+
+// startStepped starts test-flip on an engine without run loops and returns
+// a function processing its next bar
+func startStepped(t *testing.T, e *Engine, id string) func() {
+	t.Helper()
+	e.stepped = true
+	if err := e.StartStrategy(id, "", "test-flip", "BTC", "1m", nil, testConfig); err != nil {
+		t.Fatal(err)
+	}
+	state, err := e.running(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		t.Helper()
+		if err := e.step(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	e := newTestEngine(t)
+	step := startStepped(t, e, "flip")
+	step()
+	before, _ := runningInfo(e, "flip")
+	if !before.HasPosition {
+		t.Fatal("strategy opened no position")
+	}
+
+	if err := e.PauseStrategy("flip"); err != nil {
+		t.Fatal(err)
+	}
+	step()
+	step()
+	paused, _ := runningInfo(e, "flip")
+	if !paused.Paused || paused.PositionSide != before.PositionSide {
+		t.Errorf("paused strategy %+v acted on signals, was %s", paused, before.PositionSide)
+	}
+
+	if err := e.ResumeStrategy("flip"); err != nil {
+		t.Fatal(err)
+	}
+	step()
+	resumed, _ := runningInfo(e, "flip")
+	if resumed.Paused || resumed.PositionSide == before.PositionSide {
+		t.Errorf("resumed strategy %+v ignored its signal", resumed)
+	}
+	if err := e.PauseStrategy("missing"); err == nil {
+		t.Error("paused a strategy that is not running")
+	}
+}
+
+func TestUpdateAppliedAtNextBar(t *testing.T) {
+	e := newTestEngine(t)
+	step := startStepped(t, e, "flip")
+	step()
+
+	bigger := ExecutionConfig{PositionSize: 2, TradeDirection: "both"}
+	if err := e.UpdateStrategy("flip", map[string]any{"a": 1}, &bigger); err != nil {
+		t.Fatal(err)
+	}
+	// A second update builds on the pending one
+	if err := e.UpdateStrategy("flip", map[string]any{"b": 2}, nil); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := runningInfo(e, "flip")
+	changes, _ := e.GetStrategyChanges("flip")
+	if !info.PendingUpdate || info.Config != testConfig || len(info.Params) != 0 || len(changes) != 0 {
+		t.Fatalf("update applied before the next bar: %+v, changes %+v", info, changes)
+	}
+
+	step()
+	state, _ := e.running("flip")
+	info, _ = runningInfo(e, "flip")
+	if info.PendingUpdate || info.Config != bigger || info.Params["a"] != 1 || info.Params["b"] != 2 {
+		t.Errorf("after the next bar %+v, want params a and b with %+v", info, bigger)
+	}
+	changes, err := e.GetStrategyChanges("flip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("%d changes recorded, want 1 for both updates", len(changes))
+	}
+	change := changes[0]
+	if change.BarTime != state.LastCandleTime || change.PreviousConfig != testConfig || change.Config != bigger ||
+		len(change.PreviousParams) != 0 || len(change.Params) != 2 || change.AppliedAt < change.RequestedAt {
+		t.Errorf("change %+v", change)
+	}
+
+	// The record is a copy
+	changes[0].Config.PositionSize = 5
+	if again, _ := e.GetStrategyChanges("flip"); again[0].Config != bigger {
+		t.Error("changes returned by reference")
+	}
+	if _, err := e.GetStrategyChanges("missing"); err == nil {
+		t.Error("changes of a strategy that is not running")
+	}
+
+	// The new instance repeats the long it is already in, then reverses
+	// at the new size
+	step()
+	if info, _ := runningInfo(e, "flip"); info.PositionSize != 2 {
+		t.Errorf("position size %v after the update, want 2", info.PositionSize)
+	}
+}
+
+func TestInvalidConfigRejected(t *testing.T) {
+	e := newTestEngine(t)
+	step := startStepped(t, e, "flip")
+	step()
+
+	invalid := []ExecutionConfig{
+		{PositionSize: 0, TradeDirection: "both"},
+		{PositionSize: -1, TradeDirection: "long"},
+		{PositionSize: 1, TradeDirection: "sideways"},
+		{PositionSize: 1},
+		{PositionSize: 1, TradeDirection: "short", StopLossPercent: -2},
+	}
+	for _, config := range invalid {
+		if err := e.UpdateStrategy("flip", nil, &config); err == nil {
+			t.Errorf("update to %+v accepted", config)
+		}
+		if err := e.StartStrategy("other", "", "test-flip", "ETH", "1m", nil, config); err == nil {
+			t.Errorf("started with %+v", config)
+		}
+	}
+	step()
+	if info, _ := runningInfo(e, "flip"); info.PendingUpdate || info.Config != testConfig {
+		t.Errorf("rejected update applied: %+v", info)
+	}
+}
+
+func runningInfo(e *Engine, id string) (RunningStrategyInfo, bool) {
+	for _, info := range e.GetRunningStrategies() {
+		if info.ID == id {
+			return info, true
+		}
+	}
+	return RunningStrategyInfo{}, false
+}
+
+// waitFor polls cond for up to a second
+func waitFor(t *testing.T, cond func() bool) bool {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if cond() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"terminal/internal/data"
+	"terminal/internal/strategy"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// defaultLiveBars is the number of bars evaluated on each live update when
+// a strategy does not declare a Lookback
+const defaultLiveBars = 250
+
+// CandleSource fetches the candles strategies are evaluated on. It is
+// implemented by *data.Source.
+type CandleSource interface {
+	FetchHistoricalCandles(symbol string, interval string, limit int) ([]hyperliquid.Candle, error)
+}
+
+// PerpSource is implemented by candle sources that also serve the funding
+// and market data of perpetuals, like *data.Source
+type PerpSource interface {
+	FetchFundingHistory(symbol string, start, end int64) ([]data.FundingRate, error)
+	FetchMarketContext(symbol string) (data.MarketContext, error)
+	FetchMarketHistory(symbol string, since int64) ([]data.MarketContext, error)
+}
+
+// ErrInsufficientHistory is returned when fewer bars are available than a
+// strategy's warm-up requires
+var ErrInsufficientHistory = errors.New("insufficient history")
+
+// historyBars returns how many bars to fetch for each live evaluation
+func historyBars(meta strategy.Metadata) int {
+	bars := meta.Lookback
+	if bars == 0 {
+		bars = defaultLiveBars
+	}
+	return max(bars, meta.WarmupBars+1)
+}
+
+// checkHistory returns ErrInsufficientHistory unless n bars cover the
+// strategy's warm-up with at least one bar to spare
+func checkHistory(meta strategy.Metadata, n int) error {
+	if n <= meta.WarmupBars || n == 0 {
+		return fmt.Errorf("%w: %s needs more than %d bars, got %d",
+			ErrInsufficientHistory, meta.Name, meta.WarmupBars, n)
+	}
+	return nil
+}
+
+// LoadDataContext wraps the primary candles in a strategy.DataContext and
+// fetches every extra feed declared in meta, covering the primary time span.
+// Perp data is fetched as known at now.
+func LoadDataContext(
+	source CandleSource,
+	meta strategy.Metadata,
+	symbol string,
+	interval string,
+	candles []hyperliquid.Candle,
+	now time.Time,
+) (*strategy.DataContext, error) {
+	ctx := strategy.NewDataContext(symbol, interval, candles)
+	if len(candles) == 0 {
+		return ctx, nil
+	}
+
+	for _, feed := range meta.Feeds {
+		feedSymbol := feedSymbol(feed, symbol)
+		feedCandles, err := source.FetchHistoricalCandles(feedSymbol, feed.Interval, feedBars(feed, interval, candles))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch feed %s %s: %w", feedSymbol, feed.Interval, err)
+		}
+		ctx.AddFeed(feedSymbol, feed.Interval, feedCandles)
+	}
+	if meta.PerpData {
+		if err := loadPerpData(source, ctx, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return ctx, nil
+}
+
+// loadPerpData fetches the funding payments and market snapshots covering
+// the candles of ctx up to now, including the snapshot current at now
+func loadPerpData(source CandleSource, ctx *strategy.DataContext, now time.Time) error {
+	perp, ok := source.(PerpSource)
+	if !ok {
+		return fmt.Errorf("perp data for %s is not available from this source", ctx.Symbol)
+	}
+	first := ctx.Candles[0]
+	// One extra payment so the first bar already has a funding rate
+	start := first.Time - time.Hour.Milliseconds()
+	funding, err := perp.FetchFundingHistory(ctx.Symbol, start, now.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("failed to fetch funding of %s: %w", ctx.Symbol, err)
+	}
+	markets, err := perp.FetchMarketHistory(ctx.Symbol, first.Time)
+	if err != nil {
+		return fmt.Errorf("failed to fetch market history of %s: %w", ctx.Symbol, err)
+	}
+	current, err := perp.FetchMarketContext(ctx.Symbol)
+	switch {
+	case errors.Is(err, errNoSnapshot):
+		// A replay has no snapshot before the first one recorded
+	case err != nil:
+		return fmt.Errorf("failed to fetch market context of %s: %w", ctx.Symbol, err)
+	case len(markets) == 0 || markets[len(markets)-1].Time < current.Time:
+		markets = append(markets, current)
+	}
+	ctx.SetPerpData(funding, markets)
+	return nil
+}
+
+// feedSymbol returns the symbol of feed, which defaults to the primary symbol
+func feedSymbol(feed strategy.FeedDef, symbol string) string {
+	if feed.Symbol == "" {
+		return symbol
+	}
+	return feed.Symbol
+}
+
+// feedBars returns how many bars of feed cover the time span of the
+// primary candles, including the feed's warm-up
+func feedBars(feed strategy.FeedDef, interval string, candles []hyperliquid.Candle) int {
+	span := candles[len(candles)-1].Timestamp - candles[0].Timestamp +
+		data.IntervalDuration(interval).Milliseconds()
+	// One extra bar so the first primary bar already has a closed feed bar
+	return int(span/data.IntervalDuration(feed.Interval).Milliseconds()) + feed.WarmupBars + 2
+}
+
+// LoadBasket fetches limit candles for every symbol and aligns them
+func LoadBasket(source CandleSource, symbols []string, interval string, limit int) (*strategy.Basket, error) {
+	series := make(map[string][]hyperliquid.Candle, len(symbols))
+	for _, symbol := range symbols {
+		candles, err := source.FetchHistoricalCandles(symbol, interval, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", symbol, err)
+		}
+		series[symbol] = candles
+	}
+	return strategy.NewBasket(interval, symbols, series), nil
+}
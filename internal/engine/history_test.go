@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"terminal/internal/clock"
+	"terminal/internal/data"
+	"terminal/internal/strategy"
+	"terminal/internal/strategy/strategytest"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// perpSource serves fixture candles with hourly funding from the second bar
+// on and one recorded market snapshot
+type perpSource struct {
+	fixtureSource
+	funding []data.FundingRate
+	market  data.MarketContext
+}
+
+func (s perpSource) FetchFundingHistory(symbol string, start, end int64) ([]data.FundingRate, error) {
+	var rates []data.FundingRate
+	for _, rate := range s.funding {
+		if rate.Time >= start && rate.Time <= end {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
+
+func (s perpSource) FetchMarketContext(symbol string) (data.MarketContext, error) {
+	return data.MarketContext{Time: s.fixtureSource[len(s.fixtureSource)-1].Timestamp, OpenInterest: 2}, nil
+}
+
+func (s perpSource) FetchMarketHistory(symbol string, since int64) ([]data.MarketContext, error) {
+	return []data.MarketContext{s.market}, nil
+}
+
+// newPerpSource serves the last 50 fixture candles with a funding payment
+// at the open of every bar after the first, rated by its index, and a
+// market snapshot at the close of bar 20
+func newPerpSource(t *testing.T) perpSource {
+	t.Helper()
+	candles, err := strategytest.LoadCandles("../strategy/strategytest/testdata/candles/BTC_1h.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	candles = candles[len(candles)-50:]
+	source := perpSource{fixtureSource: candles, market: data.MarketContext{Time: candles[20].Timestamp, OpenInterest: 1}}
+	// Payments fall on the hour, one millisecond after a bar closes
+	for i, candle := range candles[1:] {
+		source.funding = append(source.funding, data.FundingRate{Time: candle.Time, Rate: float64(i + 1)})
+	}
+	return source
+}
+
+func TestLoadDataContextAlignsPerpData(t *testing.T) {
+	source := newPerpSource(t)
+	candles := []hyperliquid.Candle(source.fixtureSource)
+	now := time.UnixMilli(candles[len(candles)-1].Timestamp)
+
+	meta := strategy.Metadata{ID: "test-perp", PerpData: true}
+	ctx, err := LoadDataContext(source, meta, "BTC", "1h", candles, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	perp, err := ctx.Perp()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The payment at the open of bar i is only known when it closes
+	for _, i := range []int{1, 2, 49} {
+		if perp.FundingRate[i] != float64(i) {
+			t.Errorf("funding rate at bar %d is %v, want %d", i, perp.FundingRate[i], i)
+		}
+	}
+	if !math.IsNaN(perp.FundingRate[0]) || !math.IsNaN(perp.OpenInterest[19]) {
+		t.Errorf("bars before the first payment and snapshot have funding %v and open interest %v, want NaN",
+			perp.FundingRate[0], perp.OpenInterest[19])
+	}
+	if perp.OpenInterest[20] != 1 || perp.OpenInterest[48] != 1 || perp.OpenInterest[49] != 2 {
+		t.Errorf("open interest %v, want the recorded snapshot from bar 20 and the current one on the last bar",
+			perp.OpenInterest[18:])
+	}
+
+	// The context as of an earlier bar sees nothing later
+	head, err := ctx.Head(30).Perp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(head.FundingRate) != 30 || head.FundingRate[29] != 29 || head.OpenInterest[29] != 1 {
+		t.Errorf("head has %d bars ending with funding %v and open interest %v",
+			len(head.FundingRate), head.FundingRate[29], head.OpenInterest[29])
+	}
+
+	if _, err := LoadDataContext(fixtureSource(candles), meta, "BTC", "1h", candles, now); err == nil {
+		t.Error("loaded perp data from a source without any")
+	}
+}
+
+func TestReplayFeedCutsPerpDataAtClock(t *testing.T) {
+	source := newPerpSource(t)
+	candles := []hyperliquid.Candle(source.fixtureSource)
+	sim := clock.NewSim(time.UnixMilli(candles[30].Timestamp))
+	feed := NewReplayFeed(sim)
+	feed.Add("BTC", "1h", candles)
+	meta := strategy.Metadata{ID: "test-perp", PerpData: true}
+
+	if _, err := LoadDataContext(feed, meta, "BTC", "1h", candles[:31], sim.Now()); err == nil {
+		t.Error("loaded perp data from a feed without a perp source")
+	}
+	feed.SetPerpSource(source)
+
+	funding, err := feed.FetchFundingHistory("BTC", 0, math.MaxInt64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(funding) != 30 || funding[29].Time > sim.Now().UnixMilli() {
+		t.Errorf("%d payments up to %d served at %d, want the 30 made by then", len(funding), funding[len(funding)-1].Time, sim.Now().UnixMilli())
+	}
+
+	// The live market context is not known in the past; the last recorded
+	// snapshot stands in for it
+	ctx, err := LoadDataContext(feed, meta, "BTC", "1h", candles[:31], sim.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	perp, _ := ctx.Perp()
+	if perp.FundingRate[30] != 30 || perp.OpenInterest[30] != 1 {
+		t.Errorf("bar 30 sees funding %v and open interest %v, want 30 and 1", perp.FundingRate[30], perp.OpenInterest[30])
+	}
+
+	// Before the first snapshot there is none
+	sim.Set(time.UnixMilli(candles[10].Timestamp))
+	ctx, err = LoadDataContext(feed, meta, "BTC", "1h", candles[:11], sim.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	perp, _ = ctx.Perp()
+	if perp.FundingRate[10] != 10 || !math.IsNaN(perp.OpenInterest[10]) {
+		t.Errorf("bar 10 sees funding %v and open interest %v, want 10 and NaN", perp.FundingRate[10], perp.OpenInterest[10])
+	}
+}
@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"maps"
+
+	"terminal/internal/exchange"
+)
+
+// Ledger accounts for the PnL of a live strategy. Its metrics score the
+// closed trades net of their fees and funding, the way a backtest scores
+// its positions.
+type Ledger struct {
+	// RealizedPnL is the PnL of the closed trades and UnrealizedPnL that of
+	// the open position or legs at their latest mark, both before fees and
+	// funding
+	RealizedPnL   float64 `json:"realizedPnL"`
+	UnrealizedPnL float64 `json:"unrealizedPnL"`
+	// Fees paid and Funding received, negative when paid, by closed and
+	// open positions alike
+	Fees    float64 `json:"fees"`
+	Funding float64 `json:"funding"`
+	// Equity is the net PnL: realized and unrealized PnL less fees plus
+	// funding
+	Equity float64 `json:"equity"`
+
+	// The trade statistics are scored on the closed trades net of fees and
+	// funding. Gross scores them before, like a backtest, which charges
+	// neither, so the two can be compared.
+	TradeStats
+	Gross TradeStats `json:"gross"`
+}
+
+// TradeStats scores a list of closed trades
+type TradeStats struct {
+	TotalTrades   int     `json:"totalTrades"`
+	WinningTrades int     `json:"winningTrades"`
+	LosingTrades  int     `json:"losingTrades"`
+	WinRate       float64 `json:"winRate"`
+	AverageWin    float64 `json:"averageWin"`
+	AverageLoss   float64 `json:"averageLoss"`
+	ProfitFactor  float64 `json:"profitFactor"`
+	// MaxDrawdown and MaxDrawdownPercent are as in BacktestResult
+	MaxDrawdown        float64 `json:"maxDrawdown"`
+	MaxDrawdownPercent float64 `json:"maxDrawdownPercent"`
+}
+
+// StrategyHistory is the trade history of a running strategy
+type StrategyHistory struct {
+	ID     string `json:"id"`
+	Ledger Ledger `json:"ledger"`
+	// Trades are the closed trades, oldest first, and Open the open
+	// position or legs
+	Trades []exchange.Position `json:"trades"`
+	Open   []exchange.Position `json:"open"`
+	// Marks are the latest prices the open positions are valued at
+	Marks map[string]float64 `json:"marks"`
+}
+
+// GetStrategyHistory returns the trades and ledger of a running strategy
+func (e *Engine) GetStrategyHistory(id string) (*StrategyHistory, error) {
+	state, err := e.running(id)
+	if err != nil {
+		return nil, err
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	history := &StrategyHistory{
+		ID:     state.ID,
+		Ledger: state.ledger(),
+		Trades: append([]exchange.Position{}, state.Trades...),
+		Open:   []exchange.Position{},
+		Marks:  maps.Clone(state.Marks),
+	}
+	history.Open = append(history.Open, state.open()...)
+	return history, nil
+}
+
+// mark records the latest price of symbol
+func (l *LiveStrategy) mark(symbol string, price float64) {
+	if l.Marks == nil {
+		l.Marks = make(map[string]float64)
+	}
+	l.Marks[symbol] = price
+}
+
+// open returns the open position or legs of l, in symbol order, as copies
+// carrying their symbol
+func (l *LiveStrategy) open() []exchange.Position {
+	var open []exchange.Position
+	add := func(symbol string, pos *exchange.Position) {
+		if pos != nil && pos.IsOpen {
+			p := *pos
+			p.Symbol = symbol
+			open = append(open, p)
+		}
+	}
+	add(l.Symbol, l.Position)
+	for _, symbol := range l.Symbols {
+		add(symbol, l.Legs[symbol])
+	}
+	return open
+}
+
+// ledger accounts for the trades and open positions of l. Open positions
+// without a mark yet are valued at their entry.
+func (l *LiveStrategy) ledger() Ledger {
+	var ledger Ledger
+	net := make([]exchange.Position, len(l.Trades))
+	for i, trade := range l.Trades {
+		ledger.RealizedPnL += trade.PnL
+		ledger.Fees += trade.Fees
+		ledger.Funding += trade.Funding
+		net[i] = trade
+		net[i].PnL = trade.PnL - trade.Fees + trade.Funding
+	}
+	for _, pos := range l.open() {
+		if price, ok := l.Marks[pos.Symbol]; ok {
+			pnl := pos.Size * (price - pos.EntryPrice)
+			if pos.Side == "short" {
+				pnl = -pnl
+			}
+			ledger.UnrealizedPnL += pnl
+		}
+		ledger.Fees += pos.Fees
+		ledger.Funding += pos.Funding
+	}
+	ledger.Equity = ledger.RealizedPnL + ledger.UnrealizedPnL - ledger.Fees + ledger.Funding
+
+	ledger.TradeStats = tradeStats(net)
+	ledger.Gross = tradeStats(l.Trades)
+	return ledger
+}
+
+// tradeStats scores trades with the backtest metrics
+func tradeStats(trades []exchange.Position) TradeStats {
+	metrics := NewBacktester().calculateMetrics(trades)
+	return TradeStats{
+		TotalTrades:        metrics.totalTrades,
+		WinningTrades:      metrics.winningTrades,
+		LosingTrades:       metrics.losingTrades,
+		WinRate:            metrics.winRate,
+		AverageWin:         metrics.averageWin,
+		AverageLoss:        metrics.averageLoss,
+		ProfitFactor:       metrics.profitFactor,
+		MaxDrawdown:        metrics.maxDrawdown,
+		MaxDrawdownPercent: metrics.maxDrawdownPercent,
+	}
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"terminal/internal/clock"
+	"terminal/internal/exchange"
+	"terminal/internal/position"
+	"terminal/internal/strategy"
+	"terminal/internal/strategy/strategytest"
+)
+
+func TestLedgerAccountsFeesAndFunding(t *testing.T) {
+	strategy.Register("test-turns", func() strategy.Strategy { return turns{} })
+	t.Cleanup(func() { strategy.Unregister("test-turns") })
+
+	candles, err := strategytest.LoadCandles("../strategy/strategytest/testdata/candles/BTC_1h.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const feeRate, fundingRate = 0.00045, 0.0001
+	mock := exchange.NewMockAdapter(0)
+	mock.SetFeeRate(feeRate)
+	mock.SetFundingRate("BTC", fundingRate)
+
+	// Step the live engine over the last 100 bars
+	start := len(candles) - 100
+	sim := clock.NewSim(time.UnixMilli(candles[start].Timestamp))
+	feed := NewReplayFeed(sim)
+	feed.Add("BTC", "1h", candles)
+	e := NewEngine(feed, position.NewManager(mock))
+	e.SetClock(sim)
+	e.stepped = true
+	t.Cleanup(e.StopAllStrategies)
+
+	config := ExecutionConfig{PositionSize: 0.1, TradeDirection: "both"}
+	if err := e.StartStrategy("live", "", "test-turns", "BTC", "1h", nil, config); err != nil {
+		t.Fatal(err)
+	}
+	state, err := e.running("live")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.LastCandleTime = candles[start-1].Timestamp
+	for _, candle := range candles[start:] {
+		sim.Set(time.UnixMilli(candle.Timestamp))
+		if err := e.step(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := e.GetStrategyHistory("live")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Trades) < 10 {
+		t.Fatalf("strategy made only %d trades", len(history.Trades))
+	}
+
+	// Every trade pays the fee of its entry share and its exit, and longs
+	// pay the funding shorts receive
+	var net float64
+	for i, trade := range history.Trades {
+		fees := feeRate * trade.Size * (trade.EntryPrice + trade.ExitPrice)
+		if !near(trade.Fees, fees) {
+			t.Errorf("trade %d paid %v fees, want %v", i, trade.Fees, fees)
+		}
+		if trade.Funding*sideSign(trade.Side) >= 0 {
+			t.Errorf("%s trade %d received %v funding", trade.Side, i, trade.Funding)
+		}
+		net += trade.PnL - trade.Fees + trade.Funding
+	}
+
+	l := history.Ledger
+	if l.TotalTrades != len(history.Trades) || l.WinningTrades+l.LosingTrades != l.TotalTrades {
+		t.Errorf("ledger counts %d trades (%d won, %d lost), history has %d",
+			l.TotalTrades, l.WinningTrades, l.LosingTrades, len(history.Trades))
+	}
+	if l.MaxDrawdown > 0 || l.Fees <= 0 {
+		t.Errorf("ledger %+v has a positive drawdown or no fees", l)
+	}
+	// Fees cost more than the funding shorts receive, so trades do
+	// worse net than gross
+	if l.Gross.TotalTrades != l.TotalTrades || l.Gross.WinningTrades < l.WinningTrades || l.Gross.ProfitFactor <= l.ProfitFactor {
+		t.Errorf("gross stats %+v do not beat net stats %+v", l.Gross, l.TradeStats)
+	}
+	var open float64
+	for _, pos := range history.Open {
+		pnl := pos.Size * (history.Marks["BTC"] - pos.EntryPrice) * sideSign(pos.Side)
+		open += pnl - pos.Fees + pos.Funding
+	}
+	if math.Abs(l.Equity-(net+open)) > 1e-6 {
+		t.Errorf("equity %v, want %v from closed trades and %v from the open position", l.Equity, net, open)
+	}
+
+	info, _ := runningInfo(e, "live")
+	if info.Ledger != l {
+		t.Errorf("running info ledger %+v differs from the history's %+v", info.Ledger, l)
+	}
+}
@@ -0,0 +1,241 @@
+package engine
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"terminal/internal/exchange"
+	"terminal/internal/strategy"
+)
+
+// Optimization objectives
+const (
+	ObjectivePnL          = "pnl"
+	ObjectiveSharpe       = "sharpe"
+	ObjectiveProfitFactor = "profitFactor"
+	ObjectiveWinRate      = "winRate"
+	ObjectiveDrawdown     = "drawdown"
+)
+
+// ParamGrid lists the candidate values for each parameter to search
+type ParamGrid map[string][]any
+
+// BacktestSummary holds the headline metrics of a backtest without the
+// positions, signals and visualization
+type BacktestSummary struct {
+	TotalPnL           float64 `json:"totalPnL"`
+	TotalPnLPercent    float64 `json:"totalPnLPercent"`
+	WinRate            float64 `json:"winRate"`
+	TotalTrades        int     `json:"totalTrades"`
+	ProfitFactor       float64 `json:"profitFactor"`
+	MaxDrawdownPercent float64 `json:"maxDrawdownPercent"`
+	SharpeRatio        float64 `json:"sharpeRatio"`
+}
+
+// Summary returns the headline metrics of r
+func (r *BacktestResult) Summary() BacktestSummary {
+	return BacktestSummary{
+		TotalPnL:           r.TotalPnL,
+		TotalPnLPercent:    r.TotalPnLPercent,
+		WinRate:            r.WinRate,
+		TotalTrades:        r.TotalTrades,
+		ProfitFactor:       r.ProfitFactor,
+		MaxDrawdownPercent: r.MaxDrawdownPercent,
+		SharpeRatio:        r.SharpeRatio,
+	}
+}
+
+// OptimizeConfig describes a parameter search for a single-asset strategy
+type OptimizeConfig struct {
+	StrategyID string
+	// BaseParams apply to every run; Grid values override them
+	BaseParams map[string]any
+	Grid       ParamGrid
+	// Objective is one of the Objective* constants; empty means ObjectivePnL
+	Objective string
+	Execution ExecutionConfig
+}
+
+// OptimizationRun is the outcome of one parameter combination
+type OptimizationRun struct {
+	Params  map[string]any  `json:"params"`
+	Summary BacktestSummary `json:"summary"`
+	Score   float64         `json:"score"`
+}
+
+// Optimize backtests every combination in the grid over the last limit bars
+// of dataCtx and returns the runs sorted best first. Combinations the
+// strategy rejects are skipped.
+func (b *Backtester) Optimize(dataCtx *strategy.DataContext, limit int, cfg OptimizeConfig) ([]OptimizationRun, error) {
+	objective := cfg.Objective
+	if objective == "" {
+		objective = ObjectivePnL
+	}
+	if _, err := objectiveScore(objective, BacktestSummary{}); err != nil {
+		return nil, err
+	}
+
+	var runs []OptimizationRun
+	var lastErr error
+	for _, params := range cfg.Grid.combinations(cfg.BaseParams) {
+		strat, err := newStrategy(cfg.StrategyID, params)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		result, err := b.RunContext(strat, dataCtx, limit, cfg.Execution)
+		if err != nil {
+			return nil, err
+		}
+		summary := result.Summary()
+		score, _ := objectiveScore(objective, summary)
+		runs = append(runs, OptimizationRun{Params: params, Summary: summary, Score: score})
+	}
+
+	if len(runs) == 0 {
+		if lastErr != nil {
+			return nil, fmt.Errorf("no valid parameter combination: %w", lastErr)
+		}
+		return nil, fmt.Errorf("no valid parameter combination")
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Score > runs[j].Score })
+	return runs, nil
+}
+
+// combinations returns the cartesian product of the grid over base.
+// Parameter names are iterated in sorted order so results are repeatable.
+func (g ParamGrid) combinations(base map[string]any) []map[string]any {
+	result := []map[string]any{maps.Clone(base)}
+	if result[0] == nil {
+		result[0] = map[string]any{}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(g)) {
+		values := g[name]
+		if len(values) == 0 {
+			continue
+		}
+		next := make([]map[string]any, 0, len(result)*len(values))
+		for _, params := range result {
+			for _, value := range values {
+				combo := maps.Clone(params)
+				combo[name] = value
+				next = append(next, combo)
+			}
+		}
+		result = next
+	}
+	return result
+}
+
+// objectiveScore returns the value to maximize for an objective
+func objectiveScore(objective string, s BacktestSummary) (float64, error) {
+	switch objective {
+	case ObjectivePnL:
+		return s.TotalPnL, nil
+	case ObjectiveSharpe:
+		return s.SharpeRatio, nil
+	case ObjectiveProfitFactor:
+		return s.ProfitFactor, nil
+	case ObjectiveWinRate:
+		return s.WinRate, nil
+	case ObjectiveDrawdown:
+		// Drawdown is reported as a negative percentage; smaller is better
+		return s.MaxDrawdownPercent, nil
+	default:
+		return 0, fmt.Errorf("unknown objective %q", objective)
+	}
+}
+
+// WalkForwardConfig describes a walk-forward analysis. Each fold optimizes
+// on TrainBars bars and evaluates the winning params on the following
+// TestBars bars; folds advance by TestBars.
+type WalkForwardConfig struct {
+	OptimizeConfig
+	TrainBars int
+	TestBars  int
+}
+
+// WalkForwardFold is the outcome of one train/test window
+type WalkForwardFold struct {
+	Index     int             `json:"index"`
+	TrainFrom int64           `json:"trainFrom"`
+	TestFrom  int64           `json:"testFrom"`
+	TestTo    int64           `json:"testTo"`
+	Params    map[string]any  `json:"params"`
+	Train     BacktestSummary `json:"train"`
+	Test      BacktestSummary `json:"test"`
+}
+
+// WalkForwardResult combines the out-of-sample results of every fold
+type WalkForwardResult struct {
+	Folds []WalkForwardFold `json:"folds"`
+	// Positions holds the out-of-sample trades of all folds, indexed
+	// relative to the first test bar
+	Positions []exchange.Position `json:"positions"`
+	// Summary is computed over the out-of-sample trades only
+	Summary BacktestSummary `json:"summary"`
+}
+
+// WalkForward runs a walk-forward analysis over dataCtx. The strategy's
+// warm-up bars precede the first training window.
+func (b *Backtester) WalkForward(dataCtx *strategy.DataContext, cfg WalkForwardConfig) (*WalkForwardResult, error) {
+	if cfg.TrainBars <= 0 || cfg.TestBars <= 0 {
+		return nil, fmt.Errorf("train and test bars must be positive")
+	}
+	strat, err := newStrategy(cfg.StrategyID, cfg.BaseParams)
+	if err != nil {
+		return nil, err
+	}
+	meta := strat.GetMetadata()
+
+	n := len(dataCtx.Candles)
+	first := meta.WarmupBars
+	if first+cfg.TrainBars+cfg.TestBars > n {
+		return nil, fmt.Errorf("%w: walk-forward needs %d bars, got %d",
+			ErrInsufficientHistory, first+cfg.TrainBars+cfg.TestBars, n)
+	}
+
+	result := &WalkForwardResult{}
+	for trainStart := first; trainStart+cfg.TrainBars+cfg.TestBars <= n; trainStart += cfg.TestBars {
+		testStart := trainStart + cfg.TrainBars
+		testEnd := testStart + cfg.TestBars
+
+		runs, err := b.Optimize(dataCtx.Head(testStart), cfg.TrainBars, cfg.OptimizeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("fold %d: %w", len(result.Folds), err)
+		}
+		best := runs[0]
+
+		strat, err := newStrategy(cfg.StrategyID, best.Params)
+		if err != nil {
+			return nil, err
+		}
+		test, err := b.RunContext(strat, dataCtx.Head(testEnd), cfg.TestBars, cfg.Execution)
+		if err != nil {
+			return nil, fmt.Errorf("fold %d: %w", len(result.Folds), err)
+		}
+
+		offset := testStart - (first + cfg.TrainBars)
+		for _, pos := range test.Positions {
+			pos.EntryIndex += offset
+			pos.ExitIndex += offset
+			result.Positions = append(result.Positions, pos)
+		}
+		result.Folds = append(result.Folds, WalkForwardFold{
+			Index:     len(result.Folds),
+			TrainFrom: dataCtx.Candles[trainStart].Time,
+			TestFrom:  dataCtx.Candles[testStart].Time,
+			TestTo:    dataCtx.Candles[testEnd-1].Timestamp,
+			Params:    best.Params,
+			Train:     best.Summary,
+			Test:      test.Summary(),
+		})
+	}
+
+	combined := b.buildResult(result.Positions, nil, nil, meta.Name, meta.Version)
+	result.Summary = combined.Summary()
+	return result, nil
+}
@@ -0,0 +1,254 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"terminal/internal/clock"
+	"terminal/internal/data"
+	"terminal/internal/exchange"
+	"terminal/internal/position"
+	"terminal/internal/strategy"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// ReplayFeed serves preloaded candles the way a live source would have at
+// the time of its clock: only bars that closed by then. Perp data, when a
+// source is set, is likewise cut off at the clock.
+type ReplayFeed struct {
+	clock  clock.Clock
+	series map[string][]hyperliquid.Candle
+	perp   PerpSource
+}
+
+// errNoSnapshot is returned by a ReplayFeed for the market context before
+// the first snapshot recorded
+var errNoSnapshot = errors.New("no market snapshot recorded")
+
+// NewReplayFeed creates an empty feed following clk
+func NewReplayFeed(clk clock.Clock) *ReplayFeed {
+	return &ReplayFeed{clock: clk, series: make(map[string][]hyperliquid.Candle)}
+}
+
+// Add stores the candles of symbol and interval, sorted by time
+func (f *ReplayFeed) Add(symbol string, interval string, candles []hyperliquid.Candle) {
+	f.series[symbol+"/"+interval] = candles
+}
+
+// FetchHistoricalCandles returns the last limit candles that closed by the
+// clock's current time
+func (f *ReplayFeed) FetchHistoricalCandles(symbol string, interval string, limit int) ([]hyperliquid.Candle, error) {
+	candles, ok := f.series[symbol+"/"+interval]
+	if !ok {
+		return nil, fmt.Errorf("no %s %s candles to replay", symbol, interval)
+	}
+	now := f.clock.Now().UnixMilli()
+	end := sort.Search(len(candles), func(i int) bool { return candles[i].Timestamp > now })
+	if end == 0 {
+		return nil, fmt.Errorf("no %s %s candles closed by %s", symbol, interval, f.clock.Now().UTC())
+	}
+	return slices.Clip(candles[max(end-limit, 0):end]), nil
+}
+
+// SetPerpSource serves the funding and market data of perpetuals from perp
+func (f *ReplayFeed) SetPerpSource(perp PerpSource) {
+	f.perp = perp
+}
+
+// FetchFundingHistory returns the funding payments made from start to end
+// that were made by the clock's current time
+func (f *ReplayFeed) FetchFundingHistory(symbol string, start, end int64) ([]data.FundingRate, error) {
+	if f.perp == nil {
+		return nil, fmt.Errorf("no %s perp data to replay", symbol)
+	}
+	return f.perp.FetchFundingHistory(symbol, start, min(end, f.clock.Now().UnixMilli()))
+}
+
+// FetchMarketHistory returns the market snapshots recorded from since to the
+// clock's current time
+func (f *ReplayFeed) FetchMarketHistory(symbol string, since int64) ([]data.MarketContext, error) {
+	if f.perp == nil {
+		return nil, fmt.Errorf("no %s perp data to replay", symbol)
+	}
+	markets, err := f.perp.FetchMarketHistory(symbol, since)
+	if err != nil {
+		return nil, err
+	}
+	now := f.clock.Now().UnixMilli()
+	return markets[:sort.Search(len(markets), func(i int) bool { return markets[i].Time > now })], nil
+}
+
+// FetchMarketContext returns the last market snapshot recorded by the
+// clock's current time, as the live market is not known in the past
+func (f *ReplayFeed) FetchMarketContext(symbol string) (data.MarketContext, error) {
+	markets, err := f.FetchMarketHistory(symbol, 0)
+	if err != nil {
+		return data.MarketContext{}, err
+	}
+	if len(markets) == 0 {
+		return data.MarketContext{}, fmt.Errorf("%w for %s by %s", errNoSnapshot, symbol, f.clock.Now().UTC())
+	}
+	return markets[len(markets)-1], nil
+}
+
+// Replay runs a strategy over historical candles through the live engine.
+// Candles come from a ReplayFeed, time from a simulated clock that moves to
+// the close of each bar in turn, and orders go to a mock exchange. Every bar
+// is processed exactly like a live poll that sees a new bar, so the trades
+// can be compared with a backtest of the same candles.
+type Replay struct {
+	// Speed scales simulated time: 1 replays bars at their real pace, 10 ten
+	// times faster, and 0 as fast as possible
+	Speed float64
+}
+
+// Run replays a registered single-symbol strategy over the last limit bars
+// after its warm-up, like Backtester.RunStrategy. A position still open at
+// the end is closed at the last close. The result lists the trades with
+// their bar indices and the same metrics as a backtest.
+func (r *Replay) Run(
+	ctx context.Context,
+	source CandleSource,
+	strategyID string,
+	symbol string,
+	interval string,
+	limit int,
+	params map[string]any,
+	config ExecutionConfig,
+) (*BacktestResult, error) {
+	strat, err := newStrategy(strategyID, params)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := strat.(strategy.MultiAssetStrategy); ok {
+		return nil, fmt.Errorf("replaying multi-asset strategy %s is not supported", strategyID)
+	}
+
+	meta := strat.GetMetadata()
+	candles, err := source.FetchHistoricalCandles(symbol, interval, limit+meta.WarmupBars)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkHistory(meta, len(candles)); err != nil {
+		return nil, err
+	}
+	trim := warmupTrim(meta, len(candles), limit)
+
+	// The clock starts at the close of the first replayed bar, so the
+	// strategy starts with its warm-up available
+	sim := clock.NewSim(time.UnixMilli(candles[trim].Timestamp))
+	feed := NewReplayFeed(sim)
+	feed.Add(symbol, interval, candles)
+	if perp, ok := source.(PerpSource); ok {
+		feed.SetPerpSource(perp)
+	}
+	for _, def := range meta.Feeds {
+		feedSymbol := feedSymbol(def, symbol)
+		if feedSymbol == symbol && def.Interval == interval {
+			continue
+		}
+		feedCandles, err := source.FetchHistoricalCandles(feedSymbol, def.Interval, feedBars(def, interval, candles))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch feed %s %s: %w", feedSymbol, def.Interval, err)
+		}
+		feed.Add(feedSymbol, def.Interval, feedCandles)
+	}
+
+	e := NewEngine(feed, position.NewManager(exchange.NewMockAdapter(0)))
+	e.SetClock(sim)
+	e.stepped = true
+
+	const id = "replay"
+	if err := e.StartStrategy(id, "", strategyID, symbol, interval, params, config); err != nil {
+		return nil, err
+	}
+	state, err := e.running(id)
+	if err != nil {
+		return nil, err
+	}
+	// Starting recorded the first replayed bar as seen; it must be processed
+	state.LastCandleTime = 0
+	if trim > 0 {
+		state.LastCandleTime = candles[trim-1].Timestamp
+	}
+
+	for i := trim; i < len(candles); i++ {
+		if i > trim {
+			gap := time.Duration(candles[i].Timestamp-candles[i-1].Timestamp) * time.Millisecond
+			if err := r.wait(ctx, gap); err != nil {
+				e.StopStrategy(id, false)
+				return nil, err
+			}
+		}
+		sim.Set(time.UnixMilli(candles[i].Timestamp))
+		if err := e.step(state); err != nil {
+			e.StopStrategy(id, false)
+			return nil, fmt.Errorf("bar %d: %w", i-trim, err)
+		}
+	}
+	if _, err := e.stop(id, false, "End of Period"); err != nil {
+		return nil, err
+	}
+
+	// Trades are stamped with the clock, which stood at the close of the bar
+	index := make(map[int64]int, len(candles)-trim)
+	for i, candle := range candles[trim:] {
+		index[candle.Timestamp] = i
+	}
+	trades := slices.Clone(state.Trades)
+	for i := range trades {
+		trades[i].EntryIndex = index[trades[i].EntryTime]
+		trades[i].ExitIndex = index[trades[i].ExitTime]
+	}
+
+	meta = state.Strategy.GetMetadata()
+	return NewBacktester().buildResult(trades, []exchange.Signal{}, nil, meta.Name, meta.Version), nil
+}
+
+// FirstDifference returns the index of the first trade that differs between
+// two runs over the same candles, or -1 when they match trade for trade.
+// Prices, sizes and PnL only need to agree to within rounding.
+func FirstDifference(a, b []exchange.Position) int {
+	for i := range min(len(a), len(b)) {
+		if !sameTrade(a[i], b[i]) {
+			return i
+		}
+	}
+	if len(a) != len(b) {
+		return min(len(a), len(b))
+	}
+	return -1
+}
+
+func sameTrade(a, b exchange.Position) bool {
+	return a.Side == b.Side && a.EntryIndex == b.EntryIndex && a.ExitIndex == b.ExitIndex &&
+		a.EntryTime == b.EntryTime && a.ExitTime == b.ExitTime && a.ExitReason == b.ExitReason &&
+		near(a.EntryPrice, b.EntryPrice) && near(a.ExitPrice, b.ExitPrice) &&
+		near(a.Size, b.Size) && near(a.PnL, b.PnL)
+}
+
+// near reports whether a and b are equal to within float rounding
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*max(1, math.Abs(a), math.Abs(b))
+}
+
+// wait lets d of simulated time pass at the replay's speed
+func (r *Replay) wait(ctx context.Context, d time.Duration) error {
+	if r.Speed <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(time.Duration(float64(d) / r.Speed))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
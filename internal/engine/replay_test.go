@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"terminal/internal/clock"
+	"terminal/internal/exchange"
+	"terminal/internal/strategy"
+	"terminal/internal/strategy/strategytest"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// fixtureSource serves the last candles of a fixture file
+type fixtureSource []hyperliquid.Candle
+
+func (s fixtureSource) FetchHistoricalCandles(symbol string, interval string, limit int) ([]hyperliquid.Candle, error) {
+	return s[max(len(s)-limit, 0):], nil
+}
+
+// turns goes long when the close turns up and short when it turns down,
+// with a stop and target on each entry and a partial exit every tenth bar.
+// Every signal depends only on the last three bars.
+type turns struct{}
+
+func (turns) GetMetadata() strategy.Metadata {
+	return strategy.Metadata{ID: "test-turns", Name: "Turns", Version: "1.0", WarmupBars: 2, Lookback: 20}
+}
+
+func (turns) ValidateParams(map[string]any) error { return nil }
+
+func (turns) Initialize(map[string]any) error { return nil }
+
+func (turns) GenerateSignals(candles []hyperliquid.Candle) []exchange.Signal {
+	var signals []exchange.Signal
+	for i := 2; i < len(candles); i++ {
+		c0, c1, c2 := parseFloat(candles[i-2].Close), parseFloat(candles[i-1].Close), parseFloat(candles[i].Close)
+		signal := exchange.Signal{Index: i, Price: c2, Time: candles[i].Timestamp}
+		switch {
+		case c2 > c1 && c1 <= c0:
+			signal.Type, signal.StopPrice, signal.TargetPrice = exchange.SignalLong, c2*0.99, c2*1.02
+		case c2 < c1 && c1 >= c0:
+			signal.Type, signal.StopPrice, signal.TargetPrice = exchange.SignalShort, c2*1.01, c2*0.98
+		case candles[i].Timestamp/3_600_000%10 == 0:
+			signal.Type, signal.SizeFraction = exchange.SignalPartialExit, 0.5
+		default:
+			continue
+		}
+		signals = append(signals, signal)
+	}
+	return signals
+}
+
+func (turns) GetVisualization([]hyperliquid.Candle) *strategy.Visualization {
+	return strategy.NewVisualization()
+}
+
+func TestReplayMatchesBacktest(t *testing.T) {
+	strategy.Register("test-turns", func() strategy.Strategy { return turns{} })
+	t.Cleanup(func() { strategy.Unregister("test-turns") })
+
+	candles, err := strategytest.LoadCandles("../strategy/strategytest/testdata/candles/BTC_1h.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := fixtureSource(candles)
+	config := ExecutionConfig{PositionSize: 0.1, TradeDirection: "both"}
+	limit := len(candles) - 10
+
+	want, err := NewBacktester().RunStrategy(source, turns{}, "BTC", "1h", limit, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := (&Replay{}).Run(context.Background(), source, "test-turns", "BTC", "1h", limit, nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want.TotalTrades < 20 {
+		t.Fatalf("backtest made only %d trades", want.TotalTrades)
+	}
+	if i := FirstDifference(got.Positions, want.Positions); i >= 0 {
+		t.Fatalf("trade %d differs (replay made %d trades, backtest %d):\nreplay   %+v\nbacktest %+v",
+			i, len(got.Positions), len(want.Positions), at(got.Positions, i), at(want.Positions, i))
+	}
+	if !near(got.TotalPnL, want.TotalPnL) || got.WinningTrades != want.WinningTrades {
+		t.Errorf("replay PnL %v with %d winners, backtest %v with %d",
+			got.TotalPnL, got.WinningTrades, want.TotalPnL, want.WinningTrades)
+	}
+}
+
+func TestReplayStopsOnCancel(t *testing.T) {
+	strategy.Register("test-turns", func() strategy.Strategy { return turns{} })
+	t.Cleanup(func() { strategy.Unregister("test-turns") })
+
+	candles, err := strategytest.LoadCandles("../strategy/strategytest/testdata/candles/BTC_1h.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// At real speed the second bar is an hour away
+	_, err = (&Replay{Speed: 1}).Run(ctx, fixtureSource(candles), "test-turns", "BTC", "1h", 100, nil, testConfig)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the context's error", err)
+	}
+}
+
+func TestSimClockDrivesRunLoop(t *testing.T) {
+	e := newTestEngine(t)
+	sim := clock.NewSim(time.Unix(0, 0))
+	e.poll = 0
+	e.SetClock(sim)
+
+	if err := e.StartStrategy("flip", "", "test-flip", "BTC", "1m", nil, testConfig); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if info, _ := runningInfo(e, "flip"); info.HasPosition {
+		t.Fatal("strategy traded before the clock moved")
+	}
+
+	// The run loop polls every fifth of the interval of simulated time
+	ticked := waitFor(t, func() bool {
+		sim.Advance(12 * time.Second)
+		info, _ := runningInfo(e, "flip")
+		return info.HasPosition
+	})
+	if !ticked {
+		t.Fatal("advancing the clock did not run the strategy")
+	}
+}
+
+// at returns trades[i], or an empty trade past the end
+func at(trades []exchange.Position, i int) exchange.Position {
+	if i < len(trades) {
+		return trades[i]
+	}
+	return exchange.Position{}
+}
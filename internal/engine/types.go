@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"fmt"
 	"time"
 
 	"terminal/internal/exchange"
@@ -8,6 +9,16 @@ import (
 	"terminal/internal/strategy"
 )
 
+// PanicError is a panic recovered from a live strategy
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("strategy panicked: %v", e.Value)
+}
+
 // ExecutionConfig is an alias to position.ExecutionConfig
 // This allows engine and position packages to share the same type
 type ExecutionConfig = position.ExecutionConfig
@@ -24,6 +35,32 @@ type LiveStrategy struct {
 	Position          *exchange.Position
 	LastCandleTime    int64
 	LastVisualization *strategy.Visualization
+
+	// Account is the id of the trading account orders are placed on
+	Account string
+	// Params are the strategy parameters in effect
+	Params map[string]any
+	// Paused strategies keep their position and its TP/SL but ignore signals
+	Paused bool
+	// Changes lists the params and config updates applied so far
+	Changes []ParamChange
+	// Error is set when the strategy panicked and stopped running
+	Error string
+	// Trades are the closed trades, oldest first; partial exits are trades
+	// of their own
+	Trades []exchange.Position
+	// Marks are the latest closes of the strategy's symbols, which its open
+	// positions are valued at
+	Marks map[string]float64
+
+	// Symbols and Legs are used by multi-asset strategies instead of Position
+	Symbols []string
+	Legs    map[string]*exchange.Position
+}
+
+// IsBasket reports whether this is a multi-asset strategy instance
+func (l *LiveStrategy) IsBasket() bool {
+	return len(l.Symbols) > 0
 }
 
 // GetID returns the strategy instance ID
@@ -51,19 +88,90 @@ func (l *LiveStrategy) SetPosition(pos *exchange.Position) {
 	l.Position = pos
 }
 
+// RecordTrade appends a closed trade
+func (l *LiveStrategy) RecordTrade(trade exchange.Position) {
+	l.Trades = append(l.Trades, trade)
+}
+
+// GetLeg returns the position for one leg of a multi-asset strategy
+func (l *LiveStrategy) GetLeg(symbol string) *exchange.Position {
+	return l.Legs[symbol]
+}
+
+// SetLeg sets the position for one leg of a multi-asset strategy
+func (l *LiveStrategy) SetLeg(symbol string, pos *exchange.Position) {
+	if l.Legs == nil {
+		l.Legs = make(map[string]*exchange.Position)
+	}
+	if pos == nil {
+		delete(l.Legs, symbol)
+		return
+	}
+	l.Legs[symbol] = pos
+}
+
+// ParamChange records a params or config update of a running strategy
+type ParamChange struct {
+	// RequestedAt is when the update was made and AppliedAt when it took
+	// effect on the bar opening at BarTime, all in unix milliseconds
+	RequestedAt    int64           `json:"requestedAt"`
+	AppliedAt      int64           `json:"appliedAt"`
+	BarTime        int64           `json:"barTime"`
+	Params         map[string]any  `json:"params"`
+	Config         ExecutionConfig `json:"config"`
+	PreviousParams map[string]any  `json:"previousParams"`
+	PreviousConfig ExecutionConfig `json:"previousConfig"`
+}
+
 // RunningStrategyInfo is the API response for running strategy info
 type RunningStrategyInfo struct {
 	ID           string          `json:"id"`
+	Account      string          `json:"account"`
 	StrategyID   string          `json:"strategyId"`
 	StrategyName string          `json:"strategyName"`
 	Symbol       string          `json:"symbol"`
 	Interval     string          `json:"interval"`
 	IsRunning    bool            `json:"isRunning"`
+	Paused       bool            `json:"paused"`
+	Params       map[string]any  `json:"params"`
 	Config       ExecutionConfig `json:"config"`
-	HasPosition  bool            `json:"hasPosition"`
-	PositionSide string          `json:"positionSide,omitempty"`
-	PositionSize float64         `json:"positionSize,omitempty"`
-	EntryPrice   float64         `json:"entryPrice,omitempty"`
+	// PendingUpdate is set while an update waits for the next bar
+	PendingUpdate bool `json:"pendingUpdate"`
+	// Error reports why a strategy that is no longer running failed
+	Error        string  `json:"error,omitempty"`
+	HasPosition  bool    `json:"hasPosition"`
+	PositionSide string  `json:"positionSide,omitempty"`
+	PositionSize float64 `json:"positionSize,omitempty"`
+	EntryPrice   float64 `json:"entryPrice,omitempty"`
+
+	// Multi-asset strategies report their symbols and open legs
+	Symbols []string            `json:"symbols,omitempty"`
+	Legs    []exchange.Position `json:"legs,omitempty"`
+
+	// Ledger accounts for the strategy's PnL so far
+	Ledger Ledger `json:"ledger"`
+}
+
+// StopSummary reports what stopping a strategy closed
+type StopSummary struct {
+	ID      string `json:"id"`
+	Account string `json:"account"`
+	Symbol  string `json:"symbol"`
+	// Closed holds the positions and legs closed by the stop
+	Closed []exchange.Position `json:"closed,omitempty"`
+	// RealizedPnL is the PnL realized by the closed positions, including
+	// earlier partial exits
+	RealizedPnL float64 `json:"realizedPnL"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// add records a position closed by the stop
+func (s *StopSummary) add(pos *exchange.Position) {
+	if pos == nil || pos.IsOpen {
+		return
+	}
+	s.Closed = append(s.Closed, *pos)
+	s.RealizedPnL += pos.PnL
 }
 
 // BacktestResult contains the results of a backtest run
@@ -74,7 +182,8 @@ type BacktestResult struct {
 	Signals         []exchange.Signal       `json:"signals"`
 	Visualization   *strategy.Visualization `json:"visualization"`
 
-	// Flattened visualization fields for frontend convenience
+	// Flattened version 1 visualization fields for the existing chart
+	// renderer; generic overlays are only available under Visualization
 	TrendLines  []float64        `json:"TrendLines"`
 	TrendColors []string         `json:"TrendColors"`
 	Directions  []int            `json:"Directions"`
@@ -82,19 +191,25 @@ type BacktestResult struct {
 	Lines       []strategy.Line  `json:"Lines"`
 
 	// Performance metrics
-	TotalPnL           float64       `json:"totalPnL"`
-	TotalPnLPercent    float64       `json:"totalPnLPercent"`
-	WinRate            float64       `json:"winRate"`
-	TotalTrades        int           `json:"totalTrades"`
-	WinningTrades      int           `json:"winningTrades"`
-	LosingTrades       int           `json:"losingTrades"`
-	AverageWin         float64       `json:"averageWin"`
-	AverageLoss        float64       `json:"averageLoss"`
-	ProfitFactor       float64       `json:"profitFactor"`
-	MaxDrawdown        float64       `json:"maxDrawdown"`
-	MaxDrawdownPercent float64       `json:"maxDrawdownPercent"`
-	SharpeRatio        float64       `json:"sharpeRatio"`
-	LongestWinStreak   int           `json:"longestWinStreak"`
-	LongestLossStreak  int           `json:"longestLossStreak"`
-	AverageHoldTime    time.Duration `json:"averageHoldTime"`
+	TotalPnL        float64 `json:"totalPnL"`
+	TotalPnLPercent float64 `json:"totalPnLPercent"`
+	WinRate         float64 `json:"winRate"`
+	TotalTrades     int     `json:"totalTrades"`
+	WinningTrades   int     `json:"winningTrades"`
+	LosingTrades    int     `json:"losingTrades"`
+	AverageWin      float64 `json:"averageWin"`
+	AverageLoss     float64 `json:"averageLoss"`
+	ProfitFactor    float64 `json:"profitFactor"`
+	// MaxDrawdown is the largest fall of the closed-trade equity curve
+	// from its high, zero or negative. It used to be the worst drawdown
+	// within a single trade. MaxDrawdownPercent is it as a percentage of
+	// the average capital a trade tied up
+	MaxDrawdown        float64 `json:"maxDrawdown"`
+	MaxDrawdownPercent float64 `json:"maxDrawdownPercent"`
+	// SharpeRatio is the mean return of a trade on the capital it tied up
+	// over the standard deviation of those returns, not annualized
+	SharpeRatio       float64       `json:"sharpeRatio"`
+	LongestWinStreak  int           `json:"longestWinStreak"`
+	LongestLossStreak int           `json:"longestLossStreak"`
+	AverageHoldTime   time.Duration `json:"averageHoldTime"`
 }
@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+
+	"terminal/internal/events"
+	"terminal/internal/logging"
+	"terminal/internal/strategy"
+)
+
+// pendingUpdate is a validated update waiting for the next bar
+type pendingUpdate struct {
+	strategy    strategy.Strategy
+	params      map[string]any
+	config      ExecutionConfig
+	requestedAt int64
+}
+
+// running returns the state of the running strategy id
+func (e *Engine) running(id string) (*liveStrategyState, error) {
+	e.strategiesMu.RLock()
+	defer e.strategiesMu.RUnlock()
+	state, exists := e.strategies[id]
+	if !exists {
+		return nil, fmt.Errorf("strategy %s not found", id)
+	}
+	return state, nil
+}
+
+// PauseStrategy makes a running strategy ignore new signals. Its position
+// stays open and its take profit and stop loss are still checked.
+func (e *Engine) PauseStrategy(id string) error {
+	return e.setPaused(id, true)
+}
+
+// ResumeStrategy makes a paused strategy act on signals again
+func (e *Engine) ResumeStrategy(id string) error {
+	return e.setPaused(id, false)
+}
+
+func (e *Engine) setPaused(id string, paused bool) error {
+	state, err := e.running(id)
+	if err != nil {
+		return err
+	}
+	state.mu.Lock()
+	defer state.unlock()
+	if state.Paused == paused {
+		return nil
+	}
+	state.Paused = paused
+	if paused {
+		e.publishState(state.LiveStrategy, events.StatePaused)
+	} else {
+		e.publishState(state.LiveStrategy, events.StateResumed)
+	}
+	return nil
+}
+
+// UpdateStrategy validates new params and execution config for a running
+// strategy and applies them together at its next bar. params are merged over
+// the current ones and a nil config keeps the current config. An update
+// made before the previous one was applied builds on and replaces it.
+func (e *Engine) UpdateStrategy(id string, params map[string]any, config *ExecutionConfig) error {
+	if config != nil {
+		if err := config.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	state, err := e.running(id)
+	if err != nil {
+		return err
+	}
+
+	state.mu.Lock()
+	defer state.unlock()
+
+	base, baseConfig := state.Params, state.Config
+	if state.pending != nil {
+		base, baseConfig = state.pending.params, state.pending.config
+	}
+	merged := maps.Clone(base)
+	if merged == nil {
+		merged = make(map[string]any, len(params))
+	}
+	maps.Copy(merged, params)
+	if config != nil {
+		baseConfig = *config
+	}
+
+	strat, err := newStrategy(state.Strategy.GetMetadata().ID, merged)
+	if err != nil {
+		return err
+	}
+	if _, ok := strat.(strategy.MultiAssetStrategy); state.IsBasket() && !ok {
+		return fmt.Errorf("strategy %s is not a multi-asset strategy", strat.GetMetadata().ID)
+	}
+
+	state.pending = &pendingUpdate{
+		strategy:    strat,
+		params:      merged,
+		config:      baseConfig,
+		requestedAt: e.clock.Now().UnixMilli(),
+	}
+	slog.Info("strategy update scheduled for next bar", logging.KeyStrategy, id,
+		"params", merged, "config", baseConfig)
+	return nil
+}
+
+// applyUpdate swaps in the pending update, if any, on the bar opening at
+// barTime. It runs on the strategy's own goroutine, between bars, so the
+// strategy and its position manager never see half an update.
+func (e *Engine) applyUpdate(state *liveStrategyState, barTime int64) bool {
+	state.mu.Lock()
+	update := state.pending
+	if update == nil {
+		state.unlock()
+		return false
+	}
+	state.pending = nil
+	change := ParamChange{
+		RequestedAt:    update.requestedAt,
+		AppliedAt:      e.clock.Now().UnixMilli(),
+		BarTime:        barTime,
+		Params:         update.params,
+		Config:         update.config,
+		PreviousParams: state.Params,
+		PreviousConfig: state.Config,
+	}
+	state.Strategy = update.strategy
+	state.Params = update.params
+	state.Config = update.config
+	state.Changes = append(state.Changes, change)
+	state.unlock()
+
+	slog.Info("strategy update applied", logging.KeyStrategy, state.ID,
+		"params", change.Params, "config", change.Config)
+	e.publishState(state.LiveStrategy, events.StateUpdated)
+	return true
+}
+
+// GetStrategyChanges returns the params and config updates applied to a
+// running strategy, oldest first
+func (e *Engine) GetStrategyChanges(id string) ([]ParamChange, error) {
+	state, err := e.running(id)
+	if err != nil {
+		return nil, err
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return append([]ParamChange{}, state.Changes...), nil
+}
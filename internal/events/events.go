@@ -0,0 +1,277 @@
+package events
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+
+	"terminal/internal/exchange"
+	"terminal/internal/logging"
+)
+
+// Kind identifies the type of an event
+type Kind string
+
+// Event kinds
+const (
+	KindCandleClosed         Kind = "candleClosed"
+	KindSignalGenerated      Kind = "signalGenerated"
+	KindOrderSubmitted       Kind = "orderSubmitted"
+	KindOrderFilled          Kind = "orderFilled"
+	KindPositionOpened       Kind = "positionOpened"
+	KindPositionUpdated      Kind = "positionUpdated"
+	KindPositionClosed       Kind = "positionClosed"
+	KindStrategyError        Kind = "strategyError"
+	KindStrategyStateChanged Kind = "strategyStateChanged"
+	KindTradingHalted        Kind = "tradingHalted"
+)
+
+// Kinds lists every event kind
+var Kinds = []Kind{
+	KindCandleClosed,
+	KindSignalGenerated,
+	KindOrderSubmitted,
+	KindOrderFilled,
+	KindPositionOpened,
+	KindPositionUpdated,
+	KindPositionClosed,
+	KindStrategyError,
+	KindStrategyStateChanged,
+	KindTradingHalted,
+}
+
+// Payload is the typed body of an event
+type Payload interface {
+	Kind() Kind
+}
+
+// Event is one piece of live engine activity
+type Event struct {
+	Kind Kind  `json:"type"`
+	Time int64 `json:"time"`
+	// Strategy is the id of the live strategy the event belongs to
+	Strategy string  `json:"strategy,omitempty"`
+	Data     Payload `json:"data"`
+}
+
+// CandleClosed is published when a live strategy sees a new closed candle
+type CandleClosed struct {
+	Symbol   string             `json:"symbol"`
+	Interval string             `json:"interval"`
+	Candle   hyperliquid.Candle `json:"candle"`
+}
+
+// SignalGenerated is published for each signal on the latest bar
+type SignalGenerated struct {
+	Symbol string          `json:"symbol"`
+	Signal exchange.Signal `json:"signal"`
+}
+
+// OrderSubmitted is published before an order is sent to the exchange
+type OrderSubmitted struct {
+	Symbol string  `json:"symbol"`
+	Side   string  `json:"side"`
+	Size   float64 `json:"size"`
+	Price  float64 `json:"price"`
+	// Reduce is set for orders that close or reduce a position
+	Reduce bool   `json:"reduce"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// OrderFilled is published once the exchange accepted an order
+type OrderFilled struct {
+	Symbol string  `json:"symbol"`
+	Side   string  `json:"side"`
+	Size   float64 `json:"size"`
+	Price  float64 `json:"price"`
+	// Fee is the fee paid at the account's fee rate, 0 when it is not known
+	Fee    float64 `json:"fee,omitempty"`
+	Reduce bool    `json:"reduce"`
+}
+
+// PositionOpened is published when a new position or leg is opened
+type PositionOpened struct {
+	Position exchange.Position `json:"position"`
+}
+
+// PositionUpdated is published when a position is scaled into or partly closed
+type PositionUpdated struct {
+	Position exchange.Position `json:"position"`
+}
+
+// PositionClosed is published when a position or leg is fully closed
+type PositionClosed struct {
+	Position exchange.Position `json:"position"`
+}
+
+// StrategyError reports a failure while running a live strategy
+type StrategyError struct {
+	Message string `json:"message"`
+}
+
+// Strategy states
+const (
+	StateStarted = "started"
+	StateStopped = "stopped"
+	StatePaused  = "paused"
+	StateResumed = "resumed"
+	// StateUpdated is published when new params or config take effect
+	StateUpdated = "updated"
+	// StateFailed is published when a strategy panicked and stopped
+	StateFailed = "failed"
+)
+
+// StrategyStateChanged is published when a live strategy starts, stops,
+// pauses, resumes, applies an update or fails
+type StrategyStateChanged struct {
+	State      string `json:"state"`
+	StrategyID string `json:"strategyId"`
+	Symbol     string `json:"symbol"`
+	Interval   string `json:"interval"`
+}
+
+// TradingHalted is published when a risk limit or the kill switch halts
+// trading. It belongs to no strategy.
+type TradingHalted struct {
+	Reason string `json:"reason"`
+}
+
+func (CandleClosed) Kind() Kind         { return KindCandleClosed }
+func (SignalGenerated) Kind() Kind      { return KindSignalGenerated }
+func (OrderSubmitted) Kind() Kind       { return KindOrderSubmitted }
+func (OrderFilled) Kind() Kind          { return KindOrderFilled }
+func (PositionOpened) Kind() Kind       { return KindPositionOpened }
+func (PositionUpdated) Kind() Kind      { return KindPositionUpdated }
+func (PositionClosed) Kind() Kind       { return KindPositionClosed }
+func (StrategyError) Kind() Kind        { return KindStrategyError }
+func (StrategyStateChanged) Kind() Kind { return KindStrategyStateChanged }
+func (TradingHalted) Kind() Kind        { return KindTradingHalted }
+
+// Bus fans events out to subscribers. A nil *Bus discards events, so
+// publishers do not need to check whether one is configured.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[int]*subscription
+	nextID int
+	// dropped counts the events missed by all subscribers so far
+	dropped atomic.Int64
+}
+
+// subscription is one subscriber's channel. A lossless subscription makes
+// Publish wait for buffer space; the others drop events and count them.
+type subscription struct {
+	ch       chan Event
+	lossless bool
+	dropped  atomic.Int64
+}
+
+// NewBus creates an event bus
+func NewBus() *Bus {
+	return &Bus{subs: make(map[int]*subscription)}
+}
+
+// Publish sends a payload for a strategy to every subscriber. Subscribers
+// whose buffer is full miss the event rather than blocking the engine,
+// except lossless ones, which Publish waits for.
+func (b *Bus) Publish(strategy string, data Payload) {
+	if b == nil {
+		return
+	}
+	e := Event{
+		Kind:     data.Kind(),
+		Time:     time.Now().UnixMilli(),
+		Strategy: strategy,
+		Data:     data,
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for id, sub := range b.subs {
+		if sub.lossless {
+			sub.ch <- e
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			// Log the first drop and then every hundredth, so a stalled
+			// subscriber does not flood the log
+			b.dropped.Add(1)
+			if n := sub.dropped.Add(1); n == 1 || n%100 == 0 {
+				slog.Warn("event subscriber is falling behind, dropping events",
+					logging.KeyStrategy, strategy, "event", string(e.Kind), "subscriber", id, "dropped", n)
+			}
+		}
+	}
+}
+
+// Dropped returns how many events subscribers have missed in total,
+// including ones that have since unsubscribed
+func (b *Bus) Dropped() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.dropped.Load()
+}
+
+// Subscribe returns a channel receiving events and a function that ends the
+// subscription and closes the channel
+func (b *Bus) Subscribe(buffer int) (<-chan Event, func()) {
+	return b.subscribe(buffer, false)
+}
+
+func (b *Bus) subscribe(buffer int, lossless bool) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+	if b == nil {
+		close(ch)
+		return ch, func() {}
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = &subscription{ch: ch, lossless: lossless}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Handle calls fn for every event on its own goroutine. The returned
+// function ends the subscription and waits until queued events are handled.
+// Events arriving while the buffer is full are dropped.
+func (b *Bus) Handle(buffer int, fn func(Event)) func() {
+	return b.handle(buffer, false, fn)
+}
+
+// HandleAll is like Handle, but Publish waits for buffer space instead of
+// dropping events, so fn sees every event. fn must not publish or block for
+// long, as it holds up the engine.
+func (b *Bus) HandleAll(buffer int, fn func(Event)) func() {
+	return b.handle(buffer, true, fn)
+}
+
+func (b *Bus) handle(buffer int, lossless bool, fn func(Event)) func() {
+	ch, unsubscribe := b.subscribe(buffer, lossless)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range ch {
+			fn(e)
+		}
+	}()
+	return func() {
+		unsubscribe()
+		<-done
+	}
+}
@@ -0,0 +1,75 @@
+package events
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHandleAllSeesEveryEvent(t *testing.T) {
+	bus := NewBus()
+	release := make(chan struct{})
+	var all, some atomic.Int64
+	stopAll := bus.HandleAll(1, func(e Event) {
+		<-release
+		all.Add(1)
+	})
+	stopSome := bus.Handle(1, func(e Event) {
+		<-release
+		some.Add(1)
+	})
+
+	// The slow handlers hold up Publish, which is released after a while
+	const n = 50
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	for range n {
+		bus.Publish("test", StrategyError{Message: "boom"})
+	}
+	stopAll()
+	stopSome()
+
+	if all.Load() != n {
+		t.Errorf("lossless handler saw %d of %d events", all.Load(), n)
+	}
+	if some.Load()+bus.Dropped() != n || bus.Dropped() == 0 {
+		t.Errorf("buffered handler saw %d and dropped %d of %d events", some.Load(), bus.Dropped(), n)
+	}
+}
+
+func TestDroppedCountsOnlyLossySubscribers(t *testing.T) {
+	bus := NewBus()
+	lossless, stop := bus.subscribe(1, true)
+	defer stop()
+	// Nobody reads the lossy subscription, so it keeps one event and drops the rest
+	_, stopLossy := bus.Subscribe(1)
+	defer stopLossy()
+
+	const n = 5
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range n {
+			bus.Publish("test", StrategyError{Message: string(rune('a' + i))})
+		}
+	}()
+
+	// Publish waits on the full lossless buffer until each event is read
+	for i := range n {
+		select {
+		case e := <-lossless:
+			if got, want := e.Data.(StrategyError).Message, string(rune('a'+i)); got != want {
+				t.Fatalf("event %d is %q, want %q", i, got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("lossless subscriber got only %d of %d events", i, n)
+		}
+	}
+	<-done
+
+	if got := bus.Dropped(); got != n-1 {
+		t.Errorf("dropped %d events, want the lossy subscriber's %d", got, n-1)
+	}
+}
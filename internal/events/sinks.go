@@ -0,0 +1,119 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"terminal/internal/logging"
+)
+
+// LogSink returns a handler logging each event with its strategy and symbol
+func LogSink(logger *slog.Logger) func(Event) {
+	return func(e Event) {
+		level := slog.LevelInfo
+		if e.Kind == KindStrategyError || e.Kind == KindTradingHalted {
+			level = slog.LevelError
+		}
+		attrs := []any{logging.KeyStrategy, e.Strategy, "event", string(e.Kind)}
+		if symbol := Symbol(e); symbol != "" {
+			attrs = append(attrs, logging.KeySymbol, symbol)
+		}
+		logger.Log(context.Background(), level, Describe(e), attrs...)
+	}
+}
+
+// Symbol returns the symbol an event is about, or "" when it has none
+func Symbol(e Event) string {
+	switch d := e.Data.(type) {
+	case CandleClosed:
+		return d.Symbol
+	case SignalGenerated:
+		return d.Symbol
+	case OrderSubmitted:
+		return d.Symbol
+	case OrderFilled:
+		return d.Symbol
+	case PositionOpened:
+		return d.Position.Symbol
+	case PositionUpdated:
+		return d.Position.Symbol
+	case PositionClosed:
+		return d.Position.Symbol
+	case StrategyStateChanged:
+		return d.Symbol
+	default:
+		return ""
+	}
+}
+
+// Describe renders an event as a human-readable message
+func Describe(e Event) string {
+	switch d := e.Data.(type) {
+	case CandleClosed:
+		return fmt.Sprintf("New candle %s: O=%s H=%s L=%s C=%s @ %s",
+			d.Symbol, d.Candle.Open, d.Candle.High, d.Candle.Low, d.Candle.Close,
+			time.UnixMilli(d.Candle.Timestamp).Format("15:04:05"))
+	case SignalGenerated:
+		return fmt.Sprintf("%s SIGNAL on %s at %.2f", d.Signal.Type, d.Symbol, d.Signal.Price)
+	case OrderSubmitted:
+		action := "Opening"
+		if d.Reduce {
+			action = "Closing"
+		}
+		msg := fmt.Sprintf("%s %s %s: size=%.4f", action, d.Side, d.Symbol, d.Size)
+		if d.Reason != "" {
+			msg += " - " + d.Reason
+		}
+		return msg
+	case OrderFilled:
+		return fmt.Sprintf("Filled %s %s %.4f @ %.2f", d.Side, d.Symbol, d.Size, d.Price)
+	case PositionOpened:
+		return fmt.Sprintf("Position opened: %s %s %.4f @ %.2f",
+			d.Position.Side, d.Position.Symbol, d.Position.Size, d.Position.EntryPrice)
+	case PositionUpdated:
+		return fmt.Sprintf("Position updated: %s %s size=%.4f entry=%.2f PnL=%.2f",
+			d.Position.Side, d.Position.Symbol, d.Position.Size, d.Position.EntryPrice, d.Position.PnL)
+	case PositionClosed:
+		return fmt.Sprintf("Position closed: %s %s, %s, PnL: %.2f",
+			d.Position.Side, d.Position.Symbol, d.Position.ExitReason, d.Position.PnL)
+	case StrategyError:
+		return "Error: " + d.Message
+	case StrategyStateChanged:
+		return fmt.Sprintf("Strategy %s: %s on %s %s", d.State, d.StrategyID, d.Symbol, d.Interval)
+	case TradingHalted:
+		return "Trading halted: " + d.Reason
+	default:
+		return string(e.Kind)
+	}
+}
+
+// Webhook returns a handler posting events of the given kinds as JSON to
+// url, for notification services such as Slack, Discord or ntfy relays. No
+// kinds posts every event.
+func Webhook(url string, kinds ...Kind) func(Event) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func(e Event) {
+		if len(kinds) > 0 && !slices.Contains(kinds, e.Kind) {
+			return
+		}
+		body, err := json.Marshal(struct {
+			Event
+			Text string `json:"text"`
+		}{e, fmt.Sprintf("[%s] %s", e.Strategy, Describe(e))})
+		if err != nil {
+			return
+		}
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			slog.Warn("webhook failed", "error", err)
+			return
+		}
+		resp.Body.Close()
+	}
+}
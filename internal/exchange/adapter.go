@@ -3,7 +3,8 @@ package exchange
 // Adapter abstracts exchange operations
 // This allows strategies to be exchange-agnostic and testable
 type Adapter interface {
-	// OpenPosition opens a new position
+	// OpenPosition opens a new position. The returned EntryPrice is the
+	// average fill price, or 0 when it is not known.
 	OpenPosition(symbol string, side string, size float64, leverage int) (*Position, error)
 
 	// ClosePosition closes an existing position
@@ -21,3 +22,39 @@ type Adapter interface {
 	// GetAddress returns the wallet address
 	GetAddress() string
 }
+
+// PriceObserver is implemented by adapters that track the latest price of
+// the symbols traded through them, such as risk guards
+type PriceObserver interface {
+	ObservePrice(symbol string, price float64)
+}
+
+// PriceQuoter is implemented by adapters that can quote the current price of
+// a symbol
+type PriceQuoter interface {
+	// MidPrice returns the current mid price of symbol
+	MidPrice(symbol string) (float64, error)
+}
+
+// FillReporter is implemented by adapters that report the price their close
+// orders filled at
+type FillReporter interface {
+	// ClosePositionFill closes a position like ClosePosition and returns the
+	// average fill price, or 0 when it is not known
+	ClosePositionFill(symbol string, size float64) (float64, error)
+}
+
+// FeeReporter is implemented by adapters that know the fee rate of the
+// account
+type FeeReporter interface {
+	// TakerFeeRate returns the fee charged on the notional of market
+	// orders, e.g. 0.00045 for 4.5 bps
+	TakerFeeRate() (float64, error)
+}
+
+// FundingReporter is implemented by adapters that know perp funding rates
+type FundingReporter interface {
+	// FundingRate returns the current hourly funding rate of symbol. Longs
+	// pay shorts when it is positive.
+	FundingRate(symbol string) (float64, error)
+}
@@ -1,69 +1,208 @@
 package exchange
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/sonirico/go-hyperliquid"
+
+	"terminal/internal/logging"
 )
 
-// HyperliquidAdapter implements Adapter for Hyperliquid exchange
+// ErrReadOnly is returned for orders when no private key is configured
+var ErrReadOnly = errors.New("read-only mode: no private key configured")
+
+// ErrNoAddress is returned for account queries when no address is configured
+var ErrNoAddress = errors.New("no account address configured")
+
+// HyperliquidAdapter implements Adapter for Hyperliquid exchange. The
+// signing key and the queried account are separate: with an agent (API)
+// wallet the key signs orders for the account that approved it.
 type HyperliquidAdapter struct {
-	ctx        context.Context
-	info       *hyperliquid.Info
-	exchange   *hyperliquid.Exchange
-	address    string
-	privateKey *ecdsa.PrivateKey
+	ctx    context.Context
+	apiURL string
+	info   *hyperliquid.Info
+	// address is the account traded and queried
+	address string
+	// vault is the sub-account or vault address orders are signed for
+	vault string
+
+	mu sync.RWMutex
+	// exchange is nil until a signing key is set
+	exchange *hyperliquid.Exchange
+	signer   string
 }
 
-// NewHyperliquidAdapter creates a new Hyperliquid adapter
+// NewHyperliquidAdapter creates a new Hyperliquid adapter for the account at
+// address. Without a private key the adapter is read-only: account queries
+// work but orders fail with ErrReadOnly until SetSigner is called.
 func NewHyperliquidAdapter(ctx context.Context, privateKey *ecdsa.PrivateKey, address string, apiURL string) *HyperliquidAdapter {
-	exchange := hyperliquid.NewExchange(ctx, privateKey, apiURL, nil, "", "", nil, hyperliquid.ExchangeOptClientOptions())
-	info := hyperliquid.NewInfo(ctx, apiURL, true, nil, nil, hyperliquid.InfoOptClientOptions())
+	return newHyperliquidAdapter(ctx, privateKey, address, "", apiURL)
+}
+
+// NewHyperliquidVaultAdapter creates an adapter for a sub-account or vault.
+// Orders are signed by the master account's key, or its agent, on behalf of
+// vault, and account queries report the vault's balances and positions.
+func NewHyperliquidVaultAdapter(ctx context.Context, privateKey *ecdsa.PrivateKey, vault string, apiURL string) *HyperliquidAdapter {
+	return newHyperliquidAdapter(ctx, privateKey, vault, vault, apiURL)
+}
+
+func newHyperliquidAdapter(ctx context.Context, privateKey *ecdsa.PrivateKey, address, vault, apiURL string) *HyperliquidAdapter {
+	h := &HyperliquidAdapter{
+		ctx:     ctx,
+		apiURL:  apiURL,
+		info:    hyperliquid.NewInfo(ctx, apiURL, true, nil, nil, hyperliquid.InfoOptClientOptions()),
+		address: address,
+		vault:   vault,
+	}
+	if privateKey != nil {
+		h.SetSigner(privateKey)
+	}
+	return h
+}
+
+// SetSigner sets the key orders are signed with. For the main account, a
+// key whose address differs from the account must be an agent wallet
+// approved by it.
+func (h *HyperliquidAdapter) SetSigner(privateKey *ecdsa.PrivateKey) {
+	signer := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	accountAddr := ""
+	if h.vault == "" && !strings.EqualFold(signer, h.address) {
+		accountAddr = h.address
+	}
+	exchange := hyperliquid.NewExchange(h.ctx, privateKey, h.apiURL, nil, h.vault, accountAddr, nil, hyperliquid.ExchangeOptClientOptions())
+
+	h.mu.Lock()
+	h.exchange = exchange
+	h.signer = signer
+	h.mu.Unlock()
+}
+
+// SignerAddress returns the address of the signing key, or "" when read-only
+func (h *HyperliquidAdapter) SignerAddress() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.signer
+}
 
-	return &HyperliquidAdapter{
-		ctx:        ctx,
-		exchange:   exchange,
-		info:       info,
-		address:    address,
-		privateKey: privateKey,
+// AgentApproved reports whether the signing key is the account itself or an
+// agent wallet the account has approved. Vault adapters report true since
+// the master account signs for them.
+func (h *HyperliquidAdapter) AgentApproved() (bool, error) {
+	signer := h.SignerAddress()
+	if signer == "" {
+		return false, ErrReadOnly
 	}
+	if h.vault != "" || strings.EqualFold(signer, h.address) {
+		return true, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"type": "extraAgents", "user": h.address})
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(h.ctx, http.MethodPost, strings.TrimRight(h.apiURL, "/")+"/info", bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch agents: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("failed to fetch agents: %s", resp.Status)
+	}
+
+	var agents []struct {
+		Address string `json:"address"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&agents); err != nil {
+		return false, fmt.Errorf("failed to decode agents: %w", err)
+	}
+	for _, agent := range agents {
+		if strings.EqualFold(agent.Address, signer) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// trader returns the exchange client, or ErrReadOnly without a signing key
+func (h *HyperliquidAdapter) trader() (*hyperliquid.Exchange, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.exchange == nil {
+		return nil, ErrReadOnly
+	}
+	return h.exchange, nil
 }
 
 // OpenPosition opens a new position on Hyperliquid
 func (h *HyperliquidAdapter) OpenPosition(symbol string, side string, size float64, leverage int) (*Position, error) {
-	_, err := h.exchange.UpdateLeverage(h.ctx, leverage, symbol, false)
+	exchange, err := h.trader()
 	if err != nil {
+		return nil, err
+	}
+	if _, err := exchange.UpdateLeverage(h.ctx, leverage, symbol, false); err != nil {
 		return nil, fmt.Errorf("failed to set leverage: %w", err)
 	}
 
 	isBuy := side == "long"
-	resp, err := h.exchange.MarketOpen(h.ctx, symbol, isBuy, size, nil, 0.05, nil, nil)
+	resp, err := exchange.MarketOpen(h.ctx, symbol, isBuy, size, nil, 0.05, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open position: %w", err)
 	}
 
 	orderResp := parseOrderResponse(resp)
 	if !orderResp.Success {
+		slog.Warn("open order rejected", logging.KeySymbol, symbol, "side", side, "size", size, "message", orderResp.Message)
 		return nil, fmt.Errorf("position open failed: %s", orderResp.Message)
 	}
+	slog.Info("open order placed", logging.KeySymbol, symbol, logging.KeyOrderID, orderResp.OrderID,
+		"side", side, "size", size, "leverage", leverage, "status", orderResp.Message)
 
-	return &Position{
+	pos := &Position{
 		EntryTime: time.Now().UnixMilli(),
 		Side:      side,
 		Size:      size,
 		IsOpen:    true,
-	}, nil
+	}
+	if resp.Filled != nil {
+		pos.EntryPrice = parseFloatSafe(resp.Filled.AvgPx)
+	}
+	return pos, nil
 }
 
 // ClosePosition closes an existing position on Hyperliquid
 func (h *HyperliquidAdapter) ClosePosition(symbol string, size float64) error {
+	_, err := h.ClosePositionFill(symbol, size)
+	return err
+}
+
+// ClosePositionFill closes an existing position on Hyperliquid and returns
+// the average fill price of the close order
+func (h *HyperliquidAdapter) ClosePositionFill(symbol string, size float64) (float64, error) {
+	exchange, err := h.trader()
+	if err != nil {
+		return 0, err
+	}
+
 	userState, err := h.info.UserState(h.ctx, h.address)
 	if err != nil {
-		return fmt.Errorf("failed to fetch position: %w", err)
+		return 0, fmt.Errorf("failed to fetch position: %w", err)
 	}
 
 	var positionSize float64
@@ -74,7 +213,7 @@ func (h *HyperliquidAdapter) ClosePosition(symbol string, size float64) error {
 		if assetPos.Position.Coin == symbol {
 			szi := parseFloatSafe(assetPos.Position.Szi)
 			if szi == 0 {
-				return fmt.Errorf("no open position for %s", symbol)
+				return 0, fmt.Errorf("no open position for %s", symbol)
 			}
 
 			isBuy = szi < 0
@@ -89,15 +228,15 @@ func (h *HyperliquidAdapter) ClosePosition(symbol string, size float64) error {
 	}
 
 	if !found {
-		return fmt.Errorf("position not found for %s", symbol)
+		return 0, fmt.Errorf("position not found for %s", symbol)
 	}
 
-	slippagePrice, err := h.exchange.SlippagePrice(h.ctx, symbol, isBuy, 0.05, nil)
+	slippagePrice, err := exchange.SlippagePrice(h.ctx, symbol, isBuy, 0.05, nil)
 	if err != nil {
-		return fmt.Errorf("failed to get slippage price: %w", err)
+		return 0, fmt.Errorf("failed to get slippage price: %w", err)
 	}
 
-	resp, err := h.exchange.Order(h.ctx, hyperliquid.CreateOrderRequest{
+	resp, err := exchange.Order(h.ctx, hyperliquid.CreateOrderRequest{
 		Coin:       symbol,
 		IsBuy:      isBuy,
 		Size:       positionSize,
@@ -107,15 +246,60 @@ func (h *HyperliquidAdapter) ClosePosition(symbol string, size float64) error {
 	}, nil)
 
 	if err != nil {
-		return fmt.Errorf("failed to close position: %w", err)
+		return 0, fmt.Errorf("failed to close position: %w", err)
 	}
 
 	orderResp := parseOrderResponse(resp)
 	if !orderResp.Success {
-		return fmt.Errorf("position close failed: %s", orderResp.Message)
+		slog.Warn("close order rejected", logging.KeySymbol, symbol, "size", positionSize, "message", orderResp.Message)
+		return 0, fmt.Errorf("position close failed: %s", orderResp.Message)
 	}
+	slog.Info("close order placed", logging.KeySymbol, symbol, logging.KeyOrderID, orderResp.OrderID,
+		"size", positionSize, "status", orderResp.Message)
 
-	return nil
+	if resp.Filled == nil {
+		return 0, nil
+	}
+	return parseFloatSafe(resp.Filled.AvgPx), nil
+}
+
+// MidPrice returns the current mid price of symbol
+func (h *HyperliquidAdapter) MidPrice(symbol string) (float64, error) {
+	mids, err := h.info.AllMids(h.ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch mid prices: %w", err)
+	}
+	mid, ok := mids[symbol]
+	if !ok {
+		return 0, fmt.Errorf("no mid price for %s", symbol)
+	}
+	return parseFloatSafe(mid), nil
+}
+
+// TakerFeeRate returns the account's fee rate for orders that cross the book
+func (h *HyperliquidAdapter) TakerFeeRate() (float64, error) {
+	if h.address == "" {
+		return 0, ErrNoAddress
+	}
+	fees, err := h.info.UserFees(h.ctx, h.address)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch fee rates: %w", err)
+	}
+	return parseFloatSafe(fees.UserCrossRate), nil
+}
+
+// FundingRate returns the current hourly funding rate of symbol
+func (h *HyperliquidAdapter) FundingRate(symbol string) (float64, error) {
+	meta, err := h.info.MetaAndAssetCtxs(h.ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch funding rates: %w", err)
+	}
+	for i, asset := range meta.Universe {
+		if asset.Name == symbol && i < len(meta.Ctxs) {
+			return parseFloatSafe(meta.Ctxs[i].Funding), nil
+		}
+	}
+	return 0, fmt.Errorf("no funding rate for %s", symbol)
 }
 
 // GetPositions returns all open positions
@@ -138,6 +322,9 @@ func (h *HyperliquidAdapter) GetBalance() (float64, error) {
 
 // GetPortfolio returns the full portfolio summary
 func (h *HyperliquidAdapter) GetPortfolio() (*PortfolioSummary, error) {
+	if h.address == "" {
+		return nil, ErrNoAddress
+	}
 	userState, err := h.info.UserState(h.ctx, h.address)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch user state: %w", err)
@@ -217,8 +404,10 @@ func parseOrderResponse(resp hyperliquid.OrderStatus) OrderResponse {
 	out := OrderResponse{Success: true}
 	if resp.Resting != nil {
 		out.Message = resp.Resting.Status
+		out.OrderID = resp.Resting.Oid
 	} else if resp.Filled != nil {
 		out.Message = fmt.Sprintf("filled avgPx=%s size=%s", resp.Filled.AvgPx, resp.Filled.TotalSz)
+		out.OrderID = int64(resp.Filled.Oid)
 	} else if resp.Error != nil {
 		out.Success = false
 		out.Message = *resp.Error
@@ -226,5 +415,11 @@ func parseOrderResponse(resp hyperliquid.OrderStatus) OrderResponse {
 	return out
 }
 
-// Verify HyperliquidAdapter implements Adapter
-var _ Adapter = (*HyperliquidAdapter)(nil)
+// Verify HyperliquidAdapter implements Adapter and the optional interfaces
+var (
+	_ Adapter         = (*HyperliquidAdapter)(nil)
+	_ PriceQuoter     = (*HyperliquidAdapter)(nil)
+	_ FillReporter    = (*HyperliquidAdapter)(nil)
+	_ FeeReporter     = (*HyperliquidAdapter)(nil)
+	_ FundingReporter = (*HyperliquidAdapter)(nil)
+)
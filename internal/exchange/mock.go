@@ -1,47 +1,131 @@
 package exchange
 
-import "time"
+import (
+	"fmt"
+	"sync"
+	"time"
+)
 
-// MockAdapter is a mock implementation for backtesting and testing
+// MockAdapter is a mock implementation for backtesting and testing. It is
+// safe for concurrent use by several strategies.
 type MockAdapter struct {
+	mu        sync.Mutex
 	positions map[string]*Position
-	balance   float64
-	address   string
+	// marks holds the latest observed price of each symbol; closes fill there
+	marks map[string]float64
+	// feeRate and funding are the fee and hourly funding rates charged;
+	// both default to 0
+	feeRate float64
+	funding map[string]float64
+	balance float64
+	address string
 }
 
 // NewMockAdapter creates a new mock exchange adapter
 func NewMockAdapter(initialBalance float64) *MockAdapter {
 	return &MockAdapter{
 		positions: make(map[string]*Position),
+		marks:     make(map[string]float64),
+		funding:   make(map[string]float64),
 		balance:   initialBalance,
 		address:   "mock-address",
 	}
 }
 
-// OpenPosition simulates opening a position
+// OpenPosition simulates opening a position at the latest observed price
 func (m *MockAdapter) OpenPosition(symbol string, side string, size float64, leverage int) (*Position, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	pos := &Position{
-		EntryTime: time.Now().UnixMilli(),
-		Side:      side,
-		Size:      size,
-		IsOpen:    true,
+		EntryTime:  time.Now().UnixMilli(),
+		EntryPrice: m.marks[symbol],
+		Side:       side,
+		Size:       size,
+		IsOpen:     true,
 	}
-	m.positions[symbol] = pos
-	return pos, nil
+	if existing, ok := m.positions[symbol]; ok && existing.Side == side {
+		existing.Size += size
+	} else {
+		m.positions[symbol] = pos
+	}
+	// The caller gets its own copy, like from a real exchange
+	opened := *pos
+	return &opened, nil
 }
 
 // ClosePosition simulates closing a position
 func (m *MockAdapter) ClosePosition(symbol string, size float64) error {
+	_, err := m.ClosePositionFill(symbol, size)
+	return err
+}
+
+// ClosePositionFill simulates closing size units of a position, all of it
+// when size is 0, at the latest observed price
+func (m *MockAdapter) ClosePositionFill(symbol string, size float64) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if pos, exists := m.positions[symbol]; exists {
-		pos.IsOpen = false
-		pos.ExitTime = time.Now().UnixMilli()
-		delete(m.positions, symbol)
+		if size > 0 && size < pos.Size {
+			pos.Size -= size
+		} else {
+			pos.IsOpen = false
+			pos.ExitTime = time.Now().UnixMilli()
+			delete(m.positions, symbol)
+		}
+	}
+	return m.marks[symbol], nil
+}
+
+// ObservePrice records the latest price of symbol
+func (m *MockAdapter) ObservePrice(symbol string, price float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.marks[symbol] = price
+}
+
+// MidPrice returns the latest observed price of symbol
+func (m *MockAdapter) MidPrice(symbol string) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	price, ok := m.marks[symbol]
+	if !ok {
+		return 0, fmt.Errorf("no price observed for %s", symbol)
 	}
-	return nil
+	return price, nil
+}
+
+// TakerFeeRate returns the simulated fee rate
+func (m *MockAdapter) TakerFeeRate() (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.feeRate, nil
+}
+
+// SetFeeRate sets the simulated fee rate
+func (m *MockAdapter) SetFeeRate(rate float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.feeRate = rate
+}
+
+// FundingRate returns the simulated hourly funding rate of symbol
+func (m *MockAdapter) FundingRate(symbol string) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.funding[symbol], nil
+}
+
+// SetFundingRate sets the simulated hourly funding rate of symbol
+func (m *MockAdapter) SetFundingRate(symbol string, rate float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.funding[symbol] = rate
 }
 
 // GetPositions returns all simulated open positions
 func (m *MockAdapter) GetPositions() ([]ActivePosition, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	result := make([]ActivePosition, 0, len(m.positions))
 	for symbol, pos := range m.positions {
 		if pos.IsOpen {
@@ -56,6 +140,8 @@ func (m *MockAdapter) GetPositions() ([]ActivePosition, error) {
 
 // GetBalance returns the mock balance
 func (m *MockAdapter) GetBalance() (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return m.balance, nil
 }
 
@@ -77,14 +163,27 @@ func (m *MockAdapter) GetAddress() string {
 
 // SetBalance allows setting the mock balance for testing
 func (m *MockAdapter) SetBalance(balance float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.balance = balance
 }
 
 // Reset clears all positions and resets balance
 func (m *MockAdapter) Reset(initialBalance float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.positions = make(map[string]*Position)
+	m.marks = make(map[string]float64)
+	m.funding = make(map[string]float64)
 	m.balance = initialBalance
 }
 
-// Verify MockAdapter implements Adapter
-var _ Adapter = (*MockAdapter)(nil)
+// Verify MockAdapter implements Adapter and the optional interfaces
+var (
+	_ Adapter         = (*MockAdapter)(nil)
+	_ PriceObserver   = (*MockAdapter)(nil)
+	_ PriceQuoter     = (*MockAdapter)(nil)
+	_ FillReporter    = (*MockAdapter)(nil)
+	_ FeeReporter     = (*MockAdapter)(nil)
+	_ FundingReporter = (*MockAdapter)(nil)
+)
@@ -0,0 +1,64 @@
+package exchange
+
+// Account kinds
+const (
+	AccountMain       = "main"
+	AccountSubAccount = "subAccount"
+	AccountVault      = "vault"
+)
+
+// AccountInfo describes a named trading account
+type AccountInfo struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	Address string `json:"address"`
+	// Leverage is the default leverage of new positions on the account
+	Leverage int `json:"leverage"`
+}
+
+// AccountPortfolio is the portfolio of one named trading account
+type AccountPortfolio struct {
+	Account string            `json:"account"`
+	Address string            `json:"address"`
+	Summary *PortfolioSummary `json:"summary,omitempty"`
+	// Error is set instead of Summary when the account could not be queried
+	Error string `json:"error,omitempty"`
+}
+
+// AccountPosition is an open position tagged with its account
+type AccountPosition struct {
+	Account string `json:"account"`
+	ActivePosition
+}
+
+// AggregatedPortfolio combines the portfolios of several accounts
+type AggregatedPortfolio struct {
+	Accounts        []AccountPortfolio `json:"accounts"`
+	AccountValue    float64            `json:"accountValue"`
+	TotalMarginUsed float64            `json:"totalMarginUsed"`
+	WithdrawAvail   float64            `json:"withdrawAvail"`
+	UnrealizedPnL   float64            `json:"unrealizedPnL"`
+	Positions       []AccountPosition  `json:"positions"`
+}
+
+// AggregatePortfolios sums balances and collects positions across accounts.
+// Accounts that failed to load are listed but not counted.
+func AggregatePortfolios(accounts []AccountPortfolio) AggregatedPortfolio {
+	out := AggregatedPortfolio{
+		Accounts:  accounts,
+		Positions: []AccountPosition{},
+	}
+	for _, account := range accounts {
+		if account.Summary == nil {
+			continue
+		}
+		out.AccountValue += parseFloatSafe(account.Summary.Balance.AccountValue)
+		out.TotalMarginUsed += parseFloatSafe(account.Summary.Balance.TotalMarginUsed)
+		out.WithdrawAvail += parseFloatSafe(account.Summary.Balance.WithdrawAvail)
+		for _, pos := range account.Summary.Positions {
+			out.UnrealizedPnL += pos.UnrealizedPnL
+			out.Positions = append(out.Positions, AccountPosition{Account: account.Account, ActivePosition: pos})
+		}
+	}
+	return out
+}
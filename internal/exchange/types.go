@@ -1,14 +1,86 @@
 package exchange
 
+import (
+	"fmt"
+	"math"
+)
+
 // SignalType represents the type of trading signal
 type SignalType int
 
 const (
 	SignalNone SignalType = iota
+	// SignalLong opens a long position, reversing any open short
 	SignalLong
+	// SignalShort opens a short position, reversing any open long
 	SignalShort
+	// SignalExitLong closes an open long position
+	SignalExitLong
+	// SignalExitShort closes an open short position
+	SignalExitShort
+	// SignalFlatten closes any open position regardless of side
+	SignalFlatten
+	// SignalScaleIn adds to the open position on its current side
+	SignalScaleIn
+	// SignalPartialExit reduces the open position by SizeFraction
+	SignalPartialExit
 )
 
+// String returns a human readable name for the signal type
+func (t SignalType) String() string {
+	switch t {
+	case SignalNone:
+		return "NONE"
+	case SignalLong:
+		return "LONG"
+	case SignalShort:
+		return "SHORT"
+	case SignalExitLong:
+		return "EXIT LONG"
+	case SignalExitShort:
+		return "EXIT SHORT"
+	case SignalFlatten:
+		return "FLATTEN"
+	case SignalScaleIn:
+		return "SCALE IN"
+	case SignalPartialExit:
+		return "PARTIAL EXIT"
+	default:
+		return fmt.Sprintf("SignalType(%d)", int(t))
+	}
+}
+
+// IsEntry reports whether the signal opens a new position
+func (t SignalType) IsEntry() bool {
+	return t == SignalLong || t == SignalShort
+}
+
+// Side returns "long" or "short" for entry signals and "" otherwise
+func (t SignalType) Side() string {
+	switch t {
+	case SignalLong:
+		return "long"
+	case SignalShort:
+		return "short"
+	default:
+		return ""
+	}
+}
+
+// Closes reports whether the signal closes (part of) a position on the given side
+func (t SignalType) Closes(side string) bool {
+	switch t {
+	case SignalExitLong:
+		return side == "long"
+	case SignalExitShort:
+		return side == "short"
+	case SignalFlatten, SignalPartialExit:
+		return side != ""
+	default:
+		return false
+	}
+}
+
 // Signal represents a trading signal generated by a strategy
 type Signal struct {
 	Index  int        `json:"index"`
@@ -16,6 +88,44 @@ type Signal struct {
 	Price  float64    `json:"price"`
 	Time   int64      `json:"time"`
 	Reason string     `json:"reason"`
+
+	// SizeFraction scales the signal: for entries and scale-ins it is a
+	// fraction of ExecutionConfig.PositionSize, for exits a fraction of the
+	// open size. Zero means the full amount. Flatten always closes all of it.
+	SizeFraction float64 `json:"sizeFraction,omitempty"`
+	// StopPrice attaches a protective stop to the resulting position
+	StopPrice float64 `json:"stopPrice,omitempty"`
+	// TargetPrice attaches a take-profit target to the resulting position
+	TargetPrice float64 `json:"targetPrice,omitempty"`
+	// Tag is a free-form label carried onto the position, e.g. "breakout"
+	Tag string `json:"tag,omitempty"`
+	// Symbol selects the leg for multi-asset strategies; empty means the
+	// strategy's only symbol
+	Symbol string `json:"symbol,omitempty"`
+}
+
+// Fraction returns SizeFraction clamped to (0, 1], treating zero as 1
+func (s Signal) Fraction() float64 {
+	if s.SizeFraction <= 0 || s.SizeFraction > 1 {
+		return 1
+	}
+	return s.SizeFraction
+}
+
+// ExitFraction returns the fraction of the open size an exit signal closes
+func (s Signal) ExitFraction() float64 {
+	if s.Type == SignalFlatten {
+		return 1
+	}
+	return s.Fraction()
+}
+
+// EntrySize returns the order size for an entry or scale-in signal
+func (s Signal) EntrySize(baseSize float64) float64 {
+	if s.SizeFraction > 0 {
+		return baseSize * s.SizeFraction
+	}
+	return baseSize
 }
 
 // Position represents a trading position
@@ -34,6 +144,66 @@ type Position struct {
 	ExitReason    string  `json:"exitReason"`
 	MaxDrawdown   float64 `json:"maxDrawdown"`
 	MaxProfit     float64 `json:"maxProfit"`
+	StopPrice     float64 `json:"stopPrice,omitempty"`
+	TargetPrice   float64 `json:"targetPrice,omitempty"`
+	Tag           string  `json:"tag,omitempty"`
+	Symbol        string  `json:"symbol,omitempty"`
+	// Fees paid and funding received, negative when paid, by a live
+	// position. Open positions carry what their remaining size has not yet
+	// passed on to closed trades.
+	Fees    float64 `json:"fees,omitempty"`
+	Funding float64 `json:"funding,omitempty"`
+	// FundingTime is when funding was last accrued, in unix milliseconds
+	FundingTime int64 `json:"fundingTime,omitempty"`
+}
+
+// AttachExits copies the signal's stop and target onto the position.
+// Zero values leave the existing levels untouched.
+func (p *Position) AttachExits(signal Signal) {
+	if signal.StopPrice > 0 {
+		p.StopPrice = signal.StopPrice
+	}
+	if signal.TargetPrice > 0 {
+		p.TargetPrice = signal.TargetPrice
+	}
+	if signal.Tag != "" && p.Tag == "" {
+		p.Tag = signal.Tag
+	}
+}
+
+// CheckAttachedExits checks the bar range [low, high] opened at open against
+// the position's stop and target. The stop is checked first so that a bar
+// touching both is treated conservatively. Gaps through a level fill at open.
+func (p *Position) CheckAttachedExits(open, high, low float64) (price float64, reason string, hit bool) {
+	if !p.IsOpen {
+		return 0, "", false
+	}
+	if p.Side == "long" {
+		if p.StopPrice > 0 && low <= p.StopPrice {
+			return math.Min(open, p.StopPrice), "Stop Price", true
+		}
+		if p.TargetPrice > 0 && high >= p.TargetPrice {
+			return math.Max(open, p.TargetPrice), "Target Price", true
+		}
+	} else {
+		if p.StopPrice > 0 && high >= p.StopPrice {
+			return math.Max(open, p.StopPrice), "Stop Price", true
+		}
+		if p.TargetPrice > 0 && low <= p.TargetPrice {
+			return math.Min(open, p.TargetPrice), "Target Price", true
+		}
+	}
+	return 0, "", false
+}
+
+// AddSize increases the position, averaging the entry price
+func (p *Position) AddSize(size float64, price float64) {
+	total := p.Size + size
+	if total <= 0 {
+		return
+	}
+	p.EntryPrice = (p.EntryPrice*p.Size + price*size) / total
+	p.Size = total
 }
 
 // ActivePosition represents an open position from the exchange
@@ -68,4 +238,5 @@ type BalanceInfo struct {
 type OrderResponse struct {
 	Success bool
 	Message string
+	OrderID int64
 }
@@ -0,0 +1,248 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"terminal/internal/data"
+	"terminal/internal/events"
+)
+
+// Kind is the type of a journal entry
+type Kind string
+
+// Entry kinds
+const (
+	KindSignal Kind = "signal"
+	KindOrder  Kind = "order"
+	KindFill   Kind = "fill"
+	KindOpen   Kind = "open"
+	KindUpdate Kind = "update"
+	KindExit   Kind = "exit"
+	KindHalt   Kind = "halt"
+)
+
+// Market is the last closed candle of a symbol when an entry was recorded
+type Market struct {
+	CandleTime int64   `json:"candleTime"`
+	Open       float64 `json:"open"`
+	High       float64 `json:"high"`
+	Low        float64 `json:"low"`
+	Close      float64 `json:"close"`
+	Volume     float64 `json:"volume"`
+}
+
+// Entry is one line of the trade journal
+type Entry struct {
+	Time     int64   `json:"time"`
+	Strategy string  `json:"strategy"`
+	Kind     Kind    `json:"kind"`
+	Symbol   string  `json:"symbol"`
+	Side     string  `json:"side,omitempty"`
+	Size     float64 `json:"size,omitempty"`
+	Price    float64 `json:"price,omitempty"`
+	Reduce   bool    `json:"reduce,omitempty"`
+	Reason   string  `json:"reason,omitempty"`
+	// Fee is the fee paid by fill entries when it is known
+	Fee float64 `json:"fee,omitempty"`
+	// Signal is the signal type of signal entries
+	Signal string `json:"signal,omitempty"`
+	// EntryPrice and PnL describe the position for open, update and exit
+	// entries; PnL includes earlier partial exits
+	EntryPrice float64 `json:"entryPrice,omitempty"`
+	PnL        float64 `json:"pnl,omitempty"`
+	Market     *Market `json:"market,omitempty"`
+}
+
+// Filter selects journal entries. Zero fields match everything.
+type Filter struct {
+	Strategy string `json:"strategy"`
+	Symbol   string `json:"symbol"`
+	Kinds    []Kind `json:"kinds"`
+	// From and To bound the entry time in unix milliseconds, inclusive
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// Limit keeps only the most recent matching entries
+	Limit int `json:"limit"`
+}
+
+// Match reports whether e passes the filter
+func (f Filter) Match(e Entry) bool {
+	if f.Strategy != "" && e.Strategy != f.Strategy {
+		return false
+	}
+	if f.Symbol != "" && e.Symbol != f.Symbol {
+		return false
+	}
+	if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, e.Kind) {
+		return false
+	}
+	if f.From > 0 && e.Time < f.From {
+		return false
+	}
+	if f.To > 0 && e.Time > f.To {
+		return false
+	}
+	return true
+}
+
+// Journal is an append-only JSON lines file of trading activity
+type Journal struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+	// markets holds the last closed candle per strategy and symbol
+	markets map[string]Market
+}
+
+// Open opens or creates the journal at path
+func Open(path string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+	return &Journal{path: path, file: file, markets: map[string]Market{}}, nil
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// Append writes an entry
+func (j *Journal) Append(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.file.Write(append(line, '\n'))
+	return err
+}
+
+// Record journals a live engine event. Candle events are not written but
+// provide the market context of later entries.
+func (j *Journal) Record(e events.Event) error {
+	entry := Entry{Time: e.Time, Strategy: e.Strategy}
+
+	switch d := e.Data.(type) {
+	case events.CandleClosed:
+		j.mu.Lock()
+		j.markets[marketKey(e.Strategy, d.Symbol)] = Market{
+			CandleTime: d.Candle.Time,
+			Open:       data.ParseFloat(d.Candle.Open),
+			High:       data.ParseFloat(d.Candle.High),
+			Low:        data.ParseFloat(d.Candle.Low),
+			Close:      data.ParseFloat(d.Candle.Close),
+			Volume:     data.ParseFloat(d.Candle.Volume),
+		}
+		j.mu.Unlock()
+		return nil
+	case events.SignalGenerated:
+		entry.Kind = KindSignal
+		entry.Symbol = d.Symbol
+		entry.Side = d.Signal.Type.Side()
+		entry.Price = d.Signal.Price
+		entry.Reason = d.Signal.Reason
+		entry.Signal = d.Signal.Type.String()
+	case events.OrderSubmitted:
+		entry.Kind = KindOrder
+		entry.Symbol = d.Symbol
+		entry.Side = d.Side
+		entry.Size = d.Size
+		entry.Price = d.Price
+		entry.Reduce = d.Reduce
+		entry.Reason = d.Reason
+	case events.OrderFilled:
+		entry.Kind = KindFill
+		entry.Symbol = d.Symbol
+		entry.Side = d.Side
+		entry.Size = d.Size
+		entry.Price = d.Price
+		entry.Reduce = d.Reduce
+		entry.Fee = d.Fee
+	case events.PositionOpened:
+		entry.Kind = KindOpen
+		entry.Symbol = d.Position.Symbol
+		entry.Side = d.Position.Side
+		entry.Size = d.Position.Size
+		entry.Price = d.Position.EntryPrice
+		entry.EntryPrice = d.Position.EntryPrice
+		entry.Reason = d.Position.Tag
+	case events.PositionUpdated:
+		entry.Kind = KindUpdate
+		entry.Symbol = d.Position.Symbol
+		entry.Side = d.Position.Side
+		entry.Size = d.Position.Size
+		entry.EntryPrice = d.Position.EntryPrice
+		entry.PnL = d.Position.PnL
+	case events.PositionClosed:
+		entry.Kind = KindExit
+		entry.Symbol = d.Position.Symbol
+		entry.Side = d.Position.Side
+		entry.Size = d.Position.Size
+		entry.Price = d.Position.ExitPrice
+		entry.EntryPrice = d.Position.EntryPrice
+		entry.PnL = d.Position.PnL
+		entry.Reason = d.Position.ExitReason
+	case events.TradingHalted:
+		entry.Kind = KindHalt
+		entry.Reason = d.Reason
+	default:
+		return nil
+	}
+
+	j.mu.Lock()
+	if market, ok := j.markets[marketKey(e.Strategy, entry.Symbol)]; ok {
+		entry.Market = &market
+	}
+	j.mu.Unlock()
+	return j.Append(entry)
+}
+
+// Query reads the entries matching f in the order they were recorded
+func (j *Journal) Query(f Filter) ([]Entry, error) {
+	file, err := os.Open(j.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// A torn final line from a crash must not hide the rest
+			continue
+		}
+		if f.Match(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if f.Limit > 0 && len(entries) > f.Limit {
+		entries = entries[len(entries)-f.Limit:]
+	}
+	return entries, nil
+}
+
+func marketKey(strategy, symbol string) string {
+	return strategy + "\x00" + symbol
+}
@@ -0,0 +1,95 @@
+package journal
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"terminal/internal/events"
+	"terminal/internal/exchange"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+func kinds(entries []Entry) []Kind {
+	result := []Kind{}
+	for _, e := range entries {
+		result = append(result, e.Kind)
+	}
+	return result
+}
+
+func TestRecordsBusEvents(t *testing.T) {
+	j, err := Open(filepath.Join(t.TempDir(), "journal.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	bus := events.NewBus()
+	stop := bus.HandleAll(1, func(e events.Event) {
+		if err := j.Record(e); err != nil {
+			t.Error(err)
+		}
+	})
+
+	bus.Publish("trend", events.CandleClosed{Symbol: "BTC", Interval: "1h",
+		Candle: hyperliquid.Candle{Time: 1000, Open: "99", High: "102", Low: "98", Close: "101", Volume: "5"}})
+	bus.Publish("trend", events.SignalGenerated{Symbol: "BTC",
+		Signal: exchange.Signal{Type: exchange.SignalLong, Price: 101, Reason: "cross"}})
+	bus.Publish("trend", events.OrderSubmitted{Symbol: "BTC", Side: "buy", Size: 0.1, Price: 101})
+	bus.Publish("trend", events.OrderFilled{Symbol: "BTC", Side: "buy", Size: 0.1, Price: 101, Fee: 0.01})
+	bus.Publish("trend", events.PositionOpened{Position: exchange.Position{Symbol: "BTC", Side: "long", Size: 0.1, EntryPrice: 101}})
+	bus.Publish("revert", events.OrderSubmitted{Symbol: "ETH", Side: "sell", Size: 1, Price: 3000})
+	bus.Publish("revert", events.OrderFilled{Symbol: "ETH", Side: "sell", Size: 1, Price: 3000})
+
+	// Publish stamps events with the wall clock, so leave a gap to split on
+	time.Sleep(5 * time.Millisecond)
+	cut := time.Now().UnixMilli()
+	time.Sleep(5 * time.Millisecond)
+	bus.Publish("trend", events.PositionClosed{Position: exchange.Position{
+		Symbol: "BTC", Side: "long", Size: 0.1, EntryPrice: 101, ExitPrice: 105, PnL: 0.4, ExitReason: "target"}})
+	stop()
+
+	all, err := j.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 7 {
+		t.Fatalf("journal holds %d entries, want the 7 non-candle events", len(all))
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []Kind
+	}{
+		{"strategy", Filter{Strategy: "trend"}, []Kind{KindSignal, KindOrder, KindFill, KindOpen, KindExit}},
+		{"kind", Filter{Kinds: []Kind{KindOrder, KindFill}}, []Kind{KindOrder, KindFill, KindOrder, KindFill}},
+		{"strategy and kind", Filter{Strategy: "revert", Kinds: []Kind{KindFill}}, []Kind{KindFill}},
+		{"from", Filter{From: cut}, []Kind{KindExit}},
+		{"to", Filter{Strategy: "trend", To: cut}, []Kind{KindSignal, KindOrder, KindFill, KindOpen}},
+		{"limit", Filter{Strategy: "trend", Limit: 2}, []Kind{KindOpen, KindExit}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := j.Query(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := kinds(entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("kinds %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	signal := all[0]
+	want := Market{CandleTime: 1000, Open: 99, High: 102, Low: 98, Close: 101, Volume: 5}
+	if signal.Signal != exchange.SignalLong.String() || signal.Market == nil || *signal.Market != want {
+		t.Errorf("signal entry %+v lacks its market context %+v", signal, want)
+	}
+	if exit := all[6]; exit.Price != 105 || exit.PnL != 0.4 || exit.Reason != "target" {
+		t.Errorf("exit entry %+v", exit)
+	}
+}
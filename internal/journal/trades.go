@@ -0,0 +1,82 @@
+package journal
+
+import "terminal/internal/exchange"
+
+// Trades rebuilds the trades of one strategy and symbol from its journal
+// entries, oldest first. Like in a backtest every full or partial close is
+// its own trade, priced at the fill of its closing order. Each trade takes
+// its share by size of the fees of the fills that opened the position, plus
+// the fee of its close. A position still open after the last entry is
+// returned last with IsOpen set.
+func Trades(entries []Entry) []exchange.Position {
+	trades := []exchange.Position{}
+	var open *exchange.Position
+	var reason string
+	// entryFee is the fee of an entry fill whose position is not open yet
+	var entryFee float64
+
+	for _, e := range entries {
+		switch e.Kind {
+		case KindOpen:
+			open = &exchange.Position{
+				Symbol:     e.Symbol,
+				Side:       e.Side,
+				Size:       e.Size,
+				EntryPrice: e.EntryPrice,
+				EntryTime:  e.Time,
+				IsOpen:     true,
+				Fees:       entryFee,
+			}
+			entryFee = 0
+		case KindUpdate:
+			// Scale-ins move the average entry, partial exits the size
+			if open != nil {
+				open.Size = e.Size
+				open.EntryPrice = e.EntryPrice
+			}
+		case KindOrder:
+			if e.Reduce {
+				reason = e.Reason
+			}
+		case KindFill:
+			if !e.Reduce {
+				if open != nil {
+					open.Fees += e.Fee
+				} else {
+					entryFee += e.Fee
+				}
+				continue
+			}
+			if open == nil {
+				continue
+			}
+			share := 1.0
+			if e.Size < open.Size {
+				share = e.Size / open.Size
+			}
+			trade := *open
+			trade.Size = e.Size
+			trade.IsOpen = false
+			trade.ExitPrice = e.Price
+			trade.ExitTime = e.Time
+			trade.ExitReason = reason
+			trade.PnL = e.Size * (e.Price - open.EntryPrice)
+			if open.Side == "short" {
+				trade.PnL = -trade.PnL
+			}
+			if open.EntryPrice > 0 {
+				trade.PnLPercentage = trade.PnL / (e.Size * open.EntryPrice) * 100
+			}
+			trade.Fees = open.Fees*share + e.Fee
+			open.Fees -= open.Fees * share
+			trades = append(trades, trade)
+		case KindExit:
+			open = nil
+		}
+	}
+
+	if open != nil {
+		trades = append(trades, *open)
+	}
+	return trades
+}
@@ -0,0 +1,183 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Attribute keys shared by every package that logs live activity
+const (
+	KeyStrategy = "strategy"
+	KeySymbol   = "symbol"
+	KeyOrderID  = "orderId"
+)
+
+// Rotation limits for per-strategy log files
+const (
+	maxFileSize = 10 << 20
+	maxBackups  = 3
+)
+
+// Handler is a slog.Handler that writes every record to a base handler and
+// additionally appends records carrying a strategy attribute, directly or
+// through Logger.With, to a rotating JSON log file for that strategy.
+type Handler struct {
+	base  slog.Handler
+	files *fileSet
+	// strategy is set once a strategy attribute was added with WithAttrs
+	strategy string
+	// grouped is set after WithGroup, when attributes are no longer top-level
+	grouped bool
+	// derive replays WithAttrs and WithGroup calls onto file handlers
+	derive []func(slog.Handler) slog.Handler
+}
+
+// NewHandler creates a handler writing per-strategy logs into dir
+func NewHandler(base slog.Handler, dir string) *Handler {
+	return &Handler{
+		base:  base,
+		files: &fileSet{dir: dir, handlers: map[string]*fileHandler{}},
+	}
+}
+
+// Setup installs a text logger on stderr with per-strategy files in dir as
+// the slog default and returns a function closing the files
+func Setup(dir string, level slog.Level) func() error {
+	h := NewHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}), dir)
+	slog.SetDefault(slog.New(h))
+	return h.Close
+}
+
+// Enabled reports whether the base handler or the strategy files want level
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.base.Enabled(ctx, level) || level >= slog.LevelInfo
+}
+
+// Handle writes r to the base handler and its strategy's file
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	var err error
+	if h.base.Enabled(ctx, r.Level) {
+		err = h.base.Handle(ctx, r.Clone())
+	}
+
+	strategy := h.strategy
+	if strategy == "" {
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == KeyStrategy {
+				strategy = a.Value.String()
+				return false
+			}
+			return true
+		})
+	}
+	if strategy == "" || r.Level < slog.LevelInfo {
+		return err
+	}
+
+	file, fileErr := h.files.handler(strategy)
+	if fileErr != nil {
+		return fileErr
+	}
+	var fh slog.Handler = file
+	for _, derive := range h.derive {
+		fh = derive(fh)
+	}
+	if fileErr := fh.Handle(ctx, r); fileErr != nil && err == nil {
+		err = fileErr
+	}
+	return err
+}
+
+// WithAttrs returns a handler adding attrs to every record
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := h.clone()
+	next.base = h.base.WithAttrs(attrs)
+	next.derive = append(next.derive, func(fh slog.Handler) slog.Handler { return fh.WithAttrs(attrs) })
+	// Only top-level strategy attributes select a file
+	if !h.grouped {
+		for _, a := range attrs {
+			if a.Key == KeyStrategy {
+				next.strategy = a.Value.String()
+			}
+		}
+	}
+	return next
+}
+
+// WithGroup returns a handler nesting later attributes under name
+func (h *Handler) WithGroup(name string) slog.Handler {
+	next := h.clone()
+	next.base = h.base.WithGroup(name)
+	next.grouped = true
+	next.derive = append(next.derive, func(fh slog.Handler) slog.Handler { return fh.WithGroup(name) })
+	return next
+}
+
+// Close closes every strategy log file
+func (h *Handler) Close() error {
+	return h.files.close()
+}
+
+func (h *Handler) clone() *Handler {
+	next := *h
+	next.derive = append([]func(slog.Handler) slog.Handler(nil), h.derive...)
+	return &next
+}
+
+// fileSet opens one log file per strategy on first use
+type fileSet struct {
+	dir      string
+	mu       sync.Mutex
+	handlers map[string]*fileHandler
+}
+
+type fileHandler struct {
+	slog.Handler
+	file *RotatingFile
+}
+
+func (s *fileSet) handler(strategy string) (slog.Handler, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fh, ok := s.handlers[strategy]; ok {
+		return fh.Handler, nil
+	}
+	file, err := OpenRotating(filepath.Join(s.dir, fileName(strategy)), maxFileSize, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+	fh := &fileHandler{Handler: slog.NewJSONHandler(file, nil), file: file}
+	s.handlers[strategy] = fh
+	return fh.Handler, nil
+}
+
+func (s *fileSet) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var first error
+	for id, fh := range s.handlers {
+		if err := fh.file.Close(); err != nil && first == nil {
+			first = err
+		}
+		delete(s.handlers, id)
+	}
+	return first
+}
+
+// fileName turns a strategy id into a safe log file name
+func fileName(strategy string) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, strategy)
+	return name + ".log"
+}
@@ -0,0 +1,244 @@
+package position
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"terminal/internal/events"
+	"terminal/internal/exchange"
+	"terminal/internal/logging"
+)
+
+// LiveBasket represents a live multi-leg trading context
+// Each leg is keyed by its symbol
+type LiveBasket interface {
+	GetID() string
+	GetConfig() ExecutionConfig
+	GetLeg(symbol string) *exchange.Position
+	SetLeg(symbol string, pos *exchange.Position)
+}
+
+// openedLeg records an order placed while executing a leg group so it can
+// be rolled back
+type openedLeg struct {
+	symbol   string
+	side     string
+	size     float64
+	price    float64
+	previous *exchange.Position
+}
+
+// ExecuteLegs executes a group of per-symbol signals as one multi-leg order.
+// Exits are processed first. Entries and scale-ins are then placed in order;
+// if any leg fails, the legs already placed in this group are closed again
+// and an error is returned. Reversal closes that happened before the failure
+// are not undone.
+func (m *Manager) ExecuteLegs(live LiveBasket, signals []exchange.Signal, prices map[string]float64) error {
+	config := live.GetConfig()
+	var errs []error
+
+	// Exits first so reversals free margin before new legs open
+	for _, signal := range signals {
+		if signal.Type.IsEntry() || signal.Type == exchange.SignalScaleIn {
+			continue
+		}
+		pos := live.GetLeg(signal.Symbol)
+		if pos == nil || !pos.IsOpen || !signal.Type.Closes(pos.Side) {
+			continue
+		}
+		reason := signal.Reason
+		if reason == "" {
+			reason = signal.Type.String()
+		}
+		if err := m.closeLeg(live, signal.Symbol, pos.Size*signal.ExitFraction(), prices[signal.Symbol], reason); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Skip the whole entry group if direction filtering would break the hedge
+	for _, signal := range signals {
+		side := signal.Type.Side()
+		if (config.TradeDirection == "long" && side == "short") ||
+			(config.TradeDirection == "short" && side == "long") {
+			slog.Info("leg group filtered", logging.KeyStrategy, live.GetID(), logging.KeySymbol, signal.Symbol,
+				"side", side, "tradeDirection", config.TradeDirection)
+			return errors.Join(errs...)
+		}
+	}
+
+	var opened []openedLeg
+	for _, signal := range signals {
+		if !signal.Type.IsEntry() && signal.Type != exchange.SignalScaleIn {
+			continue
+		}
+
+		symbol := signal.Symbol
+		price := prices[symbol]
+		pos := live.GetLeg(symbol)
+		size := signal.EntrySize(config.PositionSize)
+
+		side := signal.Type.Side()
+		if signal.Type == exchange.SignalScaleIn {
+			if pos == nil || !pos.IsOpen {
+				continue
+			}
+			side = pos.Side
+		} else if pos != nil && pos.IsOpen {
+			if pos.Side == side {
+				continue
+			}
+			if err := m.closeLeg(live, symbol, pos.Size, price, "Leg Reversal"); err != nil {
+				errs = append(errs, err)
+				return m.rollbackLegs(live, opened, errors.Join(errs...))
+			}
+		}
+
+		newPos, f, err := m.openOrder(live.GetID(), symbol, side, size, price)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("leg %s: %w", symbol, err))
+			return m.rollbackLegs(live, opened, errors.Join(errs...))
+		}
+
+		opened = append(opened, openedLeg{
+			symbol: symbol, side: side, size: size, price: price, previous: copyPosition(pos),
+		})
+		if signal.Type == exchange.SignalScaleIn {
+			pos.AddSize(size, f.price)
+			pos.Fees += f.fee
+			pos.AttachExits(signal)
+			m.publishPosition(live.GetID(), pos, events.KindPositionUpdated)
+			continue
+		}
+		newPos.Symbol = symbol
+		newPos.EntryPrice = f.price
+		newPos.Fees = f.fee
+		newPos.EntryTime = m.now()
+		newPos.AttachExits(signal)
+		live.SetLeg(symbol, newPos)
+		m.publishPosition(live.GetID(), newPos, events.KindPositionOpened)
+	}
+
+	if len(opened) > 0 {
+		slog.Info("leg group executed", logging.KeyStrategy, live.GetID(), "legs", len(opened))
+	}
+	return errors.Join(errs...)
+}
+
+// CloseLegs closes every open leg of a basket
+func (m *Manager) CloseLegs(live LiveBasket, symbols []string, prices map[string]float64, reason string) error {
+	var errs []error
+	for _, symbol := range symbols {
+		pos := live.GetLeg(symbol)
+		if pos == nil || !pos.IsOpen {
+			continue
+		}
+		if err := m.closeLeg(live, symbol, pos.Size, prices[symbol], reason); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CheckLegExits closes a leg when the range of a bar that opened after its
+// entry reaches a stop or target attached by the strategy, like CheckExits
+func (m *Manager) CheckLegExits(live LiveBasket, symbol string, open, high, low float64) error {
+	pos := live.GetLeg(symbol)
+	if pos == nil || !pos.IsOpen {
+		return nil
+	}
+	if exitPrice, reason, hit := pos.CheckAttachedExits(open, high, low); hit {
+		return m.closeLeg(live, symbol, pos.Size, exitPrice, reason)
+	}
+	return nil
+}
+
+// CheckLegTPSL checks the stops and targets of a leg at the current price,
+// like CheckTPSL
+func (m *Manager) CheckLegTPSL(live LiveBasket, symbol string, currentPrice float64) error {
+	m.Observe(symbol, currentPrice)
+	pos := live.GetLeg(symbol)
+	if pos == nil || !pos.IsOpen {
+		return nil
+	}
+
+	// Stops and targets attached by the strategy take precedence
+	if exitPrice, reason, hit := pos.CheckAttachedExits(currentPrice, currentPrice, currentPrice); hit {
+		return m.closeLeg(live, symbol, pos.Size, exitPrice, reason)
+	}
+	if reason, hit := percentExit(live.GetConfig(), pos, currentPrice); hit {
+		return m.closeLeg(live, symbol, pos.Size, currentPrice, reason)
+	}
+	return nil
+}
+
+// closeLeg closes size units of a leg, fully closing it when size covers it
+func (m *Manager) closeLeg(live LiveBasket, symbol string, size float64, price float64, reason string) error {
+	pos := live.GetLeg(symbol)
+	if pos == nil || !pos.IsOpen {
+		return nil
+	}
+	if size > pos.Size {
+		size = pos.Size
+	}
+
+	f, err := m.closeOrder(live.GetID(), symbol, pos.Side, size, price, reason)
+	if err != nil {
+		return fmt.Errorf("close leg %s: %w", symbol, err)
+	}
+
+	trade := m.recordTrade(live, pos, size, f, reason)
+	pos.PnL += trade.PnL
+	if size < pos.Size {
+		pos.Size -= size
+		m.publishPosition(live.GetID(), pos, events.KindPositionUpdated)
+		return nil
+	}
+
+	pos.IsOpen = false
+	pos.ExitPrice = f.price
+	pos.ExitReason = reason
+	pos.ExitTime = m.now()
+	pos.Fees, pos.Funding = trade.Fees, trade.Funding
+	m.publishPosition(live.GetID(), pos, events.KindPositionClosed)
+	return nil
+}
+
+// rollbackLegs closes the legs placed so far in a failed group
+func (m *Manager) rollbackLegs(live LiveBasket, opened []openedLeg, cause error) error {
+	var errs []error
+	for i := len(opened) - 1; i >= 0; i-- {
+		leg := opened[i]
+		if _, err := m.closeOrder(live.GetID(), leg.symbol, leg.side, leg.size, leg.price, "Rollback"); err != nil {
+			errs = append(errs, fmt.Errorf("rollback leg %s: %w", leg.symbol, err))
+			continue
+		}
+
+		if leg.previous != nil {
+			live.SetLeg(leg.symbol, leg.previous)
+			m.publishPosition(live.GetID(), leg.previous, events.KindPositionUpdated)
+			continue
+		}
+		if pos := live.GetLeg(leg.symbol); pos != nil {
+			pos.IsOpen = false
+			pos.ExitPrice = leg.price
+			pos.ExitReason = "Rollback"
+			pos.ExitTime = m.now()
+			m.publishPosition(live.GetID(), pos, events.KindPositionClosed)
+		}
+		live.SetLeg(leg.symbol, nil)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("leg group failed: %w; rollback incomplete: %w", cause, errors.Join(errs...))
+	}
+	return fmt.Errorf("leg group failed and was rolled back: %w", cause)
+}
+
+// copyPosition returns a snapshot of pos so a scale-in can be undone
+func copyPosition(pos *exchange.Position) *exchange.Position {
+	if pos == nil || !pos.IsOpen {
+		return nil
+	}
+	cp := *pos
+	return &cp
+}
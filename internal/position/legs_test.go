@@ -0,0 +1,166 @@
+package position
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"terminal/internal/exchange"
+)
+
+// rejecting is a mock exchange that refuses orders opening reject and keeps
+// the open size of every other symbol
+type rejecting struct {
+	*exchange.MockAdapter
+	reject string
+	sizes  map[string]float64
+}
+
+func newRejecting(reject string) *rejecting {
+	return &rejecting{MockAdapter: exchange.NewMockAdapter(10_000), reject: reject, sizes: map[string]float64{}}
+}
+
+func (r *rejecting) OpenPosition(symbol string, side string, size float64, leverage int) (*exchange.Position, error) {
+	if symbol == r.reject {
+		return nil, errRejected
+	}
+	r.sizes[symbol] += size
+	return r.MockAdapter.OpenPosition(symbol, side, size, leverage)
+}
+
+func (r *rejecting) ClosePositionFill(symbol string, size float64) (float64, error) {
+	r.sizes[symbol] -= size
+	if size == 0 || r.sizes[symbol] < 1e-9 {
+		delete(r.sizes, symbol)
+	}
+	return r.MockAdapter.ClosePositionFill(symbol, size)
+}
+
+var errRejected = errors.New("order rejected")
+
+// basket is a minimal LiveBasket
+type basket struct {
+	config ExecutionConfig
+	legs   map[string]*exchange.Position
+	trades []exchange.Position
+}
+
+func newBasket(direction string) *basket {
+	return &basket{
+		config: ExecutionConfig{PositionSize: 1, TradeDirection: direction},
+		legs:   map[string]*exchange.Position{},
+	}
+}
+
+func (b *basket) GetID() string                                { return "test-basket" }
+func (b *basket) GetConfig() ExecutionConfig                   { return b.config }
+func (b *basket) GetLeg(symbol string) *exchange.Position      { return b.legs[symbol] }
+func (b *basket) SetLeg(symbol string, pos *exchange.Position) { b.legs[symbol] = pos }
+func (b *basket) RecordTrade(trade exchange.Position)          { b.trades = append(b.trades, trade) }
+
+var prices = map[string]float64{"BTC": 100, "ETH": 50, "SOL": 10}
+
+func entry(symbol string, t exchange.SignalType) exchange.Signal {
+	return exchange.Signal{Symbol: symbol, Type: t}
+}
+
+func TestExecuteLegsRollsBackFailedGroup(t *testing.T) {
+	adapter := newRejecting("SOL")
+	m := NewManager(adapter)
+	live := newBasket("both")
+
+	group := []exchange.Signal{
+		entry("BTC", exchange.SignalLong),
+		entry("ETH", exchange.SignalShort),
+		entry("SOL", exchange.SignalLong),
+	}
+	if err := m.ExecuteLegs(live, group, prices); err == nil {
+		t.Fatal("group with a rejected leg succeeded")
+	}
+	for _, symbol := range []string{"BTC", "ETH", "SOL"} {
+		if leg := live.GetLeg(symbol); leg != nil {
+			t.Errorf("leg %s left as %+v after rollback", symbol, leg)
+		}
+	}
+	if len(adapter.sizes) != 0 {
+		t.Errorf("exchange still holds %v after rollback", adapter.sizes)
+	}
+}
+
+func TestExecuteLegsRollbackRestoresScaleIn(t *testing.T) {
+	adapter := newRejecting("SOL")
+	m := NewManager(adapter)
+	live := newBasket("both")
+	if err := m.ExecuteLegs(live, []exchange.Signal{entry("BTC", exchange.SignalLong)}, prices); err != nil {
+		t.Fatal(err)
+	}
+
+	group := []exchange.Signal{
+		{Symbol: "BTC", Type: exchange.SignalScaleIn, SizeFraction: 0.5},
+		entry("SOL", exchange.SignalLong),
+	}
+	if err := m.ExecuteLegs(live, group, prices); err == nil {
+		t.Fatal("group with a rejected leg succeeded")
+	}
+	leg := live.GetLeg("BTC")
+	if leg == nil || !leg.IsOpen || leg.Size != 1 {
+		t.Fatalf("BTC leg %+v after rollback, want the open leg of size 1", leg)
+	}
+	if math.Abs(adapter.sizes["BTC"]-1) > 1e-9 || len(adapter.sizes) != 1 {
+		t.Errorf("exchange holds %v after rollback, want BTC of size 1", adapter.sizes)
+	}
+}
+
+func TestExecuteLegsFiltersWholeGroup(t *testing.T) {
+	adapter := newRejecting("")
+	m := NewManager(adapter)
+	live := newBasket("long")
+	if err := m.ExecuteLegs(live, []exchange.Signal{entry("SOL", exchange.SignalLong)}, prices); err != nil {
+		t.Fatal(err)
+	}
+
+	// The hedge is skipped as a whole, but its exits still apply
+	group := []exchange.Signal{
+		entry("SOL", exchange.SignalExitLong),
+		entry("BTC", exchange.SignalLong),
+		entry("ETH", exchange.SignalShort),
+	}
+	if err := m.ExecuteLegs(live, group, prices); err != nil {
+		t.Fatal(err)
+	}
+	if live.GetLeg("BTC") != nil || live.GetLeg("ETH") != nil {
+		t.Errorf("filtered group opened legs %+v", live.legs)
+	}
+	if leg := live.GetLeg("SOL"); leg == nil || leg.IsOpen {
+		t.Errorf("SOL leg %+v still open after its exit", leg)
+	}
+}
+
+func TestCheckLegExits(t *testing.T) {
+	m := NewManager(newRejecting(""))
+	live := newBasket("both")
+	group := []exchange.Signal{
+		{Symbol: "BTC", Type: exchange.SignalLong, StopPrice: 95},
+		{Symbol: "ETH", Type: exchange.SignalShort, TargetPrice: 45},
+	}
+	if err := m.ExecuteLegs(live, group, prices); err != nil {
+		t.Fatal(err)
+	}
+
+	// BTC gaps through its stop and fills at the open
+	if err := m.CheckLegExits(live, "BTC", 90, 92, 88); err != nil {
+		t.Fatal(err)
+	}
+	if leg := live.GetLeg("BTC"); leg.IsOpen || leg.ExitPrice != 90 || leg.ExitReason != "Stop Price" {
+		t.Errorf("BTC leg %+v, want stopped at the open of 90", leg)
+	}
+	if err := m.CheckLegTPSL(live, "ETH", 44); err != nil {
+		t.Fatal(err)
+	}
+	if leg := live.GetLeg("ETH"); leg.IsOpen || leg.ExitReason != "Target Price" {
+		t.Errorf("ETH leg %+v, want closed at its target", leg)
+	}
+	if len(live.trades) != 2 {
+		t.Errorf("recorded %d trades, want 2", len(live.trades))
+	}
+}
@@ -1,10 +1,17 @@
 package position
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"sync"
 	"time"
 
+	"terminal/internal/clock"
+	"terminal/internal/events"
 	"terminal/internal/exchange"
+	"terminal/internal/logging"
 )
 
 // ExecutionConfig contains runtime configuration for position management
@@ -15,6 +22,23 @@ type ExecutionConfig struct {
 	StopLossPercent   float64
 }
 
+// Validate reports every invalid setting of the config
+func (c ExecutionConfig) Validate() error {
+	var errs []error
+	if !(c.PositionSize > 0) || math.IsInf(c.PositionSize, 1) {
+		errs = append(errs, fmt.Errorf("position size must be positive, got %v", c.PositionSize))
+	}
+	switch c.TradeDirection {
+	case "long", "short", "both":
+	default:
+		errs = append(errs, fmt.Errorf("unknown trade direction %q: use long, short or both", c.TradeDirection))
+	}
+	if !(c.TakeProfitPercent >= 0) || !(c.StopLossPercent >= 0) {
+		errs = append(errs, fmt.Errorf("take profit and stop loss percent must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
 // LivePosition represents a live trading position context
 // This interface allows the position manager to work without importing engine
 type LivePosition interface {
@@ -25,11 +49,30 @@ type LivePosition interface {
 	SetPosition(pos *exchange.Position)
 }
 
+// TradeRecorder is implemented by live contexts that keep their closed
+// trades. Like in a backtest, every full or partial close is its own trade.
+type TradeRecorder interface {
+	RecordTrade(trade exchange.Position)
+}
+
 // Manager handles all position operations
 // This decouples position management from strategy logic
 type Manager struct {
 	exchange exchange.Adapter
 	leverage int
+	events   *events.Bus
+	clock    clock.Clock
+
+	// feeRate caches the account's fee rate once the exchange reported it
+	feeMu    sync.Mutex
+	feeRate  float64
+	feeKnown bool
+}
+
+// fill is the outcome of an order: the price it filled at and the fee paid
+type fill struct {
+	price float64
+	fee   float64
 }
 
 // NewManager creates a new position manager
@@ -37,6 +80,7 @@ func NewManager(exchg exchange.Adapter) *Manager {
 	return &Manager{
 		exchange: exchg,
 		leverage: 10, // Default leverage
+		clock:    clock.Real,
 	}
 }
 
@@ -45,29 +89,156 @@ func (m *Manager) SetLeverage(leverage int) {
 	m.leverage = leverage
 }
 
-// HandleSignal processes a trading signal for a live strategy
-func (m *Manager) HandleSignal(live LivePosition, signal exchange.Signal, price float64) {
-	config := live.GetConfig()
+// SetClock sets the clock positions are timestamped with
+func (m *Manager) SetClock(clk clock.Clock) {
+	m.clock = clk
+}
 
-	fmt.Printf("[%s] Signal Received: Type=%d at %.2f - %s\n", live.GetID(), signal.Type, price, signal.Reason)
+// now returns the current time in unix milliseconds
+func (m *Manager) now() int64 {
+	return m.clock.Now().UnixMilli()
+}
 
-	if signal.Type != exchange.SignalLong && signal.Type != exchange.SignalShort {
-		fmt.Printf("[%s] Invalid signal type: %d\n", live.GetID(), signal.Type)
-		return
+// SetEvents publishes orders and position changes to bus
+func (m *Manager) SetEvents(bus *events.Bus) {
+	m.events = bus
+}
+
+// Observe passes the latest price of symbol to adapters that track prices
+func (m *Manager) Observe(symbol string, price float64) {
+	if observer, ok := m.exchange.(exchange.PriceObserver); ok {
+		observer.ObservePrice(symbol, price)
+	}
+}
+
+// Quote returns the current mid price of symbol from the exchange
+func (m *Manager) Quote(symbol string) (float64, error) {
+	quoter, ok := m.exchange.(exchange.PriceQuoter)
+	if !ok {
+		return 0, fmt.Errorf("no price quotes for %s", symbol)
+	}
+	return quoter.MidPrice(symbol)
+}
+
+// fee returns the fee for filling size units at price, at the rate the
+// exchange reports for the account, or 0 when it reports none
+func (m *Manager) fee(size, price float64) float64 {
+	m.feeMu.Lock()
+	defer m.feeMu.Unlock()
+	if !m.feeKnown {
+		reporter, ok := m.exchange.(exchange.FeeReporter)
+		if !ok {
+			return 0
+		}
+		rate, err := reporter.TakerFeeRate()
+		if err != nil {
+			slog.Warn("failed to fetch fee rate", "error", err)
+			return 0
+		}
+		m.feeRate, m.feeKnown = rate, true
+	}
+	return size * price * m.feeRate
+}
+
+// openOrder submits an order opening or adding to a position, reports it
+// and returns its fill: the exchange's fill price when it reports one,
+// price otherwise
+func (m *Manager) openOrder(id, symbol, side string, size, price float64) (*exchange.Position, fill, error) {
+	m.Observe(symbol, price)
+	m.events.Publish(id, events.OrderSubmitted{Symbol: symbol, Side: side, Size: size, Price: price})
+	pos, err := m.exchange.OpenPosition(symbol, side, size, m.leverage)
+	if err != nil {
+		return nil, fill{}, err
+	}
+	f := fill{price: pos.EntryPrice}
+	if f.price <= 0 {
+		f.price = price
 	}
+	f.fee = m.fee(size, f.price)
+	m.events.Publish(id, events.OrderFilled{Symbol: symbol, Side: side, Size: size, Price: f.price, Fee: f.fee})
+	return pos, f, nil
+}
+
+// closeOrder submits an order closing size units of a position, reports it
+// and returns its fill: the exchange's fill price when it reports one,
+// price otherwise
+func (m *Manager) closeOrder(id, symbol, side string, size, price float64, reason string) (fill, error) {
+	m.Observe(symbol, price)
+	m.events.Publish(id, events.OrderSubmitted{
+		Symbol: symbol, Side: side, Size: size, Price: price, Reduce: true, Reason: reason,
+	})
+	var f fill
+	var err error
+	if reporter, ok := m.exchange.(exchange.FillReporter); ok {
+		f.price, err = reporter.ClosePositionFill(symbol, size)
+	} else {
+		err = m.exchange.ClosePosition(symbol, size)
+	}
+	if err != nil {
+		return fill{}, err
+	}
+	if f.price <= 0 {
+		f.price = price
+	}
+	f.fee = m.fee(size, f.price)
+	m.events.Publish(id, events.OrderFilled{
+		Symbol: symbol, Side: side, Size: size, Price: f.price, Fee: f.fee, Reduce: true,
+	})
+	return f, nil
+}
 
-	side := "long"
-	if signal.Type == exchange.SignalShort {
-		side = "short"
+// publishPosition reports a copy of pos, so subscribers never share state
+// with the manager
+func (m *Manager) publishPosition(id string, pos *exchange.Position, kind events.Kind) {
+	switch kind {
+	case events.KindPositionOpened:
+		m.events.Publish(id, events.PositionOpened{Position: *pos})
+	case events.KindPositionClosed:
+		m.events.Publish(id, events.PositionClosed{Position: *pos})
+	default:
+		m.events.Publish(id, events.PositionUpdated{Position: *pos})
 	}
+}
+
+// logger returns the logger for a live strategy's activity
+func logger(live LivePosition) *slog.Logger {
+	return slog.With(logging.KeyStrategy, live.GetID(), logging.KeySymbol, live.GetSymbol())
+}
+
+// publishError reports a failed operation
+func (m *Manager) publishError(id string, format string, args ...any) {
+	m.events.Publish(id, events.StrategyError{Message: fmt.Sprintf(format, args...)})
+}
+
+// HandleSignal processes a trading signal for a live strategy
+func (m *Manager) HandleSignal(live LivePosition, signal exchange.Signal, price float64) {
+	log := logger(live)
+	log.Info("signal received", "type", signal.Type.String(), "price", price, "reason", signal.Reason)
+
+	switch signal.Type {
+	case exchange.SignalLong, exchange.SignalShort:
+		m.openOnSignal(live, signal, price)
+	case exchange.SignalScaleIn:
+		m.scaleIn(live, signal, price)
+	case exchange.SignalExitLong, exchange.SignalExitShort, exchange.SignalFlatten, exchange.SignalPartialExit:
+		m.exitOnSignal(live, signal, price)
+	default:
+		log.Warn("invalid signal type", "type", int(signal.Type))
+	}
+}
+
+// openOnSignal opens a position for an entry signal, reversing if needed
+func (m *Manager) openOnSignal(live LivePosition, signal exchange.Signal, price float64) {
+	config := live.GetConfig()
+	side := signal.Type.Side()
 
 	// Filter by trade direction
 	if config.TradeDirection == "long" && side == "short" {
-		fmt.Printf("[%s] Signal filtered: SHORT signal ignored (trade direction: long only)\n", live.GetID())
+		logger(live).Info("signal filtered", "side", side, "tradeDirection", config.TradeDirection)
 		return
 	}
 	if config.TradeDirection == "short" && side == "long" {
-		fmt.Printf("[%s] Signal filtered: LONG signal ignored (trade direction: short only)\n", live.GetID())
+		logger(live).Info("signal filtered", "side", side, "tradeDirection", config.TradeDirection)
 		return
 	}
 
@@ -75,24 +246,69 @@ func (m *Manager) HandleSignal(live LivePosition, signal exchange.Signal, price
 	pos := live.GetPosition()
 	if pos != nil && pos.IsOpen {
 		if pos.Side == side {
-			fmt.Printf("[%s] Already in %s position, ignoring signal\n", live.GetID(), side)
+			logger(live).Info("already in position, ignoring signal", "side", side)
 			return
 		}
-		fmt.Printf("[%s] Closing existing %s position before opening new %s position\n", live.GetID(), pos.Side, side)
+		logger(live).Info("reversing position", "from", pos.Side, "to", side)
 		m.ClosePosition(live, price, "Trend Reversal")
 	}
 
 	// Open new position
-	fmt.Printf("[%s] Opening %s position: size=%.4f, leverage=%dx\n", live.GetID(), side, config.PositionSize, m.leverage)
-	newPos, err := m.exchange.OpenPosition(live.GetSymbol(), side, config.PositionSize, m.leverage)
+	size := signal.EntrySize(config.PositionSize)
+	newPos, f, err := m.openOrder(live.GetID(), live.GetSymbol(), side, size, price)
 	if err != nil {
-		fmt.Printf("[%s] Failed to open position: %v\n", live.GetID(), err)
+		m.publishError(live.GetID(), "Failed to open %s position: %v", side, err)
 		return
 	}
 
-	newPos.EntryPrice = price
+	newPos.Symbol = live.GetSymbol()
+	newPos.EntryPrice = f.price
+	newPos.Fees = f.fee
+	newPos.EntryTime = m.now()
+	newPos.AttachExits(signal)
 	live.SetPosition(newPos)
-	fmt.Printf("[%s] Position opened successfully: %s %.4f @ %.2f\n", live.GetID(), side, config.PositionSize, price)
+	m.publishPosition(live.GetID(), newPos, events.KindPositionOpened)
+}
+
+// scaleIn adds to the open position on its current side
+func (m *Manager) scaleIn(live LivePosition, signal exchange.Signal, price float64) {
+	pos := live.GetPosition()
+	if pos == nil || !pos.IsOpen {
+		logger(live).Info("no open position to scale into, ignoring signal")
+		return
+	}
+
+	size := signal.EntrySize(live.GetConfig().PositionSize)
+	_, f, err := m.openOrder(live.GetID(), live.GetSymbol(), pos.Side, size, price)
+	if err != nil {
+		m.publishError(live.GetID(), "Failed to scale in: %v", err)
+		return
+	}
+
+	pos.AddSize(size, f.price)
+	pos.Fees += f.fee
+	pos.AttachExits(signal)
+	m.publishPosition(live.GetID(), pos, events.KindPositionUpdated)
+}
+
+// exitOnSignal closes all or part of the open position for an exit signal
+func (m *Manager) exitOnSignal(live LivePosition, signal exchange.Signal, price float64) {
+	pos := live.GetPosition()
+	if pos == nil || !pos.IsOpen || !signal.Type.Closes(pos.Side) {
+		logger(live).Info("no matching position, ignoring signal", "type", signal.Type.String())
+		return
+	}
+
+	reason := signal.Reason
+	if reason == "" {
+		reason = signal.Type.String()
+	}
+
+	if fraction := signal.ExitFraction(); fraction < 1 {
+		m.ReducePosition(live, pos.Size*fraction, price, reason)
+		return
+	}
+	m.ClosePosition(live, price, reason)
 }
 
 // ClosePosition closes an existing position
@@ -102,38 +318,152 @@ func (m *Manager) ClosePosition(live LivePosition, price float64, reason string)
 		return
 	}
 
-	fmt.Printf("[%s] Closing position: %s\n", live.GetID(), reason)
-	err := m.exchange.ClosePosition(live.GetSymbol(), pos.Size)
+	f, err := m.closeOrder(live.GetID(), live.GetSymbol(), pos.Side, pos.Size, price, reason)
 	if err != nil {
-		fmt.Printf("[%s] Failed to close position: %v\n", live.GetID(), err)
+		m.publishError(live.GetID(), "Failed to close position: %v", err)
 		return
 	}
 
+	trade := m.recordTrade(live, pos, pos.Size, f, reason)
 	pos.IsOpen = false
-	pos.ExitPrice = price
+	pos.ExitPrice = f.price
 	pos.ExitReason = reason
-	pos.ExitTime = time.Now().UnixMilli()
+	pos.ExitTime = m.now()
+	pos.Fees, pos.Funding = trade.Fees, trade.Funding
+
+	// Calculate PnL, adding to anything realized by earlier partial exits
+	pos.PnL += trade.PnL
+	m.publishPosition(live.GetID(), pos, events.KindPositionClosed)
+}
 
-	// Calculate PnL
-	var pnl float64
+// ReducePosition closes size units of the open position, keeping the rest open
+func (m *Manager) ReducePosition(live LivePosition, size float64, price float64, reason string) {
+	pos := live.GetPosition()
+	if pos == nil || !pos.IsOpen {
+		return
+	}
+	if size >= pos.Size {
+		m.ClosePosition(live, price, reason)
+		return
+	}
+
+	f, err := m.closeOrder(live.GetID(), live.GetSymbol(), pos.Side, size, price, reason)
+	if err != nil {
+		m.publishError(live.GetID(), "Failed to reduce position: %v", err)
+		return
+	}
+
+	trade := m.recordTrade(live, pos, size, f, reason)
+	pos.PnL += trade.PnL
+	pos.Size -= size
+	m.publishPosition(live.GetID(), pos, events.KindPositionUpdated)
+}
+
+// realizedPnL returns the PnL of closing size units of pos at price
+func realizedPnL(pos *exchange.Position, size float64, price float64) float64 {
 	if pos.Side == "long" {
-		pnl = (price - pos.EntryPrice) * pos.Size
-	} else {
-		pnl = (pos.EntryPrice - price) * pos.Size
+		return (price - pos.EntryPrice) * size
+	}
+	return (pos.EntryPrice - price) * size
+}
+
+// recordTrade returns the trade closing size units of pos at fill f and
+// passes it to live if it keeps its trades. The trade takes its share by
+// size of the fees and funding pos carries, plus the fee of the close.
+func (m *Manager) recordTrade(live any, pos *exchange.Position, size float64, f fill, reason string) exchange.Position {
+	share := 1.0
+	if size < pos.Size {
+		share = size / pos.Size
+	}
+	trade := *pos
+	trade.Size = size
+	trade.IsOpen = false
+	trade.ExitPrice = f.price
+	trade.ExitReason = reason
+	trade.ExitTime = m.now()
+	trade.PnL = realizedPnL(pos, size, f.price)
+	if pos.EntryPrice > 0 {
+		trade.PnLPercentage = trade.PnL / (size * pos.EntryPrice) * 100
 	}
-	pos.PnL = pnl
+	trade.Fees = pos.Fees*share + f.fee
+	trade.Funding = pos.Funding * share
+	pos.Fees -= pos.Fees * share
+	pos.Funding -= pos.Funding * share
 
-	fmt.Printf("[%s] Position closed: %s, PnL: %.2f\n", live.GetID(), reason, pnl)
+	if recorder, ok := live.(TradeRecorder); ok {
+		recorder.RecordTrade(trade)
+	}
+	return trade
+}
+
+// AccrueFunding books the funding an open position paid or received at
+// each funding hour since it was last accrued, at the current rate and
+// price. It does nothing unless the exchange reports funding rates.
+func (m *Manager) AccrueFunding(pos *exchange.Position, symbol string, price float64) {
+	if pos == nil || !pos.IsOpen {
+		return
+	}
+	reporter, ok := m.exchange.(exchange.FundingReporter)
+	if !ok {
+		return
+	}
+	const hour = int64(time.Hour / time.Millisecond)
+	now := m.now()
+	since := max(pos.FundingTime, pos.EntryTime)
+	hours := now/hour - since/hour
+	if hours <= 0 {
+		return
+	}
+	rate, err := reporter.FundingRate(symbol)
+	if err != nil {
+		slog.Warn("failed to fetch funding rate", logging.KeySymbol, symbol, "error", err)
+		return
+	}
+
+	// Longs pay shorts when the rate is positive
+	payment := pos.Size * price * rate * float64(hours)
+	if pos.Side == "long" {
+		payment = -payment
+	}
+	pos.Funding += payment
+	pos.FundingTime = now
+}
+
+// CheckExits closes the position when the range of a bar that opened after
+// its entry reaches a stop or target attached by the strategy, checking the
+// bar the way the backtester does
+func (m *Manager) CheckExits(live LivePosition, open, high, low float64) {
+	pos := live.GetPosition()
+	if pos == nil || !pos.IsOpen {
+		return
+	}
+	if exitPrice, reason, hit := pos.CheckAttachedExits(open, high, low); hit {
+		m.ClosePosition(live, exitPrice, reason)
+	}
 }
 
 // CheckTPSL checks if take profit or stop loss should be triggered
 func (m *Manager) CheckTPSL(live LivePosition, currentPrice float64) {
+	m.Observe(live.GetSymbol(), currentPrice)
 	pos := live.GetPosition()
 	if pos == nil || !pos.IsOpen {
 		return
 	}
 
-	config := live.GetConfig()
+	// Stops and targets attached by the strategy take precedence
+	if exitPrice, reason, hit := pos.CheckAttachedExits(currentPrice, currentPrice, currentPrice); hit {
+		m.ClosePosition(live, exitPrice, reason)
+		return
+	}
+
+	if reason, hit := percentExit(live.GetConfig(), pos, currentPrice); hit {
+		m.ClosePosition(live, currentPrice, reason)
+	}
+}
+
+// percentExit reports whether currentPrice reaches the take profit or stop
+// loss percentage of the execution config for pos
+func percentExit(config ExecutionConfig, pos *exchange.Position, currentPrice float64) (reason string, hit bool) {
 	entry := pos.EntryPrice
 
 	var pnlPercent float64
@@ -144,10 +474,11 @@ func (m *Manager) CheckTPSL(live LivePosition, currentPrice float64) {
 	}
 
 	if config.TakeProfitPercent > 0 && pnlPercent >= config.TakeProfitPercent {
-		m.ClosePosition(live, currentPrice, "Take Profit")
+		return "Take Profit", true
 	} else if config.StopLossPercent > 0 && pnlPercent <= -config.StopLossPercent {
-		m.ClosePosition(live, currentPrice, "Stop Loss")
+		return "Stop Loss", true
 	}
+	return "", false
 }
 
 // GetExchange returns the underlying exchange adapter
@@ -0,0 +1,172 @@
+package preset
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"terminal/internal/position"
+	"terminal/internal/strategy"
+)
+
+// Preset is a named, reusable strategy setup: which strategy to run, with
+// which params and execution config, on which market. StrategyVersion is the
+// strategy's Metadata.Version when the preset was saved.
+type Preset struct {
+	Name            string                   `json:"name"`
+	StrategyID      string                   `json:"strategyId"`
+	StrategyVersion string                   `json:"strategyVersion"`
+	Symbol          string                   `json:"symbol"`
+	Interval        string                   `json:"interval"`
+	Params          map[string]any           `json:"params"`
+	Config          position.ExecutionConfig `json:"config"`
+	Notes           string                   `json:"notes,omitempty"`
+	UpdatedAt       int64                    `json:"updatedAt"`
+}
+
+// Validate checks that the preset is complete and its params are accepted
+// by the current version of its strategy
+func (p Preset) Validate() error {
+	strat, err := p.strategy()
+	if err != nil {
+		return err
+	}
+	if err := strat.ValidateParams(p.Params); err != nil {
+		return fmt.Errorf("preset %s: invalid params: %w", p.Name, err)
+	}
+	return nil
+}
+
+// strategy checks the preset's required fields and returns its strategy
+func (p Preset) strategy() (strategy.Strategy, error) {
+	if err := checkName(p.Name); err != nil {
+		return nil, err
+	}
+	if p.Symbol == "" || p.Interval == "" {
+		return nil, fmt.Errorf("preset %s: symbol and interval are required", p.Name)
+	}
+	strat, err := strategy.Get(p.StrategyID)
+	if err != nil {
+		return nil, fmt.Errorf("preset %s: %w", p.Name, err)
+	}
+	return strat, nil
+}
+
+// checkName rejects names that are not a plain file name, as the name is
+// the preset's file name in the store
+func checkName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("preset name is required")
+	}
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid preset name %q", name)
+	}
+	return nil
+}
+
+// Migration describes how a preset relates to the current version of its
+// strategy
+type Migration struct {
+	Name           string `json:"name"`
+	StrategyID     string `json:"strategyId"`
+	PresetVersion  string `json:"presetVersion"`
+	CurrentVersion string `json:"currentVersion"`
+	// Outdated is set when the strategy version changed since the preset was saved
+	Outdated bool `json:"outdated"`
+	// Added lists parameters the strategy declares that the preset lacks;
+	// they take their defaults on migration
+	Added []string `json:"added,omitempty"`
+	// Removed lists preset parameters the strategy no longer declares;
+	// they are dropped on migration
+	Removed []string `json:"removed,omitempty"`
+	// Errors lists values the current version rejects; these must be fixed
+	// by hand before the preset can be applied
+	Errors []string `json:"errors,omitempty"`
+	// Params holds the migrated params when there are no errors
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// Compatible reports whether the preset can be applied to the current
+// strategy version, possibly after migration
+func (m Migration) Compatible() bool {
+	return len(m.Errors) == 0
+}
+
+// Check compares p against the current metadata of its strategy
+func Check(p Preset) (Migration, error) {
+	strat, err := p.strategy()
+	if err != nil {
+		return Migration{}, err
+	}
+	meta := strat.GetMetadata()
+
+	m := Migration{
+		Name:           p.Name,
+		StrategyID:     p.StrategyID,
+		PresetVersion:  p.StrategyVersion,
+		CurrentVersion: meta.Version,
+		Outdated:       p.StrategyVersion != meta.Version,
+	}
+
+	declared := make(map[string]bool, len(meta.Parameters))
+	for _, def := range meta.Parameters {
+		declared[def.Name] = true
+		if _, ok := p.Params[def.Name]; !ok {
+			m.Added = append(m.Added, def.Name)
+		}
+	}
+	for name := range p.Params {
+		if !declared[name] {
+			m.Removed = append(m.Removed, name)
+		}
+	}
+	sort.Strings(m.Removed)
+
+	params, err := strategy.ValidateAgainst(meta, p.Params)
+	if err != nil {
+		m.Errors = strings.Split(err.Error(), "\n")
+		return m, nil
+	}
+	m.Params = params
+	return m, nil
+}
+
+// Migrate returns p updated to the current strategy version, with removed
+// params dropped and added params set to their defaults
+func Migrate(p Preset) (Preset, error) {
+	m, err := Check(p)
+	if err != nil {
+		return p, err
+	}
+	if !m.Compatible() {
+		return p, fmt.Errorf("preset %s cannot be migrated to %s v%s: %s",
+			p.Name, p.StrategyID, m.CurrentVersion, strings.Join(m.Errors, "; "))
+	}
+	p.Params = m.Params
+	p.StrategyVersion = m.CurrentVersion
+	return p, nil
+}
+
+// Export encodes presets as an indented JSON array
+func Export(presets []Preset) ([]byte, error) {
+	return json.MarshalIndent(presets, "", "  ")
+}
+
+// Import decodes presets exported with Export. A single preset object is
+// accepted as well as an array.
+func Import(raw []byte) ([]Preset, error) {
+	trimmed := strings.TrimSpace(string(raw))
+	if strings.HasPrefix(trimmed, "{") {
+		var p Preset
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, fmt.Errorf("invalid preset: %w", err)
+		}
+		return []Preset{p}, nil
+	}
+	var presets []Preset
+	if err := json.Unmarshal(raw, &presets); err != nil {
+		return nil, fmt.Errorf("invalid presets: %w", err)
+	}
+	return presets, nil
+}
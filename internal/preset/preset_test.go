@@ -0,0 +1,146 @@
+package preset
+
+import (
+	"reflect"
+	"testing"
+
+	"terminal/internal/exchange"
+	"terminal/internal/position"
+	"terminal/internal/strategy"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+func ptr(f float64) *float64 { return &f }
+
+// versioned is a strategy at version 2, which added mode and dropped the
+// length parameter of version 1
+type versioned struct{}
+
+func (versioned) GetMetadata() strategy.Metadata {
+	return strategy.Metadata{ID: "test-versioned", Name: "Versioned", Version: "2", Parameters: []strategy.ParameterDef{
+		{Name: "period", Type: strategy.ParamInteger, DefaultValue: 14, Min: ptr(2)},
+		{Name: "mode", Type: strategy.ParamSelect, DefaultValue: "fast",
+			Options: []strategy.Option{{Value: "fast"}, {Value: "slow"}}},
+	}}
+}
+
+func (s versioned) ValidateParams(params map[string]any) error {
+	_, err := strategy.ValidateAgainst(s.GetMetadata(), params)
+	return err
+}
+
+func (versioned) Initialize(map[string]any) error { return nil }
+
+func (versioned) GenerateSignals([]hyperliquid.Candle) []exchange.Signal { return nil }
+
+func (versioned) GetVisualization([]hyperliquid.Candle) *strategy.Visualization {
+	return strategy.NewVisualization()
+}
+
+func register(t *testing.T) {
+	t.Helper()
+	strategy.Register("test-versioned", func() strategy.Strategy { return versioned{} })
+	t.Cleanup(func() { strategy.Unregister("test-versioned") })
+}
+
+// oldPreset is a preset saved with version 1
+func oldPreset(params map[string]any) Preset {
+	return Preset{
+		Name: "old", StrategyID: "test-versioned", StrategyVersion: "1", Symbol: "BTC", Interval: "1h",
+		Params: params, Config: position.ExecutionConfig{PositionSize: 1, TradeDirection: "both"},
+	}
+}
+
+func TestCheckAndMigrateOldPreset(t *testing.T) {
+	register(t)
+	p := oldPreset(map[string]any{"period": 20.0, "length": 5.0})
+
+	m, err := Check(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Migration{
+		Name: "old", StrategyID: "test-versioned", PresetVersion: "1", CurrentVersion: "2", Outdated: true,
+		Added: []string{"mode"}, Removed: []string{"length"}, Params: map[string]any{"period": 20, "mode": "fast"},
+	}
+	if !reflect.DeepEqual(m, want) || !m.Compatible() {
+		t.Errorf("check %+v, want %+v", m, want)
+	}
+
+	migrated, err := Migrate(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated.StrategyVersion != "2" || !reflect.DeepEqual(migrated.Params, want.Params) {
+		t.Errorf("migrated %+v", migrated)
+	}
+	if m, _ := Check(migrated); m.Outdated || len(m.Added) > 0 || len(m.Removed) > 0 {
+		t.Errorf("migrated preset still needs migration: %+v", m)
+	}
+}
+
+func TestMigrateRejectsInvalidValues(t *testing.T) {
+	register(t)
+	p := oldPreset(map[string]any{"period": 1.0, "mode": "turbo"})
+
+	m, err := Check(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Compatible() || len(m.Errors) != 2 || m.Params != nil {
+		t.Errorf("check %+v, want errors for period and mode", m)
+	}
+	if _, err := Migrate(p); err == nil {
+		t.Error("migrated a preset the strategy rejects")
+	}
+	if err := p.Validate(); err == nil {
+		t.Error("validated a preset the strategy rejects")
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	presets := []Preset{
+		oldPreset(map[string]any{"period": 20.0}),
+		{Name: "new", StrategyID: "test-versioned", StrategyVersion: "2", Symbol: "ETH", Interval: "4h",
+			Params: map[string]any{"mode": "slow"}, Notes: "hedge", UpdatedAt: 1700000000000,
+			Config: position.ExecutionConfig{PositionSize: 0.5, TradeDirection: "short", StopLossPercent: 2}},
+	}
+	raw, err := Export(presets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Import(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, presets) {
+		t.Errorf("imported %+v, want %+v", got, presets)
+	}
+
+	// A single exported preset is accepted too
+	single, err := Import([]byte(`{"name": "one", "strategyId": "test-versioned"}`))
+	if err != nil || len(single) != 1 || single[0].Name != "one" {
+		t.Errorf("imported %+v, %v", single, err)
+	}
+	if _, err := Import([]byte(`[{"name": 1}]`)); err == nil {
+		t.Error("imported malformed presets")
+	}
+}
+
+func TestInvalidNames(t *testing.T) {
+	register(t)
+	for _, name := range []string{"", "  ", "../escape", "a/b", `a\b`, "..", "x..y"} {
+		p := oldPreset(nil)
+		p.Name = name
+		if err := p.Validate(); err == nil {
+			t.Errorf("preset named %q validated", name)
+		}
+		if _, err := Check(p); err == nil {
+			t.Errorf("preset named %q checked", name)
+		}
+	}
+	if err := oldPreset(nil).Validate(); err != nil {
+		t.Errorf("valid preset rejected: %v", err)
+	}
+}
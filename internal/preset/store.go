@@ -0,0 +1,157 @@
+package preset
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store persists presets as JSON files in a directory
+type Store struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewStore creates a store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// List returns all saved presets sorted by name
+func (s *Store) List() ([]Preset, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return []Preset{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read presets: %w", err)
+	}
+
+	presets := []Preset{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		p, err := s.read(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, p)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets, nil
+}
+
+// Get returns the preset with the given name
+func (s *Store) Get(name string) (Preset, error) {
+	if err := checkName(name); err != nil {
+		return Preset{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, err := s.read(s.path(name))
+	if os.IsNotExist(err) {
+		return Preset{}, fmt.Errorf("preset not found: %s", name)
+	}
+	return p, err
+}
+
+// Save validates p, stamps it with the current strategy version and writes
+// it to the store, replacing any preset with the same name
+func (s *Store) Save(p Preset) (Preset, error) {
+	if err := p.Validate(); err != nil {
+		return p, err
+	}
+	strat, _ := p.strategy()
+	p.StrategyVersion = strat.GetMetadata().Version
+	p.UpdatedAt = time.Now().UnixMilli()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return p, fmt.Errorf("failed to create presets dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return p, err
+	}
+	return p, os.WriteFile(s.path(p.Name), raw, 0o644)
+}
+
+// Delete removes a preset
+func (s *Store) Delete(name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("preset not found: %s", name)
+		}
+		return err
+	}
+	return nil
+}
+
+// Import saves presets decoded from raw JSON. Presets keep the strategy
+// version and params they were exported with so that Check reports any
+// drift; only presets for unknown strategies are rejected.
+func (s *Store) Import(raw []byte) ([]Preset, error) {
+	presets, err := Import(raw)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range presets {
+		if _, err := p.strategy(); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create presets dir: %w", err)
+	}
+	for i, p := range presets {
+		if p.UpdatedAt == 0 {
+			presets[i].UpdatedAt = time.Now().UnixMilli()
+		}
+		encoded, err := json.MarshalIndent(presets[i], "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(s.path(p.Name), encoded, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return presets, nil
+}
+
+func (s *Store) read(path string) (Preset, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Preset{}, err
+	}
+	var p Preset
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return Preset{}, fmt.Errorf("invalid preset %s: %w", filepath.Base(path), err)
+	}
+	return p, nil
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, filepath.Base(name)+".json")
+}
@@ -0,0 +1,100 @@
+package preset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	register(t)
+	dir := t.TempDir()
+	s := NewStore(filepath.Join(dir, "presets"))
+
+	if list, err := s.List(); err != nil || len(list) != 0 {
+		t.Fatalf("empty store lists %v, %v", list, err)
+	}
+	saved, err := s.Save(oldPreset(map[string]any{"period": 20.0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Saving stamps the current version
+	if saved.StrategyVersion != "2" || saved.UpdatedAt == 0 {
+		t.Errorf("saved %+v", saved)
+	}
+	got, err := s.Get("old")
+	if err != nil || got.StrategyVersion != "2" || got.Params["period"] != 20.0 {
+		t.Errorf("got %+v, %v", got, err)
+	}
+
+	if err := s.Delete("old"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("old"); err == nil {
+		t.Error("got a deleted preset")
+	}
+	if err := s.Delete("old"); err == nil {
+		t.Error("deleted a missing preset")
+	}
+}
+
+func TestStoreImportKeepsVersion(t *testing.T) {
+	register(t)
+	s := NewStore(t.TempDir())
+
+	raw, err := Export([]Preset{oldPreset(map[string]any{"length": 5.0}), {
+		Name: "b", StrategyID: "test-versioned", StrategyVersion: "2", Symbol: "ETH", Interval: "1h",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Import(raw); err != nil {
+		t.Fatal(err)
+	}
+	list, err := s.List()
+	if err != nil || len(list) != 2 || list[0].Name != "b" || list[1].Name != "old" {
+		t.Fatalf("listed %+v, %v", list, err)
+	}
+	// Imported presets keep their version so Check reports the drift
+	if m, _ := Check(list[1]); !m.Outdated || len(m.Removed) != 1 {
+		t.Errorf("imported preset %+v checks as %+v", list[1], m)
+	}
+
+	unknown, _ := Export([]Preset{{Name: "c", StrategyID: "missing", Symbol: "BTC", Interval: "1h"}})
+	if _, err := s.Import(unknown); err == nil {
+		t.Error("imported a preset of an unknown strategy")
+	}
+}
+
+func TestStoreRejectsPathNames(t *testing.T) {
+	register(t)
+	root := t.TempDir()
+	s := NewStore(filepath.Join(root, "presets"))
+	if _, err := s.Save(oldPreset(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"../escape", "sub/old", `..\escape`, ".."} {
+		p := oldPreset(nil)
+		p.Name = name
+		if _, err := s.Save(p); err == nil {
+			t.Errorf("saved a preset named %q", name)
+		}
+		raw, _ := Export([]Preset{p})
+		if _, err := s.Import(raw); err == nil {
+			t.Errorf("imported a preset named %q", name)
+		}
+		if _, err := s.Get(name); err == nil {
+			t.Errorf("got a preset named %q", name)
+		}
+		if err := s.Delete(name); err == nil {
+			t.Errorf("deleted a preset named %q", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "escape.json")); !os.IsNotExist(err) {
+		t.Error("a preset was written outside the store")
+	}
+	if _, err := s.Get("old"); err != nil {
+		t.Errorf("valid preset lost: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package risk
+
+import (
+	"fmt"
+
+	"terminal/internal/exchange"
+)
+
+// Guard is an exchange adapter checking orders against a Manager's limits
+// before passing them on. Closing orders are never blocked.
+type Guard struct {
+	exchange.Adapter
+	risk    *Manager
+	account string
+}
+
+// Guard wraps the adapter of an account so its orders count towards the
+// limits
+func (m *Manager) Guard(account string, adapter exchange.Adapter) *Guard {
+	return &Guard{Adapter: adapter, risk: m, account: account}
+}
+
+// OpenPosition opens a position if it stays within the limits
+func (g *Guard) OpenPosition(symbol string, side string, size float64, leverage int) (*exchange.Position, error) {
+	r, err := g.risk.reserve(g.account, symbol, side, size, leverage)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := g.Adapter.OpenPosition(symbol, side, size, leverage)
+	if err != nil {
+		g.risk.settle(r, false, 0)
+		return nil, err
+	}
+	g.risk.settle(r, true, pos.EntryPrice)
+	return pos, nil
+}
+
+// ClosePosition closes size units of a position
+func (g *Guard) ClosePosition(symbol string, size float64) error {
+	_, err := g.ClosePositionFill(symbol, size)
+	return err
+}
+
+// ClosePositionFill closes size units of a position and returns the fill
+// price when the wrapped adapter reports it
+func (g *Guard) ClosePositionFill(symbol string, size float64) (float64, error) {
+	var fill float64
+	var err error
+	if reporter, ok := g.Adapter.(exchange.FillReporter); ok {
+		fill, err = reporter.ClosePositionFill(symbol, size)
+	} else {
+		err = g.Adapter.ClosePosition(symbol, size)
+	}
+	if err != nil {
+		return 0, err
+	}
+	g.risk.closed(g.account, symbol, size, fill)
+	return fill, nil
+}
+
+// MidPrice quotes symbol through the wrapped adapter
+func (g *Guard) MidPrice(symbol string) (float64, error) {
+	quoter, ok := g.Adapter.(exchange.PriceQuoter)
+	if !ok {
+		return 0, fmt.Errorf("no price quotes for %s", symbol)
+	}
+	return quoter.MidPrice(symbol)
+}
+
+// TakerFeeRate returns the fee rate of the wrapped adapter
+func (g *Guard) TakerFeeRate() (float64, error) {
+	reporter, ok := g.Adapter.(exchange.FeeReporter)
+	if !ok {
+		return 0, fmt.Errorf("fee rate of account %s is not known", g.account)
+	}
+	return reporter.TakerFeeRate()
+}
+
+// FundingRate returns the funding rate of symbol from the wrapped adapter
+func (g *Guard) FundingRate(symbol string) (float64, error) {
+	reporter, ok := g.Adapter.(exchange.FundingReporter)
+	if !ok {
+		return 0, fmt.Errorf("no funding rates for %s", symbol)
+	}
+	return reporter.FundingRate(symbol)
+}
+
+// ObservePrice records the latest price of symbol
+func (g *Guard) ObservePrice(symbol string, price float64) {
+	g.risk.Mark(symbol, price)
+	if observer, ok := g.Adapter.(exchange.PriceObserver); ok {
+		observer.ObservePrice(symbol, price)
+	}
+}
+
+// Verify Guard implements Adapter and the optional interfaces
+var (
+	_ exchange.Adapter         = (*Guard)(nil)
+	_ exchange.PriceObserver   = (*Guard)(nil)
+	_ exchange.PriceQuoter     = (*Guard)(nil)
+	_ exchange.FillReporter    = (*Guard)(nil)
+	_ exchange.FeeReporter     = (*Guard)(nil)
+	_ exchange.FundingReporter = (*Guard)(nil)
+)
@@ -0,0 +1,460 @@
+// Package risk enforces portfolio-level limits on live trading. A Manager
+// tracks the exposure opened through every account and rejects orders that
+// would breach a limit; loss limits halt trading until it is resumed.
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"terminal/internal/clock"
+)
+
+var (
+	// ErrLimit is returned for orders that would breach a limit
+	ErrLimit = errors.New("risk limit exceeded")
+	// ErrHalted is returned for orders placed while trading is halted
+	ErrHalted = errors.New("trading halted")
+)
+
+// Limits caps live exposure across all accounts. Zero disables a limit.
+type Limits struct {
+	// MaxGrossNotional caps the summed absolute notional of all positions
+	MaxGrossNotional float64 `json:"maxGrossNotional"`
+	// MaxNetNotional caps the absolute difference of long and short notional
+	MaxNetNotional float64 `json:"maxNetNotional"`
+	// MaxPositionSize caps the size held in one symbol, in coins
+	MaxPositionSize float64 `json:"maxPositionSize"`
+	// MaxOpenPositions caps the number of open account and symbol pairs
+	MaxOpenPositions int `json:"maxOpenPositions"`
+	// MaxLeverage caps the leverage of new positions
+	MaxLeverage int `json:"maxLeverage"`
+	// MaxDailyLoss halts trading once realized and unrealized PnL for the
+	// UTC day falls this far
+	MaxDailyLoss float64 `json:"maxDailyLoss"`
+	// MaxDrawdown halts trading once PnL falls this far below its high
+	MaxDrawdown float64 `json:"maxDrawdown"`
+	// FlattenOnBreach closes all positions and stops all strategies when
+	// trading is halted by a loss limit
+	FlattenOnBreach bool `json:"flattenOnBreach"`
+}
+
+// Status is a snapshot of exposure, PnL and the halt state
+type Status struct {
+	Limits        Limits  `json:"limits"`
+	Halted        bool    `json:"halted"`
+	Reason        string  `json:"reason,omitempty"`
+	GrossNotional float64 `json:"grossNotional"`
+	NetNotional   float64 `json:"netNotional"`
+	OpenPositions int     `json:"openPositions"`
+	RealizedPnL   float64 `json:"realizedPnL"`
+	UnrealizedPnL float64 `json:"unrealizedPnL"`
+	DailyPnL      float64 `json:"dailyPnL"`
+	// HighWaterMark is the highest PnL seen; Drawdown is how far below it
+	// PnL is now
+	HighWaterMark float64 `json:"highWaterMark"`
+	Drawdown      float64 `json:"drawdown"`
+}
+
+// exposure is the net position one account holds in one symbol. Size is
+// negative for shorts.
+type exposure struct {
+	size  float64
+	entry float64
+}
+
+type key struct {
+	account string
+	symbol  string
+}
+
+// reservation is the exposure of an order that passed the limits and has
+// not filled yet
+type reservation struct {
+	key   key
+	delta float64
+}
+
+// Manager enforces Limits across the accounts it guards
+type Manager struct {
+	mu        sync.Mutex
+	limits    Limits
+	positions map[key]*exposure
+	// pending holds the signed size of orders in flight, which count
+	// towards the limits until they fill or fail
+	pending  map[key]float64
+	marks    map[string]float64
+	realized float64
+	hwm      float64
+	day      string
+	dayStart float64
+	halted   bool
+	reason   string
+	onHalt   func(reason string, flatten bool)
+	clock    clock.Clock
+}
+
+// NewManager creates a risk manager enforcing limits
+func NewManager(limits Limits) *Manager {
+	return &Manager{
+		limits:    limits,
+		positions: make(map[key]*exposure),
+		pending:   make(map[key]float64),
+		marks:     make(map[string]float64),
+		clock:     clock.Real,
+	}
+}
+
+// SetClock sets the clock the UTC day of the daily loss is read from
+func (m *Manager) SetClock(clk clock.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = clk
+}
+
+// OnHalt sets a function called on its own goroutine whenever trading is
+// halted. flatten is set when positions should be closed and strategies
+// stopped.
+func (m *Manager) OnHalt(fn func(reason string, flatten bool)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onHalt = fn
+}
+
+// Limits returns the enforced limits
+func (m *Manager) Limits() Limits {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.limits
+}
+
+// Halt rejects new orders until Resume is called. flatten is passed on to
+// the OnHalt function.
+func (m *Manager) Halt(reason string, flatten bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.halt(reason, flatten)
+}
+
+// halt must be called with m.mu held
+func (m *Manager) halt(reason string, flatten bool) {
+	if m.halted {
+		return
+	}
+	m.halted = true
+	m.reason = reason
+	slog.Error("trading halted", "reason", reason, "flatten", flatten)
+	if m.onHalt != nil {
+		go m.onHalt(reason, flatten)
+	}
+}
+
+// Err returns an error wrapping ErrHalted while trading is halted
+func (m *Manager) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.halted {
+		return fmt.Errorf("%w: %s", ErrHalted, m.reason)
+	}
+	return nil
+}
+
+// Resume accepts new orders again. The daily loss and drawdown are measured
+// afresh from the current PnL, so a loss limit does not halt again at once.
+func (m *Manager) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.halted {
+		return
+	}
+	pnl := m.pnl()
+	m.halted = false
+	m.reason = ""
+	m.hwm = pnl
+	m.dayStart = pnl
+	slog.Info("trading resumed")
+}
+
+// Status returns a snapshot of exposure and PnL
+func (m *Manager) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	gross, net := m.notional()
+	unrealized := m.unrealized()
+	pnl := m.realized + unrealized
+	return Status{
+		Limits:        m.limits,
+		Halted:        m.halted,
+		Reason:        m.reason,
+		GrossNotional: gross,
+		NetNotional:   net,
+		OpenPositions: len(m.positions),
+		RealizedPnL:   m.realized,
+		UnrealizedPnL: unrealized,
+		DailyPnL:      pnl - m.dayStart,
+		HighWaterMark: m.hwm,
+		Drawdown:      m.hwm - pnl,
+	}
+}
+
+// Mark records the latest price of symbol and checks the loss limits
+func (m *Manager) Mark(symbol string, price float64) {
+	if price <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollDay()
+	m.marks[symbol] = price
+	for k, pos := range m.positions {
+		// Positions opened before the first price take it as their entry
+		if k.symbol == symbol && pos.entry == 0 {
+			pos.entry = price
+		}
+	}
+	m.checkLoss()
+}
+
+// rollDay starts measuring the daily loss afresh on a new UTC day. It must
+// be called with m.mu held, before the PnL changes.
+func (m *Manager) rollDay() {
+	if day := m.clock.Now().UTC().Format(time.DateOnly); day != m.day {
+		m.day = day
+		m.dayStart = m.pnl()
+	}
+}
+
+// checkLoss halts trading when a loss limit is reached. It must be called
+// with m.mu held.
+func (m *Manager) checkLoss() {
+	m.rollDay()
+	pnl := m.pnl()
+	m.hwm = math.Max(m.hwm, pnl)
+
+	if m.halted {
+		return
+	}
+	if loss := m.dayStart - pnl; m.limits.MaxDailyLoss > 0 && loss >= m.limits.MaxDailyLoss {
+		m.halt(fmt.Sprintf("daily loss %.2f reached limit %.2f", loss, m.limits.MaxDailyLoss), m.limits.FlattenOnBreach)
+		return
+	}
+	if drawdown := m.hwm - pnl; m.limits.MaxDrawdown > 0 && drawdown >= m.limits.MaxDrawdown {
+		m.halt(fmt.Sprintf("drawdown %.2f reached limit %.2f", drawdown, m.limits.MaxDrawdown), m.limits.FlattenOnBreach)
+	}
+}
+
+// CheckOpen reports whether an order opening or adding size units of symbol
+// on side would stay within the limits, counting orders still in flight
+func (m *Manager) CheckOpen(account, symbol, side string, size float64, leverage int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkOpen(key{account, symbol}, side, size, leverage)
+}
+
+// reserve checks an order like CheckOpen and, if it passes, counts it
+// towards the limits until settle is called, so concurrent orders cannot
+// all pass the same check
+func (m *Manager) reserve(account, symbol, side string, size float64, leverage int) (*reservation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := key{account, symbol}
+	if err := m.checkOpen(k, side, size, leverage); err != nil {
+		return nil, err
+	}
+	r := &reservation{key: k, delta: signed(side, size)}
+	m.pending[k] += r.delta
+	return r, nil
+}
+
+// settle releases a reservation once its order is done. When it filled, the
+// position is recorded at price, or the latest mark when price is 0.
+func (m *Manager) settle(r *reservation, filled bool, price float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pending[r.key] -= r.delta; math.Abs(m.pending[r.key]) < 1e-12 {
+		delete(m.pending, r.key)
+	}
+	if filled {
+		m.rollDay()
+		m.open(r.key, r.delta, price)
+	}
+}
+
+func (m *Manager) checkOpen(k key, side string, size float64, leverage int) error {
+	if m.halted {
+		return fmt.Errorf("%w: %s", ErrHalted, m.reason)
+	}
+	if m.limits.MaxLeverage > 0 && leverage > m.limits.MaxLeverage {
+		return fmt.Errorf("%w: leverage %d above %d", ErrLimit, leverage, m.limits.MaxLeverage)
+	}
+
+	held := m.held()
+	delta := signed(side, size)
+	current := held[k]
+	after := current + delta
+
+	if m.limits.MaxPositionSize > 0 {
+		total := math.Abs(after)
+		for other, size := range held {
+			if other != k && other.symbol == k.symbol {
+				total += math.Abs(size)
+			}
+		}
+		if total > m.limits.MaxPositionSize {
+			return fmt.Errorf("%w: %s size %.4f above %.4f", ErrLimit, k.symbol, total, m.limits.MaxPositionSize)
+		}
+	}
+
+	if m.limits.MaxOpenPositions > 0 && current == 0 && after != 0 && len(held) >= m.limits.MaxOpenPositions {
+		return fmt.Errorf("%w: %d positions open, limit %d", ErrLimit, len(held), m.limits.MaxOpenPositions)
+	}
+
+	if m.limits.MaxGrossNotional > 0 || m.limits.MaxNetNotional > 0 {
+		price, ok := m.marks[k.symbol]
+		if !ok {
+			return fmt.Errorf("%w: no price for %s to check notional", ErrLimit, k.symbol)
+		}
+		var gross, net float64
+		for other, size := range held {
+			gross += math.Abs(size) * m.price(other.symbol, m.positions[other])
+			net += size * m.price(other.symbol, m.positions[other])
+		}
+		gross += (math.Abs(after) - math.Abs(current)) * price
+		net = math.Abs(net + delta*price)
+		if m.limits.MaxGrossNotional > 0 && gross > m.limits.MaxGrossNotional {
+			return fmt.Errorf("%w: gross notional %.2f above %.2f", ErrLimit, gross, m.limits.MaxGrossNotional)
+		}
+		if m.limits.MaxNetNotional > 0 && net > m.limits.MaxNetNotional {
+			return fmt.Errorf("%w: net notional %.2f above %.2f", ErrLimit, net, m.limits.MaxNetNotional)
+		}
+	}
+	return nil
+}
+
+// held returns the signed size of every position including the orders in
+// flight. It must be called with m.mu held.
+func (m *Manager) held() map[key]float64 {
+	held := make(map[key]float64, len(m.positions)+len(m.pending))
+	for k, pos := range m.positions {
+		held[k] = pos.size
+	}
+	for k, size := range m.pending {
+		held[k] += size
+	}
+	for k, size := range held {
+		if math.Abs(size) < 1e-12 {
+			delete(held, k)
+		}
+	}
+	return held
+}
+
+// open records a filled order of delta units opening or adding to a
+// position at price, or the latest mark when price is 0. It must be called
+// with m.mu held.
+func (m *Manager) open(k key, delta, price float64) {
+	if price <= 0 {
+		price = m.marks[k.symbol]
+	}
+	size := math.Abs(delta)
+
+	pos := m.positions[k]
+	if pos == nil {
+		m.positions[k] = &exposure{size: delta, entry: price}
+		return
+	}
+	if pos.size*delta < 0 {
+		// An open on the other side nets against the position
+		m.reduce(k, pos, math.Min(math.Abs(delta), math.Abs(pos.size)), price)
+		if rest := math.Abs(delta) - math.Abs(pos.size); rest > 0 {
+			m.positions[k] = &exposure{size: math.Copysign(rest, delta), entry: price}
+		}
+		return
+	}
+	total := math.Abs(pos.size) + size
+	pos.entry = (pos.entry*math.Abs(pos.size) + price*size) / total
+	pos.size += delta
+}
+
+// closed records a filled order closing size units of a position, all of it
+// when size is 0, at price, or the latest mark when price is 0
+func (m *Manager) closed(account, symbol string, size, price float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if price <= 0 {
+		price = m.marks[symbol]
+	}
+	m.rollDay()
+	k := key{account, symbol}
+	if pos := m.positions[k]; pos != nil {
+		if size <= 0 {
+			size = math.Abs(pos.size)
+		}
+		m.reduce(k, pos, math.Min(size, math.Abs(pos.size)), price)
+	}
+	m.checkLoss()
+}
+
+// reduce realizes the PnL of closing size units of pos at price. It must be
+// called with m.mu held.
+func (m *Manager) reduce(k key, pos *exposure, size, price float64) {
+	if price > 0 && pos.entry > 0 {
+		m.realized += math.Copysign(size, pos.size) * (price - pos.entry)
+	}
+	pos.size -= math.Copysign(size, pos.size)
+	if math.Abs(pos.size) < 1e-12 {
+		delete(m.positions, k)
+	}
+}
+
+// notional returns the gross and absolute net notional at the latest marks.
+// It must be called with m.mu held.
+func (m *Manager) notional() (gross, net float64) {
+	for k, pos := range m.positions {
+		price := m.price(k.symbol, pos)
+		gross += math.Abs(pos.size) * price
+		net += pos.size * price
+	}
+	return gross, math.Abs(net)
+}
+
+// unrealized returns the PnL of open positions at the latest marks
+func (m *Manager) unrealized() float64 {
+	var pnl float64
+	for k, pos := range m.positions {
+		if pos.entry == 0 {
+			continue
+		}
+		pnl += pos.size * (m.price(k.symbol, pos) - pos.entry)
+	}
+	return pnl
+}
+
+// pnl returns realized plus unrealized PnL
+func (m *Manager) pnl() float64 {
+	return m.realized + m.unrealized()
+}
+
+// price returns the latest mark of symbol, or the entry of pos before one
+// is seen
+func (m *Manager) price(symbol string, pos *exposure) float64 {
+	if price, ok := m.marks[symbol]; ok {
+		return price
+	}
+	if pos == nil {
+		return 0
+	}
+	return pos.entry
+}
+
+// signed returns size as a signed quantity, negative for shorts
+func signed(side string, size float64) float64 {
+	if side == "short" {
+		return -size
+	}
+	return size
+}
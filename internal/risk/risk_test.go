@@ -0,0 +1,260 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"terminal/internal/clock"
+	"terminal/internal/exchange"
+)
+
+// slipped is a mock exchange filling entries one above and closes one below
+// the latest price, slowly enough for concurrent orders to overlap
+type slipped struct {
+	*exchange.MockAdapter
+	delay time.Duration
+}
+
+func (s *slipped) OpenPosition(symbol string, side string, size float64, leverage int) (*exchange.Position, error) {
+	time.Sleep(s.delay)
+	pos, err := s.MockAdapter.OpenPosition(symbol, side, size, leverage)
+	if err != nil {
+		return nil, err
+	}
+	pos.EntryPrice++
+	return pos, nil
+}
+
+func (s *slipped) ClosePositionFill(symbol string, size float64) (float64, error) {
+	fill, err := s.MockAdapter.ClosePositionFill(symbol, size)
+	return fill - 1, err
+}
+
+func newGuard(m *Manager, account string) *Guard {
+	return m.Guard(account, &slipped{MockAdapter: exchange.NewMockAdapter(10_000)})
+}
+
+type order struct {
+	account, symbol, side string
+	size                  float64
+	leverage              int
+	// rejected is set when the order must fail with ErrLimit
+	rejected bool
+}
+
+func TestLimitsRejectOrders(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		orders []order
+	}{
+		{
+			name:   "leverage",
+			limits: Limits{MaxLeverage: 5},
+			orders: []order{
+				{account: "a", symbol: "BTC", side: "long", size: 1, leverage: 5},
+				{account: "a", symbol: "ETH", side: "long", size: 1, leverage: 10, rejected: true},
+			},
+		},
+		{
+			name:   "position size across accounts",
+			limits: Limits{MaxPositionSize: 1},
+			orders: []order{
+				{account: "a", symbol: "BTC", side: "long", size: 0.6},
+				{account: "b", symbol: "BTC", side: "short", size: 0.6, rejected: true},
+				{account: "b", symbol: "ETH", side: "short", size: 0.6},
+			},
+		},
+		{
+			name:   "open positions",
+			limits: Limits{MaxOpenPositions: 1},
+			orders: []order{
+				{account: "a", symbol: "BTC", side: "long", size: 1},
+				{account: "a", symbol: "ETH", side: "long", size: 1, rejected: true},
+				{account: "a", symbol: "BTC", side: "long", size: 1},
+			},
+		},
+		{
+			name:   "gross notional",
+			limits: Limits{MaxGrossNotional: 1000},
+			orders: []order{
+				{account: "a", symbol: "BTC", side: "long", size: 5},
+				{account: "a", symbol: "ETH", side: "short", size: 6, rejected: true},
+				{account: "a", symbol: "ETH", side: "short", size: 5},
+			},
+		},
+		{
+			name:   "net notional",
+			limits: Limits{MaxNetNotional: 500},
+			orders: []order{
+				{account: "a", symbol: "BTC", side: "long", size: 4},
+				{account: "a", symbol: "ETH", side: "long", size: 2, rejected: true},
+				{account: "a", symbol: "ETH", side: "short", size: 2},
+			},
+		},
+		{
+			name:   "notional without a price",
+			limits: Limits{MaxGrossNotional: 1000},
+			orders: []order{
+				{account: "a", symbol: "SOL", side: "long", size: 1, rejected: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(tt.limits)
+			guards := map[string]*Guard{"a": newGuard(m, "a"), "b": newGuard(m, "b")}
+			for _, g := range guards {
+				g.ObservePrice("BTC", 100)
+				g.ObservePrice("ETH", 100)
+			}
+			for i, o := range tt.orders {
+				_, err := guards[o.account].OpenPosition(o.symbol, o.side, o.size, max(o.leverage, 1))
+				if o.rejected != errors.Is(err, ErrLimit) {
+					t.Errorf("order %d %+v: got %v", i, o, err)
+				}
+			}
+		})
+	}
+}
+
+func TestExposureRecordedAtFills(t *testing.T) {
+	m := NewManager(Limits{})
+	g := newGuard(m, "a")
+	g.ObservePrice("BTC", 100)
+	if _, err := g.OpenPosition("BTC", "long", 2, 1); err != nil {
+		t.Fatal(err)
+	}
+	g.ObservePrice("BTC", 110)
+
+	// Entered at 101, half closed at 109 and the rest marked at 110
+	if _, err := g.ClosePositionFill("BTC", 1); err != nil {
+		t.Fatal(err)
+	}
+	status := m.Status()
+	if status.RealizedPnL != 8 || status.UnrealizedPnL != 9 || status.OpenPositions != 1 {
+		t.Errorf("realized %v and unrealized %v with %d open, want 8 and 9 with 1",
+			status.RealizedPnL, status.UnrealizedPnL, status.OpenPositions)
+	}
+
+	// Size 0 closes the rest
+	if _, err := g.ClosePositionFill("BTC", 0); err != nil {
+		t.Fatal(err)
+	}
+	if status := m.Status(); status.RealizedPnL != 16 || status.OpenPositions != 0 {
+		t.Errorf("realized %v with %d open after closing all, want 16 with none", status.RealizedPnL, status.OpenPositions)
+	}
+}
+
+func TestLossLimitsHaltAndResume(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		marks  []float64
+	}{
+		{name: "daily loss", limits: Limits{MaxDailyLoss: 50, FlattenOnBreach: true}, marks: []float64{80, 60, 45}},
+		{name: "drawdown", limits: Limits{MaxDrawdown: 30, FlattenOnBreach: true}, marks: []float64{150, 125, 120}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(tt.limits)
+			halts := make(chan bool, 1)
+			m.OnHalt(func(reason string, flatten bool) { halts <- flatten })
+			g := newGuard(m, "a")
+			g.ObservePrice("BTC", 99)
+			if _, err := g.OpenPosition("BTC", "long", 1, 1); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, mark := range tt.marks {
+				g.ObservePrice("BTC", mark)
+				if halted := m.Err() != nil; halted != (i == len(tt.marks)-1) {
+					t.Fatalf("halted %v at mark %v", halted, mark)
+				}
+			}
+			select {
+			case flatten := <-halts:
+				if !flatten {
+					t.Error("halt did not ask to flatten")
+				}
+			case <-time.After(time.Second):
+				t.Fatal("OnHalt was not called")
+			}
+			if _, err := g.OpenPosition("ETH", "long", 1, 1); !errors.Is(err, ErrHalted) {
+				t.Errorf("open while halted: got %v, want ErrHalted", err)
+			}
+			if _, err := g.ClosePositionFill("BTC", 0.5); err != nil {
+				t.Errorf("close while halted: %v", err)
+			}
+
+			// Resuming measures the loss afresh
+			m.Resume()
+			g.ObservePrice("BTC", tt.marks[len(tt.marks)-1])
+			if err := m.Err(); err != nil {
+				t.Fatalf("still halted after resume: %v", err)
+			}
+			if status := m.Status(); status.DailyPnL != 0 || status.Drawdown != 0 {
+				t.Errorf("daily PnL %v and drawdown %v after resume, want 0", status.DailyPnL, status.Drawdown)
+			}
+			if _, err := g.OpenPosition("ETH", "long", 1, 1); err != nil {
+				t.Errorf("open after resume: %v", err)
+			}
+		})
+	}
+}
+
+func TestDailyLossRollsOverAtUTCMidnight(t *testing.T) {
+	m := NewManager(Limits{MaxDailyLoss: 50})
+	sim := clock.NewSim(time.Date(2026, 1, 1, 22, 0, 0, 0, time.UTC))
+	m.SetClock(sim)
+	g := newGuard(m, "a")
+	g.ObservePrice("BTC", 99)
+	if _, err := g.OpenPosition("BTC", "long", 1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// 40 lost on the first day and 45 on the second stay within the limit
+	g.ObservePrice("BTC", 60)
+	sim.Advance(4 * time.Hour)
+	g.ObservePrice("BTC", 15)
+	if err := m.Err(); err != nil {
+		t.Fatalf("halted on the second day's loss of 45: %v", err)
+	}
+	g.ObservePrice("BTC", 5)
+	if err := m.Err(); !errors.Is(err, ErrHalted) {
+		t.Errorf("second day's loss of 55 did not halt: %v", err)
+	}
+}
+
+func TestConcurrentOpensStayWithinLimits(t *testing.T) {
+	m := NewManager(Limits{MaxGrossNotional: 500, MaxOpenPositions: 8})
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	filled := 0
+	for i := range 10 {
+		g := m.Guard(fmt.Sprint("account", i), &slipped{MockAdapter: exchange.NewMockAdapter(10_000), delay: 10 * time.Millisecond})
+		g.ObservePrice("BTC", 99)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := g.OpenPosition("BTC", "long", 1, 1); err == nil {
+				mu.Lock()
+				filled++
+				mu.Unlock()
+			} else if !errors.Is(err, ErrLimit) {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Positions are valued at the mark of 99, so five fit
+	if status := m.Status(); filled != 5 || status.GrossNotional > 500 {
+		t.Errorf("%d of 10 concurrent orders filled for gross notional %v, want 5 within 500",
+			filled, status.GrossNotional)
+	}
+}
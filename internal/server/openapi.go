@@ -0,0 +1,197 @@
+package server
+
+import (
+	"reflect"
+	"strings"
+)
+
+// openAPI builds an OpenAPI 3 document from the route table. Schemas are
+// derived from the Go request and response types by reflection, so the
+// document cannot drift from the handlers.
+func (s *Server) openAPI() map[string]any {
+	gen := &schemaGen{components: map[string]any{}, names: map[reflect.Type]string{}}
+	errorSchema := gen.schema(reflect.TypeFor[ErrorResponse]())
+
+	paths := map[string]any{}
+	for _, rt := range s.routes {
+		op := map[string]any{
+			"summary":     rt.summary,
+			"operationId": operationID(rt),
+		}
+
+		var params []any
+		for _, p := range rt.params {
+			params = append(params, map[string]any{
+				"name":        p.name,
+				"in":          p.in,
+				"required":    p.required,
+				"description": p.description,
+				"schema":      map[string]any{"type": p.kind},
+			})
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+
+		if rt.request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(gen.schema(rt.request)),
+			}
+		}
+
+		responses := map[string]any{
+			"400": map[string]any{"description": "Invalid request", "content": jsonContent(errorSchema)},
+			"401": map[string]any{"description": "Missing or invalid token", "content": jsonContent(errorSchema)},
+			"500": map[string]any{"description": "Request failed", "content": jsonContent(errorSchema)},
+		}
+		if rt.stream {
+			responses["101"] = map[string]any{
+				"description": "WebSocket stream; each text message is one JSON encoded value",
+				"content":     jsonContent(gen.schema(rt.response)),
+			}
+		} else {
+			responses["200"] = map[string]any{
+				"description": "OK",
+				"content":     jsonContent(gen.schema(rt.response)),
+			}
+		}
+		op["responses"] = responses
+
+		item, _ := paths[rt.path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[rt.path] = item
+		}
+		item[strings.ToLower(rt.method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "HyperTerminal API",
+			"version": "1.0.0",
+			"description": "Local API mirroring the HyperTerminal app. Authenticate with " +
+				"\"Authorization: Bearer <token>\" or the token query parameter.",
+		},
+		"paths":    paths,
+		"security": []any{map[string]any{"bearer": []any{}}, map[string]any{"query": []any{}}},
+		"components": map[string]any{
+			"schemas": gen.components,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+				"query":  map[string]any{"type": "apiKey", "in": "query", "name": "token"},
+			},
+		},
+	}
+}
+
+// operationID derives a stable id such as getStrategiesId from a route
+func operationID(rt route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(rt.method))
+	for _, part := range strings.Split(strings.TrimPrefix(rt.path, "/api/"), "/") {
+		part = strings.Trim(part, "{}")
+		part = strings.TrimSuffix(part, ".json")
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// schemaGen converts Go types to JSON schemas following encoding/json rules.
+// Named structs become shared components so recursive types terminate.
+type schemaGen struct {
+	components map[string]any
+	names      map[reflect.Type]string
+}
+
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + g.component(t)}
+	default:
+		// Interfaces and anything else accept any JSON value
+		return map[string]any{}
+	}
+}
+
+// component registers a named struct and returns its component name
+func (g *schemaGen) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.components[name]; taken {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	g.names[t] = name
+	g.components[name] = map[string]any{} // placeholder for recursive references
+	g.components[name] = g.object(t)
+	return name
+}
+
+// object builds the schema of a struct's JSON fields
+func (g *schemaGen) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	g.fields(t, props)
+	return map[string]any{"type": "object", "properties": props}
+}
+
+func (g *schemaGen) fields(t reflect.Type, props map[string]any) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// Untagged embedded structs are flattened like encoding/json does
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, props)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+	}
+}
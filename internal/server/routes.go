@@ -0,0 +1,435 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+
+	"terminal/internal/engine"
+	"terminal/internal/events"
+	"terminal/internal/exchange"
+	"terminal/internal/journal"
+	"terminal/internal/risk"
+	"terminal/internal/strategy"
+)
+
+// BacktestRequest runs a backtest. Multi-asset strategies take Symbols
+// instead of Symbol.
+type BacktestRequest struct {
+	StrategyID string                 `json:"strategyId"`
+	Symbol     string                 `json:"symbol,omitempty"`
+	Symbols    []string               `json:"symbols,omitempty"`
+	Interval   string                 `json:"interval"`
+	Limit      int                    `json:"limit"`
+	Params     map[string]any         `json:"params"`
+	Config     engine.ExecutionConfig `json:"config"`
+}
+
+// RunRequest starts a live strategy under ID. Multi-asset strategies take
+// Symbols instead of Symbol.
+type RunRequest struct {
+	ID string `json:"id"`
+	// Account is the trading account id; empty is the main account
+	Account    string                 `json:"account,omitempty"`
+	StrategyID string                 `json:"strategyId"`
+	Symbol     string                 `json:"symbol,omitempty"`
+	Symbols    []string               `json:"symbols,omitempty"`
+	Interval   string                 `json:"interval"`
+	Params     map[string]any         `json:"params"`
+	Config     engine.ExecutionConfig `json:"config"`
+}
+
+// UpdateRequest changes a running strategy at its next bar. Only the given
+// params change; Config replaces the execution config when set.
+type UpdateRequest struct {
+	Params map[string]any          `json:"params"`
+	Config *engine.ExecutionConfig `json:"config,omitempty"`
+}
+
+// KillRequest halts trading; Flatten also stops every running strategy and
+// closes its positions
+type KillRequest struct {
+	Flatten bool `json:"flatten"`
+}
+
+// StatusResponse acknowledges a request without a result
+type StatusResponse struct {
+	Status string `json:"status"`
+}
+
+// WalletResponse holds the wallet address trades are made from and the
+// address of the key signing them, which differs for agent wallets and is
+// empty in read-only mode
+type WalletResponse struct {
+	Address string `json:"address"`
+	Signer  string `json:"signer,omitempty"`
+}
+
+// ErrorResponse is returned with every non-2xx status
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// none marks a route without a request body
+type none struct{}
+
+var ok = StatusResponse{Status: "ok"}
+
+// param documents a path or query parameter
+type param struct {
+	name        string
+	in          string // "path" or "query"
+	kind        string // OpenAPI type: "string", "integer" or "boolean"
+	required    bool
+	description string
+}
+
+// runningParam is the id a live strategy was started with
+var runningParam = param{name: "id", in: "path", kind: "string", required: true, description: "id the strategy was started with"}
+
+// accountParam selects a trading account; omitted is the main account
+var accountParam = param{name: "account", in: "query", kind: "string", description: "account id; defaults to the main account"}
+
+// route is one endpoint. The request and response types are kept so the
+// OpenAPI document can be generated from the same table that serves requests.
+type route struct {
+	method   string
+	path     string
+	summary  string
+	params   []param
+	request  reflect.Type // nil without a body
+	response reflect.Type
+	// stream marks the WebSocket endpoint, whose response is a stream of
+	// response values
+	stream  bool
+	handler http.Handler
+}
+
+// requestError is a client error reported with status 400
+type requestError struct{ err error }
+
+func (e requestError) Error() string { return e.err.Error() }
+
+func badRequest(format string, args ...any) error {
+	return requestError{fmt.Errorf(format, args...)}
+}
+
+// jsonRoute builds a route whose handler decodes Req from the body, calls fn
+// and encodes the result
+func jsonRoute[Req, Resp any](method, path, summary string, params []param, fn func(*http.Request, Req) (Resp, error)) route {
+	rt := route{
+		method:   method,
+		path:     path,
+		summary:  summary,
+		params:   params,
+		response: reflect.TypeFor[Resp](),
+	}
+	if reqType := reflect.TypeFor[Req](); reqType != reflect.TypeFor[none]() {
+		rt.request = reqType
+	}
+
+	rt.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		if rt.request != nil {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+				return
+			}
+		}
+		resp, err := fn(r, req)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.As(err, &requestError{}) {
+				status = http.StatusBadRequest
+			}
+			writeError(w, status, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+	return rt
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("API: failed to encode response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+// queryInt parses an optional integer query parameter
+func queryInt(r *http.Request, name string, def int64) (int64, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, badRequest("invalid %s: %q", name, raw)
+	}
+	return v, nil
+}
+
+func (s *Server) buildRoutes() []route {
+	routes := []route{
+		jsonRoute("GET", "/api/strategies", "List registered strategies", nil,
+			func(r *http.Request, _ none) ([]strategy.Metadata, error) {
+				return s.api.GetAvailableStrategies(), nil
+			}),
+		jsonRoute("GET", "/api/strategies/{id}", "Describe a strategy and its parameters",
+			[]param{{name: "id", in: "path", kind: "string", required: true, description: "strategy id"}},
+			func(r *http.Request, _ none) (*strategy.Metadata, error) {
+				meta, err := s.api.GetStrategyParams(r.PathValue("id"))
+				if err != nil {
+					return nil, requestError{err}
+				}
+				return meta, nil
+			}),
+		jsonRoute("GET", "/api/candles", "Fetch historical candles",
+			[]param{
+				{name: "symbol", in: "query", kind: "string", required: true, description: "coin, e.g. BTC"},
+				{name: "interval", in: "query", kind: "string", description: "candle interval; defaults to 1h"},
+				{name: "limit", in: "query", kind: "integer", description: "number of candles; defaults to 500"},
+				{name: "before", in: "query", kind: "integer", description: "only candles opening before this unix millisecond timestamp"},
+			},
+			s.candles),
+		jsonRoute("POST", "/api/backtest", "Backtest a strategy", nil, s.backtest),
+		jsonRoute("GET", "/api/running", "List running strategies", nil,
+			func(r *http.Request, _ none) ([]engine.RunningStrategyInfo, error) {
+				return s.api.GetRunningStrategies(), nil
+			}),
+		jsonRoute("POST", "/api/running", "Start a live strategy", nil, s.run),
+		jsonRoute("DELETE", "/api/running", "Stop all strategies, closing their positions, and report the PnL each realized", nil,
+			func(r *http.Request, _ none) ([]engine.StopSummary, error) {
+				return s.api.FlattenAllStrategies(), nil
+			}),
+		jsonRoute("DELETE", "/api/running/{id}", "Stop a running strategy",
+			[]param{runningParam, {name: "keepPosition", in: "query", kind: "boolean", description: "leave the position open"}},
+			func(r *http.Request, _ none) (StatusResponse, error) {
+				keep := r.URL.Query().Get("keepPosition") == "true"
+				if err := s.api.StopLiveStrategy(r.PathValue("id"), keep); err != nil {
+					return StatusResponse{}, requestError{err}
+				}
+				return ok, nil
+			}),
+		jsonRoute("PATCH", "/api/running/{id}", "Update params or config of a running strategy at its next bar",
+			[]param{runningParam},
+			func(r *http.Request, req UpdateRequest) (StatusResponse, error) {
+				if err := s.api.UpdateLiveStrategy(r.PathValue("id"), req.Params, req.Config); err != nil {
+					return StatusResponse{}, requestError{err}
+				}
+				return ok, nil
+			}),
+		jsonRoute("POST", "/api/running/{id}/pause", "Ignore signals, keeping the position and its TP/SL",
+			[]param{runningParam},
+			func(r *http.Request, _ none) (StatusResponse, error) {
+				if err := s.api.PauseLiveStrategy(r.PathValue("id")); err != nil {
+					return StatusResponse{}, requestError{err}
+				}
+				return ok, nil
+			}),
+		jsonRoute("POST", "/api/running/{id}/resume", "Act on signals again",
+			[]param{runningParam},
+			func(r *http.Request, _ none) (StatusResponse, error) {
+				if err := s.api.ResumeLiveStrategy(r.PathValue("id")); err != nil {
+					return StatusResponse{}, requestError{err}
+				}
+				return ok, nil
+			}),
+		jsonRoute("GET", "/api/running/{id}/changes", "List params and config updates of a running strategy",
+			[]param{runningParam},
+			func(r *http.Request, _ none) ([]engine.ParamChange, error) {
+				changes, err := s.api.GetStrategyChanges(r.PathValue("id"))
+				if err != nil {
+					return nil, requestError{err}
+				}
+				return changes, nil
+			}),
+		jsonRoute("GET", "/api/running/{id}/history", "Get the trades and PnL ledger of a running strategy, with fees, funding and unrealized PnL",
+			[]param{runningParam},
+			func(r *http.Request, _ none) (*engine.StrategyHistory, error) {
+				history, err := s.api.GetStrategyHistory(r.PathValue("id"))
+				if err != nil {
+					return nil, requestError{err}
+				}
+				return history, nil
+			}),
+		jsonRoute("GET", "/api/wallet", "Get the wallet address", nil,
+			func(r *http.Request, _ none) (WalletResponse, error) {
+				return WalletResponse{Address: s.api.GetWalletAddress(), Signer: s.api.GetSignerAddress()}, nil
+			}),
+		jsonRoute("GET", "/api/accounts", "List trading accounts", nil,
+			func(r *http.Request, _ none) ([]exchange.AccountInfo, error) {
+				return s.api.GetAccounts(), nil
+			}),
+		jsonRoute("GET", "/api/portfolio", "Get balances and open positions of an account",
+			[]param{accountParam},
+			func(r *http.Request, _ none) (*exchange.PortfolioSummary, error) {
+				return s.api.GetPortfolioSummary(r.URL.Query().Get("account"))
+			}),
+		jsonRoute("GET", "/api/portfolio/all", "Get balances and positions aggregated across accounts", nil,
+			func(r *http.Request, _ none) (exchange.AggregatedPortfolio, error) {
+				return s.api.GetAggregatedPortfolio(), nil
+			}),
+		jsonRoute("GET", "/api/positions", "List open exchange positions of an account",
+			[]param{accountParam},
+			func(r *http.Request, _ none) ([]exchange.ActivePosition, error) {
+				return s.api.GetActivePositions(r.URL.Query().Get("account"))
+			}),
+		jsonRoute("GET", "/api/risk", "Get exposure, PnL and risk limits", nil,
+			func(r *http.Request, _ none) (risk.Status, error) {
+				return s.api.GetRiskStatus(), nil
+			}),
+		jsonRoute("POST", "/api/risk/kill", "Halt trading, optionally stopping all strategies", nil,
+			func(r *http.Request, req KillRequest) (StatusResponse, error) {
+				s.api.KillSwitch(req.Flatten)
+				return ok, nil
+			}),
+		jsonRoute("POST", "/api/risk/resume", "Resume trading after a halt", nil,
+			func(r *http.Request, _ none) (StatusResponse, error) {
+				s.api.ResumeTrading()
+				return ok, nil
+			}),
+		jsonRoute("DELETE", "/api/cache", "Clear the candle cache",
+			[]param{{name: "symbol", in: "query", kind: "string", description: "only clear this symbol"}},
+			func(r *http.Request, _ none) (StatusResponse, error) {
+				var err error
+				if symbol := r.URL.Query().Get("symbol"); symbol != "" {
+					err = s.api.InvalidateCacheForSymbol(symbol)
+				} else {
+					err = s.api.InvalidateCache()
+				}
+				if err != nil {
+					return StatusResponse{}, err
+				}
+				return ok, nil
+			}),
+		jsonRoute("GET", "/api/journal", "Query the trade journal",
+			[]param{
+				{name: "strategy", in: "query", kind: "string", description: "live strategy id"},
+				{name: "symbol", in: "query", kind: "string", description: "coin, e.g. BTC"},
+				{name: "kinds", in: "query", kind: "string", description: "comma-separated entry kinds: signal, order, fill, open, update, exit, halt"},
+				{name: "from", in: "query", kind: "integer", description: "earliest entry time in unix milliseconds"},
+				{name: "to", in: "query", kind: "integer", description: "latest entry time in unix milliseconds"},
+				{name: "limit", in: "query", kind: "integer", description: "only return the most recent entries"},
+			},
+			s.journal),
+		jsonRoute("POST", "/api/divergence", "Compare the journaled trades of a live strategy with a backtest of the period it ran", nil,
+			func(r *http.Request, req engine.DivergenceConfig) (*engine.DivergenceReport, error) {
+				if req.ID == "" {
+					return nil, badRequest("id is required")
+				}
+				return s.api.StrategyDivergence(req)
+			}),
+		{
+			method:  "GET",
+			path:    "/api/events",
+			summary: "Stream live events over WebSocket",
+			params: []param{{name: "types", in: "query", kind: "string",
+				description: "comma-separated event types to receive, e.g. orderFilled,positionClosed; defaults to all"}},
+			response: reflect.TypeFor[events.Event](),
+			stream:   true,
+			handler:  http.HandlerFunc(s.streamEvents),
+		},
+	}
+
+	// The document describes itself, so it is built from the table above
+	// plus its own entry
+	routes = append(routes, jsonRoute("GET", "/api/openapi.json", "Get this OpenAPI document", nil,
+		func(r *http.Request, _ none) (map[string]any, error) {
+			return s.openAPI(), nil
+		}))
+	return routes
+}
+
+func (s *Server) candles(r *http.Request, _ none) (hyperliquid.Candles, error) {
+	q := r.URL.Query()
+	symbol := q.Get("symbol")
+	if symbol == "" {
+		return nil, badRequest("symbol is required")
+	}
+	interval := q.Get("interval")
+	if interval == "" {
+		interval = "1h"
+	}
+	limit, err := queryInt(r, "limit", 500)
+	if err != nil {
+		return nil, err
+	}
+	before, err := queryInt(r, "before", 0)
+	if err != nil {
+		return nil, err
+	}
+	if before > 0 {
+		return s.api.FetchCandlesBefore(symbol, interval, int(limit), before)
+	}
+	return s.api.FetchCandles(symbol, interval, int(limit))
+}
+
+func (s *Server) journal(r *http.Request, _ none) ([]journal.Entry, error) {
+	q := r.URL.Query()
+	filter := journal.Filter{Strategy: q.Get("strategy"), Symbol: q.Get("symbol")}
+	for _, kind := range strings.Split(q.Get("kinds"), ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			filter.Kinds = append(filter.Kinds, journal.Kind(kind))
+		}
+	}
+	var err error
+	if filter.From, err = queryInt(r, "from", 0); err != nil {
+		return nil, err
+	}
+	if filter.To, err = queryInt(r, "to", 0); err != nil {
+		return nil, err
+	}
+	limit, err := queryInt(r, "limit", 0)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = int(limit)
+	return s.api.QueryJournal(filter)
+}
+
+func (s *Server) backtest(r *http.Request, req BacktestRequest) (*engine.BacktestResult, error) {
+	if req.StrategyID == "" || req.Interval == "" {
+		return nil, badRequest("strategyId and interval are required")
+	}
+	if len(req.Symbols) > 0 {
+		return s.api.StrategyBacktestBasket(req.StrategyID, req.Symbols, req.Interval, req.Limit, req.Params, req.Config)
+	}
+	if req.Symbol == "" {
+		return nil, badRequest("symbol or symbols is required")
+	}
+	return s.api.StrategyBacktest(req.StrategyID, req.Symbol, req.Interval, req.Limit, req.Params, req.Config)
+}
+
+func (s *Server) run(r *http.Request, req RunRequest) (StatusResponse, error) {
+	if req.ID == "" || req.StrategyID == "" || req.Interval == "" {
+		return StatusResponse{}, badRequest("id, strategyId and interval are required")
+	}
+	var err error
+	switch {
+	case len(req.Symbols) > 0:
+		err = s.api.StrategyRunBasket(req.ID, req.Account, req.StrategyID, req.Symbols, req.Interval, req.Params, req.Config)
+	case req.Symbol != "":
+		err = s.api.StrategyRun(req.ID, req.Account, req.StrategyID, req.Symbol, req.Interval, req.Params, req.Config)
+	default:
+		return StatusResponse{}, badRequest("symbol or symbols is required")
+	}
+	if err != nil {
+		return StatusResponse{}, requestError{err}
+	}
+	return ok, nil
+}
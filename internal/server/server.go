@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+
+	"terminal/internal/engine"
+	"terminal/internal/events"
+	"terminal/internal/exchange"
+	"terminal/internal/journal"
+	"terminal/internal/risk"
+	"terminal/internal/strategy"
+)
+
+// API is the subset of the App bindings the server exposes
+type API interface {
+	GetAvailableStrategies() []strategy.Metadata
+	GetStrategyParams(strategyID string) (*strategy.Metadata, error)
+	FetchCandles(symbol string, interval string, limit int) (hyperliquid.Candles, error)
+	FetchCandlesBefore(symbol string, interval string, limit int, beforeTimestamp int64) (hyperliquid.Candles, error)
+	StrategyRun(id string, account string, strategyID string, symbol string, interval string, params map[string]any, config engine.ExecutionConfig) error
+	StrategyRunBasket(id string, account string, strategyID string, symbols []string, interval string, params map[string]any, config engine.ExecutionConfig) error
+	StrategyBacktest(strategyID string, symbol string, interval string, limit int, params map[string]any, config engine.ExecutionConfig) (*engine.BacktestResult, error)
+	StrategyBacktestBasket(strategyID string, symbols []string, interval string, limit int, params map[string]any, config engine.ExecutionConfig) (*engine.BacktestResult, error)
+	GetRunningStrategies() []engine.RunningStrategyInfo
+	StopLiveStrategy(name string, keepPosition bool) error
+	FlattenAllStrategies() []engine.StopSummary
+	PauseLiveStrategy(id string) error
+	ResumeLiveStrategy(id string) error
+	UpdateLiveStrategy(id string, params map[string]any, config *engine.ExecutionConfig) error
+	GetStrategyChanges(id string) ([]engine.ParamChange, error)
+	GetStrategyHistory(id string) (*engine.StrategyHistory, error)
+	GetWalletAddress() string
+	GetSignerAddress() string
+	GetAccounts() []exchange.AccountInfo
+	GetPortfolioSummary(account string) (*exchange.PortfolioSummary, error)
+	GetActivePositions(account string) ([]exchange.ActivePosition, error)
+	GetAggregatedPortfolio() exchange.AggregatedPortfolio
+	GetRiskStatus() risk.Status
+	KillSwitch(flatten bool)
+	ResumeTrading()
+	InvalidateCache() error
+	InvalidateCacheForSymbol(symbol string) error
+	QueryJournal(filter journal.Filter) ([]journal.Entry, error)
+	StrategyDivergence(cfg engine.DivergenceConfig) (*engine.DivergenceReport, error)
+}
+
+// Server serves the API over HTTP and streams live events over WebSocket.
+// Every request must carry the token, and the listener only accepts
+// loopback addresses.
+type Server struct {
+	api    API
+	bus    *events.Bus
+	token  string
+	routes []route
+	http   *http.Server
+	// done ends open event streams, which Shutdown does not track
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+var errNoEvents = errors.New("event streaming is not enabled")
+
+// New creates a server for api. Events published on bus are streamed to
+// WebSocket clients; a nil bus streams nothing.
+func New(api API, bus *events.Bus, token string) *Server {
+	s := &Server{api: api, bus: bus, token: token, done: make(chan struct{})}
+	s.routes = s.buildRoutes()
+	return s
+}
+
+// Handler returns the HTTP handler with authentication applied
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for _, rt := range s.routes {
+		mux.Handle(rt.method+" "+rt.path, rt.handler)
+	}
+	return s.authenticate(mux)
+}
+
+// Start listens on addr and serves in the background. addr must resolve to
+// a loopback address.
+func (s *Server) Start(addr string) error {
+	if s.token == "" {
+		return fmt.Errorf("api server needs a token")
+	}
+	if err := checkLoopback(addr); err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("api server: %w", err)
+	}
+
+	s.http = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := s.http.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("API server stopped", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops the server and closes open event streams. It may be
+// called more than once.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.http == nil {
+		return nil
+	}
+	s.stopOnce.Do(func() { close(s.done) })
+	return s.http.Shutdown(ctx)
+}
+
+// checkLoopback rejects listen addresses reachable from other machines
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid api address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("api address %q is not a loopback address", addr)
+}
+
+// authenticate requires the token as a bearer token, or as the token query
+// parameter for clients that cannot set headers on WebSocket requests
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			var isBearer bool
+			if token, isBearer = strings.CutPrefix(auth, "Bearer "); !isBearer {
+				token = ""
+			}
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid or missing token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
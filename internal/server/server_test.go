@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"terminal/internal/strategy"
+)
+
+const testToken = "secret"
+
+// fakeAPI serves the few calls the tests make; any other call panics
+type fakeAPI struct {
+	API
+}
+
+func (fakeAPI) GetAvailableStrategies() []strategy.Metadata {
+	return []strategy.Metadata{{ID: "test-strategy"}}
+}
+
+func serve(t *testing.T, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	New(fakeAPI{}, nil, testToken).Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func bearer(token string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+func TestTokenRequired(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		header http.Header
+		status int
+	}{
+		{name: "missing", target: "/api/strategies", status: http.StatusUnauthorized},
+		{name: "wrong bearer", target: "/api/strategies", header: bearer("guess"), status: http.StatusUnauthorized},
+		{name: "not a bearer", target: "/api/strategies", header: http.Header{"Authorization": {testToken}}, status: http.StatusUnauthorized},
+		{name: "wrong query", target: "/api/strategies?token=guess", status: http.StatusUnauthorized},
+		{name: "header overrides query", target: "/api/strategies?token=" + testToken, header: bearer("guess"), status: http.StatusUnauthorized},
+		{name: "bearer", target: "/api/strategies", header: bearer(testToken), status: http.StatusOK},
+		{name: "query", target: "/api/strategies?token=" + testToken, status: http.StatusOK},
+		{name: "unknown route", target: "/api/nothing", header: bearer(testToken), status: http.StatusNotFound},
+		{name: "unknown route without token", target: "/api/nothing", status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, "GET", tt.target, "", tt.header)
+			if rec.Code != tt.status {
+				t.Errorf("status %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	s := New(fakeAPI{}, nil, testToken)
+	seen := map[string]bool{}
+	for _, rt := range s.routes {
+		key := rt.method + " " + rt.path
+		if seen[key] {
+			t.Errorf("route %s registered twice", key)
+		}
+		seen[key] = true
+		if rt.handler == nil || rt.summary == "" || rt.response == nil {
+			t.Errorf("route %s lacks a handler, summary or response type", key)
+		}
+		for _, p := range rt.params {
+			if p.in == "path" && !strings.Contains(rt.path, "{"+p.name+"}") {
+				t.Errorf("route %s documents path parameter %s it does not have", key, p.name)
+			}
+		}
+	}
+
+	rec := serve(t, "GET", "/api/strategies", "", bearer(testToken))
+	var strategies []strategy.Metadata
+	if err := json.NewDecoder(rec.Body).Decode(&strategies); err != nil || len(strategies) != 1 || strategies[0].ID != "test-strategy" {
+		t.Errorf("strategies %+v, %v", strategies, err)
+	}
+
+	// Client errors are reported as such with an error body
+	for _, body := range []string{"{", `{"strategyId": "test-strategy"}`} {
+		rec := serve(t, "POST", "/api/divergence", body, bearer(testToken))
+		var resp ErrorResponse
+		if rec.Code != http.StatusBadRequest || json.NewDecoder(rec.Body).Decode(&resp) != nil || resp.Error == "" {
+			t.Errorf("divergence with body %s: status %d, error %q", body, rec.Code, resp.Error)
+		}
+	}
+
+	if rec := serve(t, "GET", "/api/events", "", bearer(testToken)); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("event stream without a bus: status %d", rec.Code)
+	}
+}
+
+func TestOpenAPIDescribesEveryRoute(t *testing.T) {
+	rec := serve(t, "GET", "/api/openapi.json", "", bearer(testToken))
+	var doc struct {
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := map[string]bool{}
+	for _, rt := range New(fakeAPI{}, nil, testToken).routes {
+		op, ok := doc.Paths[rt.path][strings.ToLower(rt.method)]
+		if !ok {
+			t.Errorf("%s %s missing from the document", rt.method, rt.path)
+			continue
+		}
+		id, _ := op["operationId"].(string)
+		if id == "" || ids[id] {
+			t.Errorf("%s %s has an empty or duplicate operation id %q", rt.method, rt.path, id)
+		}
+		ids[id] = true
+		if _, hasBody := op["requestBody"]; hasBody != (rt.request != nil) {
+			t.Errorf("%s %s request body documented %v", rt.method, rt.path, hasBody)
+		}
+	}
+
+	// Referenced components are all defined
+	var refs []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				refs = append(refs, strings.TrimPrefix(ref, "#/components/schemas/"))
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	for _, item := range doc.Paths {
+		for _, op := range item {
+			walk(op)
+		}
+	}
+	for _, schema := range doc.Components.Schemas {
+		walk(schema)
+	}
+	for _, ref := range refs {
+		if _, ok := doc.Components.Schemas[ref]; !ok {
+			t.Errorf("schema %s is referenced but not defined", ref)
+		}
+	}
+	if _, ok := doc.Components.Schemas["BacktestRequest"]; !ok {
+		t.Error("BacktestRequest schema missing")
+	}
+}
+
+func TestShutdownTwice(t *testing.T) {
+	s := New(fakeAPI{}, nil, testToken)
+	if err := s.Start("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := s.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStartRejectsRemoteAddresses(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:0", ":0", "192.168.1.2:0", "example.com:80"} {
+		if err := New(fakeAPI{}, nil, testToken).Start(addr); err == nil {
+			t.Errorf("started on %s", addr)
+		}
+	}
+	if err := New(fakeAPI{}, nil, "").Start("127.0.0.1:0"); err == nil {
+		t.Error("started without a token")
+	}
+}
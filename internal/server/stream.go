@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"terminal/internal/events"
+)
+
+const (
+	streamBuffer = 256
+	pingInterval = 30 * time.Second
+	writeTimeout = 10 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	// Browsers send an Origin header; only pages served from this machine
+	// may open a stream. Tools that send no Origin are allowed.
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		if u.Hostname() == "localhost" || u.Hostname() == "wails.localhost" {
+			return true
+		}
+		ip := net.ParseIP(u.Hostname())
+		return ip != nil && ip.IsLoopback()
+	},
+}
+
+// streamEvents upgrades to a WebSocket and writes each published event as
+// a JSON message until the client disconnects
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	if s.bus == nil {
+		writeError(w, http.StatusServiceUnavailable, errNoEvents)
+		return
+	}
+	kinds := map[events.Kind]bool{}
+	for _, k := range strings.Split(r.URL.Query().Get("types"), ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			kinds[events.Kind(k)] = true
+		}
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written the error response
+		return
+	}
+	defer conn.Close()
+
+	stream, unsubscribe := s.bus.Subscribe(streamBuffer)
+	defer unsubscribe()
+
+	// Clients only send control frames; reading detects when they go away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-s.done:
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+				time.Now().Add(writeTimeout))
+			return
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case e := <-stream:
+			if len(kinds) > 0 && !kinds[e.Kind] {
+				continue
+			}
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		}
+	}
+}
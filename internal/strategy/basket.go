@@ -0,0 +1,114 @@
+package strategy
+
+import (
+	"sort"
+
+	"terminal/internal/exchange"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// Basket holds candles for several symbols aligned on a common timeline.
+// Candles[symbol][i] for every symbol belongs to the bar closing at Times[i].
+type Basket struct {
+	Interval string
+	Symbols  []string
+	Times    []int64
+	Candles  map[string][]hyperliquid.Candle
+}
+
+// NewBasket aligns the series in candles on the close timestamps present in
+// every symbol. Bars missing from any symbol are dropped so that index i
+// always refers to the same moment across legs.
+func NewBasket(interval string, symbols []string, candles map[string][]hyperliquid.Candle) *Basket {
+	counts := make(map[int64]int)
+	for _, symbol := range symbols {
+		seen := make(map[int64]bool)
+		for _, c := range candles[symbol] {
+			if !seen[c.Timestamp] {
+				seen[c.Timestamp] = true
+				counts[c.Timestamp]++
+			}
+		}
+	}
+
+	times := make([]int64, 0, len(counts))
+	for ts, n := range counts {
+		if n == len(symbols) {
+			times = append(times, ts)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	index := make(map[int64]int, len(times))
+	for i, ts := range times {
+		index[ts] = i
+	}
+
+	aligned := make(map[string][]hyperliquid.Candle, len(symbols))
+	for _, symbol := range symbols {
+		series := make([]hyperliquid.Candle, len(times))
+		for _, c := range candles[symbol] {
+			if i, ok := index[c.Timestamp]; ok {
+				series[i] = c
+			}
+		}
+		aligned[symbol] = series
+	}
+
+	return &Basket{
+		Interval: interval,
+		Symbols:  symbols,
+		Times:    times,
+		Candles:  aligned,
+	}
+}
+
+// Len returns the number of aligned bars
+func (b *Basket) Len() int {
+	return len(b.Times)
+}
+
+// Slice returns the basket without its first start bars
+func (b *Basket) Slice(start int) *Basket {
+	start = min(max(start, 0), b.Len())
+	candles := make(map[string][]hyperliquid.Candle, len(b.Candles))
+	for symbol, series := range b.Candles {
+		candles[symbol] = series[start:]
+	}
+	return &Basket{
+		Interval: b.Interval,
+		Symbols:  b.Symbols,
+		Times:    b.Times[start:],
+		Candles:  candles,
+	}
+}
+
+// Head returns the basket as it looked when the first bars bars had closed
+func (b *Basket) Head(bars int) *Basket {
+	bars = min(max(bars, 0), b.Len())
+	candles := make(map[string][]hyperliquid.Candle, len(b.Candles))
+	for symbol, series := range b.Candles {
+		candles[symbol] = series[:bars]
+	}
+	return &Basket{
+		Interval: b.Interval,
+		Symbols:  b.Symbols,
+		Times:    b.Times[:bars],
+		Candles:  candles,
+	}
+}
+
+// MultiAssetStrategy is implemented by strategies that trade several symbols
+// at once (pairs, spreads, baskets). Every signal must set Symbol to one of
+// the basket's symbols; signals sharing an Index are executed together as
+// one multi-leg order.
+type MultiAssetStrategy interface {
+	Strategy
+
+	// GenerateBasketSignals generates per-symbol signals from aligned candles
+	GenerateBasketSignals(basket *Basket) []exchange.Signal
+
+	// GetBasketVisualization returns chart overlays for the basket
+	GetBasketVisualization(basket *Basket) *Visualization
+}
@@ -0,0 +1,9 @@
+// Package builtin registers every strategy implementation shipped with the
+// terminal. Import it for its side effects; new strategy packages are added
+// here so the app and the strategy test suite pick them up together.
+package builtin
+
+import (
+	_ "terminal/internal/strategy/maxtrend"
+	_ "terminal/internal/strategy/pairs"
+)
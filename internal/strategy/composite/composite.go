@@ -0,0 +1,458 @@
+package composite
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"terminal/internal/exchange"
+	"terminal/internal/logging"
+	"terminal/internal/strategy"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// Combination modes
+const (
+	// ModeFilter passes the first child's signals through, but only takes
+	// entries when every other child is positioned on the same side
+	ModeFilter = "filter"
+	// ModeMajority is long or short while more than half the children are
+	ModeMajority = "majority"
+	// ModeUnanimous is long or short only while all children agree
+	ModeUnanimous = "unanimous"
+)
+
+// paramSeparator joins a child alias and its parameter name
+const paramSeparator = "."
+
+// Child references a registered strategy used inside a composite
+type Child struct {
+	// Alias namespaces the child's parameters, e.g. "trend" -> "trend.factor"
+	Alias      string         `json:"alias"`
+	StrategyID string         `json:"strategyId"`
+	Params     map[string]any `json:"params,omitempty"`
+	// Interval runs the child on a different timeframe, e.g. "4h" to confirm
+	// a 15m entry. Empty means the composite's own interval.
+	Interval string `json:"interval,omitempty"`
+}
+
+// Spec declares a composite strategy without writing Go
+type Spec struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Version     string  `json:"version"`
+	Description string  `json:"description"`
+	Mode        string  `json:"mode"`
+	Children    []Child `json:"children"`
+}
+
+// Validate checks the spec is well formed and all children are registered
+func (s Spec) Validate() error {
+	if s.ID == "" {
+		return fmt.Errorf("composite id is required")
+	}
+	// The id names the composite's file in the store
+	if strings.ContainsAny(s.ID, `/\`) || strings.Contains(s.ID, "..") {
+		return fmt.Errorf("invalid composite id %q", s.ID)
+	}
+	switch s.Mode {
+	case ModeFilter, ModeMajority, ModeUnanimous:
+	default:
+		return fmt.Errorf("unknown composite mode %q", s.Mode)
+	}
+	if len(s.Children) < 2 {
+		return fmt.Errorf("composite needs at least 2 children, got %d", len(s.Children))
+	}
+
+	if s.Mode == ModeFilter && s.Children[0].Interval != "" {
+		return fmt.Errorf("the primary child of a filter composite must use the composite's interval")
+	}
+
+	aliases := make(map[string]bool, len(s.Children))
+	for _, child := range s.Children {
+		if child.Alias == "" || strings.Contains(child.Alias, paramSeparator) {
+			return fmt.Errorf("invalid child alias %q", child.Alias)
+		}
+		if aliases[child.Alias] {
+			return fmt.Errorf("duplicate child alias %q", child.Alias)
+		}
+		aliases[child.Alias] = true
+
+		if child.StrategyID == s.ID {
+			return fmt.Errorf("composite %s cannot contain itself", s.ID)
+		}
+		if !strategy.Has(child.StrategyID) {
+			return fmt.Errorf("child %s: strategy not found: %s", child.Alias, child.StrategyID)
+		}
+	}
+	return nil
+}
+
+// Strategy combines the signals of several registered strategies
+type Strategy struct {
+	spec     Spec
+	children []strategy.Strategy
+}
+
+// New creates a composite from spec, instantiating each child from the registry
+func New(spec Spec) (*Strategy, error) {
+	children := make([]strategy.Strategy, len(spec.Children))
+	for i, child := range spec.Children {
+		strat, err := strategy.Get(child.StrategyID)
+		if err != nil {
+			return nil, fmt.Errorf("child %s: %w", child.Alias, err)
+		}
+		if _, ok := strat.(strategy.MultiAssetStrategy); ok {
+			return nil, fmt.Errorf("child %s: multi-asset strategies cannot be composed", child.Alias)
+		}
+		children[i] = strat
+	}
+	return &Strategy{spec: spec, children: children}, nil
+}
+
+// Spec returns the composite's definition
+func (s *Strategy) Spec() Spec {
+	return s.spec
+}
+
+// GetMetadata returns metadata with every child parameter namespaced by alias
+func (s *Strategy) GetMetadata() strategy.Metadata {
+	meta := strategy.Metadata{
+		ID:          s.spec.ID,
+		Name:        s.spec.Name,
+		Version:     s.spec.Version,
+		Description: s.spec.Description,
+		Parameters:  []strategy.ParameterDef{},
+	}
+
+	feeds := make(map[string]strategy.FeedDef)
+	addFeed := func(feed strategy.FeedDef) {
+		key := feed.Symbol + "@" + feed.Interval
+		if existing, ok := feeds[key]; ok {
+			feed.WarmupBars = max(feed.WarmupBars, existing.WarmupBars)
+		}
+		feeds[key] = feed
+	}
+	for i, strat := range s.children {
+		child := s.spec.Children[i]
+		childMeta := strat.GetMetadata()
+		for _, def := range childMeta.Parameters {
+			if value, ok := child.Params[def.Name]; ok {
+				def.DefaultValue = value
+			}
+			def.Name = child.Alias + paramSeparator + def.Name
+			def.Label = childMeta.Name + ": " + def.Label
+			meta.Parameters = append(meta.Parameters, def)
+		}
+
+		if child.Interval != "" {
+			addFeed(strategy.FeedDef{Interval: child.Interval, WarmupBars: childMeta.WarmupBars})
+			continue
+		}
+		for _, feed := range childMeta.Feeds {
+			addFeed(feed)
+		}
+		meta.WarmupBars = max(meta.WarmupBars, childMeta.WarmupBars)
+		meta.Lookback = max(meta.Lookback, childMeta.Lookback)
+	}
+	for _, feed := range feeds {
+		meta.Feeds = append(meta.Feeds, feed)
+	}
+	sort.Slice(meta.Feeds, func(i, j int) bool {
+		return meta.Feeds[i].Symbol+meta.Feeds[i].Interval < meta.Feeds[j].Symbol+meta.Feeds[j].Interval
+	})
+	return meta
+}
+
+// ValidateParams validates each child's namespaced parameters
+func (s *Strategy) ValidateParams(params map[string]any) error {
+	for i, strat := range s.children {
+		child := s.spec.Children[i]
+		if err := strat.ValidateParams(s.childParams(child, params)); err != nil {
+			return fmt.Errorf("%s: %w", child.Alias, err)
+		}
+	}
+	return nil
+}
+
+// Initialize initializes each child with its namespaced parameters
+func (s *Strategy) Initialize(params map[string]any) error {
+	for i, strat := range s.children {
+		child := s.spec.Children[i]
+		if err := strat.Initialize(s.childParams(child, params)); err != nil {
+			return fmt.Errorf("%s: %w", child.Alias, err)
+		}
+	}
+	return nil
+}
+
+// childParams extracts a child's parameters, layering the spec's values
+// under any namespaced overrides
+func (s *Strategy) childParams(child Child, params map[string]any) map[string]any {
+	result := make(map[string]any, len(child.Params))
+	for name, value := range child.Params {
+		result[name] = value
+	}
+	prefix := child.Alias + paramSeparator
+	for name, value := range params {
+		if strings.HasPrefix(name, prefix) {
+			result[strings.TrimPrefix(name, prefix)] = value
+		}
+	}
+	return result
+}
+
+// GenerateSignals combines child signals on candles without extra feeds
+func (s *Strategy) GenerateSignals(candles []hyperliquid.Candle) []exchange.Signal {
+	return s.GenerateSignalsWithFeeds(strategy.NewDataContext("", "", candles))
+}
+
+// GetVisualization merges child visualizations on candles without extra feeds
+func (s *Strategy) GetVisualization(candles []hyperliquid.Candle) *strategy.Visualization {
+	return s.GetVisualizationWithFeeds(strategy.NewDataContext("", "", candles))
+}
+
+// GenerateSignalsWithFeeds combines child signals according to the spec's mode
+func (s *Strategy) GenerateSignalsWithFeeds(ctx *strategy.DataContext) []exchange.Signal {
+	if len(s.children) == 0 {
+		return nil
+	}
+
+	n := len(ctx.Candles)
+	states := make([][]int, len(s.children))
+	var primary []exchange.Signal
+
+	for i, strat := range s.children {
+		childCtx, feed, err := s.childContext(s.spec.Children[i], ctx)
+		if err != nil {
+			// Without every child there is nothing to combine
+			slog.Warn("composite child has no data", logging.KeyStrategy, s.spec.ID, logging.KeySymbol, ctx.Symbol,
+				"child", s.spec.Children[i].Alias, "error", err)
+			return nil
+		}
+
+		signals := strategy.GenerateSignals(strat, childCtx)
+		if i == 0 {
+			primary = sortedSignals(signals)
+		}
+
+		childStates := positionStates(sortedSignals(signals), len(childCtx.Candles))
+		if feed == nil {
+			states[i] = childStates
+			continue
+		}
+
+		// Map the higher timeframe states onto primary bars without lookahead
+		aligned := make([]int, n)
+		for bar := 0; bar < n; bar++ {
+			if idx := feed.IndexAt(bar); idx >= 0 {
+				aligned[bar] = childStates[idx]
+			}
+		}
+		states[i] = aligned
+	}
+
+	if s.spec.Mode == ModeFilter {
+		return s.filterSignals(primary, states[1:])
+	}
+
+	combined := make([]int, n)
+	for bar := 0; bar < n; bar++ {
+		var longs, shorts int
+		for _, childStates := range states {
+			switch childStates[bar] {
+			case 1:
+				longs++
+			case -1:
+				shorts++
+			}
+		}
+		switch s.spec.Mode {
+		case ModeMajority:
+			if longs*2 > len(states) {
+				combined[bar] = 1
+			} else if shorts*2 > len(states) {
+				combined[bar] = -1
+			}
+		case ModeUnanimous:
+			if longs == len(states) {
+				combined[bar] = 1
+			} else if shorts == len(states) {
+				combined[bar] = -1
+			}
+		}
+	}
+	return stateSignals(combined, ctx.Candles, "Composite "+s.spec.Mode)
+}
+
+// filterSignals passes primary signals through, dropping entries the
+// filters disagree with. A blocked reversal still closes the old position.
+func (s *Strategy) filterSignals(primary []exchange.Signal, filters [][]int) []exchange.Signal {
+	result := []exchange.Signal{}
+	state := 0
+	for _, signal := range primary {
+		switch {
+		case signal.Type.IsEntry():
+			side := sideOf(signal.Type)
+			agreed := true
+			for _, filter := range filters {
+				if signal.Index >= len(filter) || filter[signal.Index] != side {
+					agreed = false
+					break
+				}
+			}
+			if agreed {
+				result = append(result, signal)
+				state = side
+				continue
+			}
+			if state == -side {
+				exit := signal
+				exit.Type = exitFor(state)
+				exit.Reason = "Filtered Reversal"
+				result = append(result, exit)
+				state = 0
+			}
+		case signal.Type == exchange.SignalScaleIn:
+			if state != 0 {
+				result = append(result, signal)
+			}
+		default:
+			if state != 0 && signal.Type.Closes(sideName(state)) {
+				result = append(result, signal)
+				if signal.ExitFraction() >= 1 {
+					state = 0
+				}
+			}
+		}
+	}
+	return result
+}
+
+// GetVisualizationWithFeeds merges the visualizations of children on the
+// composite's own interval. Child series and panes are namespaced by alias.
+func (s *Strategy) GetVisualizationWithFeeds(ctx *strategy.DataContext) *strategy.Visualization {
+	var merged *strategy.Visualization
+	for i, strat := range s.children {
+		child := s.spec.Children[i]
+		if child.Interval != "" {
+			continue
+		}
+		vis := strategy.GetVisualization(strat, ctx)
+		if vis == nil {
+			continue
+		}
+		if merged == nil {
+			merged = strategy.NewVisualization()
+		}
+		merged.Merge(child.Alias, vis)
+	}
+	return merged
+}
+
+// childContext returns the data context a child runs on, and the feed used
+// to align it when the child has its own interval
+func (s *Strategy) childContext(child Child, ctx *strategy.DataContext) (*strategy.DataContext, *strategy.Feed, error) {
+	if child.Interval == "" {
+		return ctx, nil, nil
+	}
+	feed, err := ctx.Feed("", child.Interval)
+	if err != nil {
+		return nil, nil, err
+	}
+	return strategy.NewDataContext(ctx.Symbol, child.Interval, feed.Candles), feed, nil
+}
+
+// positionStates replays signals into a per-bar state: 1 long, -1 short, 0 flat
+func positionStates(signals []exchange.Signal, n int) []int {
+	states := make([]int, n)
+	state := 0
+	next := 0
+	for i := 0; i < n; i++ {
+		for next < len(signals) && signals[next].Index <= i {
+			signal := signals[next]
+			next++
+			switch {
+			case signal.Type.IsEntry():
+				state = sideOf(signal.Type)
+			case signal.Type == exchange.SignalScaleIn:
+			case state != 0 && signal.Type.Closes(sideName(state)) && signal.ExitFraction() >= 1:
+				state = 0
+			}
+		}
+		states[i] = state
+	}
+	return states
+}
+
+// stateSignals converts a per-bar state series back into signals
+func stateSignals(states []int, candles []hyperliquid.Candle, reason string) []exchange.Signal {
+	signals := []exchange.Signal{}
+	prev := 0
+	for i, state := range states {
+		if state == prev {
+			continue
+		}
+		signalType := exitFor(prev)
+		if state == 1 {
+			signalType = exchange.SignalLong
+		} else if state == -1 {
+			signalType = exchange.SignalShort
+		}
+		signals = append(signals, exchange.Signal{
+			Index:  i,
+			Type:   signalType,
+			Price:  parseFloat(candles[i].Close),
+			Time:   candles[i].Timestamp,
+			Reason: reason,
+		})
+		prev = state
+	}
+	return signals
+}
+
+func sortedSignals(signals []exchange.Signal) []exchange.Signal {
+	sorted := append([]exchange.Signal(nil), signals...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+	return sorted
+}
+
+func sideOf(t exchange.SignalType) int {
+	switch t {
+	case exchange.SignalLong:
+		return 1
+	case exchange.SignalShort:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func sideName(state int) string {
+	switch state {
+	case 1:
+		return "long"
+	case -1:
+		return "short"
+	default:
+		return ""
+	}
+}
+
+func exitFor(state int) exchange.SignalType {
+	if state == 1 {
+		return exchange.SignalExitLong
+	}
+	return exchange.SignalExitShort
+}
+
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// Verify Strategy implements the interface
+var _ strategy.FeedStrategy = (*Strategy)(nil)
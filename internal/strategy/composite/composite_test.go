@@ -0,0 +1,111 @@
+package composite
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"terminal/internal/exchange"
+	"terminal/internal/strategy"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// scripted is a strategy that holds the given position on each bar:
+// 1 long, -1 short, 0 flat
+type scripted []int
+
+func (scripted) GetMetadata() strategy.Metadata {
+	return strategy.Metadata{Name: "Scripted", Version: "1", Parameters: []strategy.ParameterDef{}}
+}
+
+func (scripted) ValidateParams(map[string]any) error { return nil }
+
+func (scripted) Initialize(map[string]any) error { return nil }
+
+func (s scripted) GenerateSignals(candles []hyperliquid.Candle) []exchange.Signal {
+	return stateSignals(s[:len(candles)], candles, "Scripted")
+}
+
+func (scripted) GetVisualization([]hyperliquid.Candle) *strategy.Visualization {
+	return strategy.NewVisualization()
+}
+
+// registerScripted registers a scripted strategy for the duration of the test
+func registerScripted(t *testing.T, id string, states ...int) {
+	t.Helper()
+	strategy.Register(id, func() strategy.Strategy { return scripted(states) })
+	t.Cleanup(func() { strategy.Unregister(id) })
+}
+
+func testCandles(n int) []hyperliquid.Candle {
+	candles := make([]hyperliquid.Candle, n)
+	for i := range candles {
+		candles[i] = hyperliquid.Candle{Timestamp: int64(i) * 3_600_000, Close: strconv.Itoa(100 + i)}
+	}
+	return candles
+}
+
+func generate(t *testing.T, spec Spec, n int) []exchange.Signal {
+	t.Helper()
+	if err := spec.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	strat, err := New(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strat.GenerateSignals(testCandles(n))
+}
+
+func TestVoting(t *testing.T) {
+	registerScripted(t, "test-a", 0, 1, 1, 1, -1, -1)
+	registerScripted(t, "test-b", 0, 1, 0, -1, -1, 0)
+	registerScripted(t, "test-c", 0, 0, 1, 1, -1, -1)
+	children := []Child{{Alias: "a", StrategyID: "test-a"}, {Alias: "b", StrategyID: "test-b"}, {Alias: "c", StrategyID: "test-c"}}
+
+	tests := []struct {
+		mode string
+		want []int
+	}{
+		{ModeMajority, []int{0, 1, 1, 1, -1, -1}},
+		{ModeUnanimous, []int{0, 0, 0, 0, -1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			signals := generate(t, Spec{ID: "test-vote", Mode: tt.mode, Children: children}, len(tt.want))
+			if got := positionStates(signals, len(tt.want)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("states %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	registerScripted(t, "test-primary", 0, 1, 1, -1, -1, 1, 0, -1)
+	registerScripted(t, "test-filter", 0, 1, 1, 1, -1, 1, 1, 1)
+	spec := Spec{ID: "test-filtered", Mode: ModeFilter, Children: []Child{
+		{Alias: "primary", StrategyID: "test-primary"}, {Alias: "filter", StrategyID: "test-filter"},
+	}}
+
+	type step struct {
+		Index  int
+		Type   exchange.SignalType
+		Reason string
+	}
+	var got []step
+	for _, signal := range generate(t, spec, 8) {
+		got = append(got, step{signal.Index, signal.Type, signal.Reason})
+	}
+	want := []step{
+		{1, exchange.SignalLong, "Scripted"},
+		// The filter is still long, so the short is blocked but the long is closed
+		{3, exchange.SignalExitLong, "Filtered Reversal"},
+		{5, exchange.SignalLong, "Scripted"},
+		{6, exchange.SignalExitLong, "Scripted"},
+		// A blocked entry from flat emits nothing
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("signals %+v, want %+v", got, want)
+	}
+}
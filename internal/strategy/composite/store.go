@@ -0,0 +1,204 @@
+package composite
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"terminal/internal/strategy"
+)
+
+// registered tracks composite specs in the global registry so that
+// definitions can be listed and cycles detected
+var (
+	registered   = make(map[string]Spec)
+	registeredMu sync.RWMutex
+)
+
+// Register validates spec and adds it to the global strategy registry
+func Register(spec Spec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	if _, isComposite := registered[spec.ID]; !isComposite && strategy.Has(spec.ID) {
+		return fmt.Errorf("strategy %s already exists", spec.ID)
+	}
+	if hasCycle(spec, spec.ID, map[string]bool{}) {
+		return fmt.Errorf("composite %s references itself", spec.ID)
+	}
+
+	if _, err := New(spec); err != nil {
+		return err
+	}
+
+	registered[spec.ID] = spec
+	strategy.Register(spec.ID, func() strategy.Strategy {
+		strat, err := New(spec)
+		if err != nil {
+			// Children were checked at registration; only an unregistered
+			// child can fail here, which leaves an empty composite
+			return &Strategy{spec: spec}
+		}
+		return strat
+	})
+	return nil
+}
+
+// Unregister removes a composite from the global strategy registry
+func Unregister(id string) error {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	if _, ok := registered[id]; !ok {
+		return fmt.Errorf("composite not found: %s", id)
+	}
+	for other, spec := range registered {
+		for _, child := range spec.Children {
+			if child.StrategyID == id {
+				return fmt.Errorf("composite %s is used by %s", id, other)
+			}
+		}
+	}
+
+	delete(registered, id)
+	strategy.Unregister(id)
+	return nil
+}
+
+// List returns all registered composite specs sorted by id
+func List() []Spec {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+
+	result := make([]Spec, 0, len(registered))
+	for _, spec := range registered {
+		result = append(result, spec)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// hasCycle reports whether spec reaches root through its children
+func hasCycle(spec Spec, root string, seen map[string]bool) bool {
+	for _, child := range spec.Children {
+		if child.StrategyID == root {
+			return true
+		}
+		if seen[child.StrategyID] {
+			continue
+		}
+		seen[child.StrategyID] = true
+		if next, ok := registered[child.StrategyID]; ok && hasCycle(next, root, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// Store persists composite specs as JSON files in a directory
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Load registers every spec in the store. Specs are retried until no more
+// can be registered so that composites of composites load in any order.
+func (s *Store) Load() error {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read composites: %w", err)
+	}
+
+	var pending []Spec
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		var spec Spec
+		if err := json.Unmarshal(raw, &spec); err != nil {
+			return fmt.Errorf("invalid composite %s: %w", entry.Name(), err)
+		}
+		pending = append(pending, spec)
+	}
+
+	for len(pending) > 0 {
+		var retry []Spec
+		var lastErr error
+		for _, spec := range pending {
+			if err := Register(spec); err != nil {
+				retry = append(retry, spec)
+				lastErr = fmt.Errorf("composite %s: %w", spec.ID, err)
+			}
+		}
+		if len(retry) == len(pending) {
+			return lastErr
+		}
+		pending = retry
+	}
+	return nil
+}
+
+// Save writes spec to the store and registers it. The previous file is
+// restored when registering fails, so the store never holds a spec that
+// is not registered, nor the other way round.
+func (s *Store) Save(spec Spec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create composites dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := s.path(spec.ID)
+	previous, readErr := os.ReadFile(path)
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write composite: %w", err)
+	}
+	if err := Register(spec); err != nil {
+		if readErr == nil {
+			os.WriteFile(path, previous, 0o644)
+		} else {
+			os.Remove(path)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete unregisters a composite and removes its file
+func (s *Store) Delete(id string) error {
+	if err := Unregister(id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
@@ -0,0 +1,94 @@
+package composite
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// registerComposite registers spec for the duration of the test
+func registerComposite(t *testing.T, spec Spec) {
+	t.Helper()
+	if err := Register(spec); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Unregister(spec.ID) })
+}
+
+func pair(id, first, second string) Spec {
+	return Spec{ID: id, Mode: ModeMajority, Children: []Child{
+		{Alias: "first", StrategyID: first}, {Alias: "second", StrategyID: second},
+	}}
+}
+
+func TestRegisterRejectsCycle(t *testing.T) {
+	registerScripted(t, "test-a", 0, 1)
+	registerScripted(t, "test-b", 0, 1)
+	registerComposite(t, pair("test-x", "test-a", "test-b"))
+	registerComposite(t, pair("test-y", "test-x", "test-b"))
+
+	// Redefining x in terms of y would make x reach itself through y
+	err := Register(pair("test-x", "test-y", "test-a"))
+	if err == nil || !strings.Contains(err.Error(), "references itself") {
+		t.Fatalf("got %v, want a cycle error", err)
+	}
+	if got := List()[0]; got.Children[0].StrategyID != "test-a" {
+		t.Errorf("rejected spec replaced the registered one: %+v", got)
+	}
+}
+
+func TestUnregisterRefusesReferencedChild(t *testing.T) {
+	registerScripted(t, "test-a", 0, 1)
+	registerScripted(t, "test-b", 0, 1)
+	registerComposite(t, pair("test-x", "test-a", "test-b"))
+	registerComposite(t, pair("test-y", "test-x", "test-b"))
+
+	if err := Unregister("test-x"); err == nil || !strings.Contains(err.Error(), "used by test-y") {
+		t.Fatalf("got %v, want an in-use error", err)
+	}
+	if err := Unregister("test-y"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Unregister("test-x"); err != nil {
+		t.Fatalf("unregister after its parent is gone: %v", err)
+	}
+}
+
+func TestSaveRestoresFileWhenRegisterFails(t *testing.T) {
+	registerScripted(t, "test-a", 0, 1)
+	registerScripted(t, "test-b", 0, 1)
+	store := NewStore(t.TempDir())
+	t.Cleanup(func() { Unregister("test-y"); Unregister("test-x") })
+
+	if err := store.Save(pair("test-x", "test-a", "test-b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(pair("test-y", "test-x", "test-b")); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(store.dir, "test-x.json")
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Save(pair("test-x", "test-y", "test-a")); err == nil {
+		t.Fatal("saved a cyclic composite")
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("file not restored:\n%s\nwant\n%s", after, before)
+	}
+
+	// A new spec that fails to register leaves no file behind
+	if err := store.Save(pair("test-a", "test-b", "test-x")); err == nil {
+		t.Fatal("saved a composite over a built-in strategy")
+	}
+	if _, err := os.Stat(filepath.Join(store.dir, "test-a.json")); !os.IsNotExist(err) {
+		t.Errorf("failed save left a file: %v", err)
+	}
+}
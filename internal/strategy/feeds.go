@@ -0,0 +1,164 @@
+package strategy
+
+import (
+	"fmt"
+
+	"terminal/internal/exchange"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// FeedDef declares an extra candle series a strategy needs besides the
+// primary one it runs on
+type FeedDef struct {
+	// Symbol of the feed; empty means the strategy's primary symbol
+	Symbol string `json:"symbol,omitempty"`
+	// Interval of the feed, e.g. "4h"
+	Interval string `json:"interval"`
+	// WarmupBars of the feed's own interval to fetch before the primary span
+	WarmupBars int `json:"warmupBars,omitempty"`
+}
+
+func feedKey(symbol, interval string) string {
+	return symbol + "@" + interval
+}
+
+// Feed is an extra candle series aligned to the primary candles
+type Feed struct {
+	Symbol   string
+	Interval string
+	Candles  []hyperliquid.Candle
+
+	// aligned[i] is the index of the last feed candle closed at primary bar i,
+	// or -1 if none has closed yet
+	aligned []int
+}
+
+// At returns the last feed candle that had closed by primary bar i.
+// A higher-timeframe bar only becomes visible once it has closed, so there
+// is no lookahead.
+func (f *Feed) At(i int) (hyperliquid.Candle, bool) {
+	idx := f.IndexAt(i)
+	if idx < 0 {
+		return hyperliquid.Candle{}, false
+	}
+	return f.Candles[idx], true
+}
+
+// IndexAt returns the index into Candles visible at primary bar i, or -1
+func (f *Feed) IndexAt(i int) int {
+	if i < 0 || i >= len(f.aligned) {
+		return -1
+	}
+	return f.aligned[i]
+}
+
+// DataContext holds the primary candles and all extra feeds a strategy declared
+type DataContext struct {
+	Symbol   string
+	Interval string
+	Candles  []hyperliquid.Candle
+	feeds    map[string]*Feed
+	perp     *PerpSeries
+}
+
+// NewDataContext creates a context for the primary series
+func NewDataContext(symbol, interval string, candles []hyperliquid.Candle) *DataContext {
+	return &DataContext{
+		Symbol:   symbol,
+		Interval: interval,
+		Candles:  candles,
+		feeds:    make(map[string]*Feed),
+	}
+}
+
+// AddFeed aligns candles to the primary series and stores them as a feed.
+// Both series must be sorted by time. A feed candle is visible at primary
+// bar i when its close time is not after the close time of bar i.
+func (c *DataContext) AddFeed(symbol, interval string, candles []hyperliquid.Candle) {
+	aligned := make([]int, len(c.Candles))
+	j := -1
+	for i, candle := range c.Candles {
+		for j+1 < len(candles) && candles[j+1].Timestamp <= candle.Timestamp {
+			j++
+		}
+		aligned[i] = j
+	}
+
+	c.feeds[feedKey(symbol, interval)] = &Feed{
+		Symbol:   symbol,
+		Interval: interval,
+		Candles:  candles,
+		aligned:  aligned,
+	}
+}
+
+// Feed returns the feed for symbol and interval; an empty symbol means the
+// primary symbol
+func (c *DataContext) Feed(symbol, interval string) (*Feed, error) {
+	if symbol == "" {
+		symbol = c.Symbol
+	}
+	feed, ok := c.feeds[feedKey(symbol, interval)]
+	if !ok {
+		return nil, fmt.Errorf("feed not loaded: %s %s", symbol, interval)
+	}
+	return feed, nil
+}
+
+// Head returns the context as it looked when the first bars primary candles
+// had closed: later primary candles are dropped and every feed keeps only the
+// candles closed by then
+func (c *DataContext) Head(bars int) *DataContext {
+	bars = min(max(bars, 0), len(c.Candles))
+	head := NewDataContext(c.Symbol, c.Interval, c.Candles[:bars])
+	if bars == 0 {
+		return head
+	}
+
+	cutoff := c.Candles[bars-1].Timestamp
+	for _, feed := range c.feeds {
+		head.AddFeed(feed.Symbol, feed.Interval, closedBy(feed.Candles, cutoff))
+	}
+	if c.perp != nil {
+		head.SetPerpData(c.perp.funding, c.perp.markets)
+	}
+	return head
+}
+
+// closedBy returns the leading candles that closed at or before cutoff
+func closedBy(candles []hyperliquid.Candle, cutoff int64) []hyperliquid.Candle {
+	n := 0
+	for n < len(candles) && candles[n].Timestamp <= cutoff {
+		n++
+	}
+	return candles[:n]
+}
+
+// FeedStrategy is implemented by strategies that declare extra feeds in
+// their Metadata and want them delivered alongside the primary candles
+type FeedStrategy interface {
+	Strategy
+
+	// GenerateSignalsWithFeeds generates signals from the primary candles and feeds
+	GenerateSignalsWithFeeds(ctx *DataContext) []exchange.Signal
+
+	// GetVisualizationWithFeeds returns chart overlays computed with feeds
+	GetVisualizationWithFeeds(ctx *DataContext) *Visualization
+}
+
+// GenerateSignals runs s on ctx, using the feed-aware entry point if s has one
+func GenerateSignals(s Strategy, ctx *DataContext) []exchange.Signal {
+	if fs, ok := s.(FeedStrategy); ok {
+		return fs.GenerateSignalsWithFeeds(ctx)
+	}
+	return s.GenerateSignals(ctx.Candles)
+}
+
+// GetVisualization runs s on ctx, using the feed-aware entry point if s has one
+func GetVisualization(s Strategy, ctx *DataContext) *Visualization {
+	if fs, ok := s.(FeedStrategy); ok {
+		return fs.GetVisualizationWithFeeds(ctx)
+	}
+	return s.GetVisualization(ctx.Candles)
+}
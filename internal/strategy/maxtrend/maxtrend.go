@@ -11,6 +11,13 @@ import (
 	hyperliquid "github.com/sonirico/go-hyperliquid"
 )
 
+// hmaPeriod is the Hull Moving Average period used for the band distance
+const hmaPeriod = 200
+
+// warmupBars is the number of bars before the HMA produces its first value:
+// the slow WMA needs hmaPeriod bars and the final WMA another sqrt(period)-1
+var warmupBars = hmaPeriod + int(math.Sqrt(hmaPeriod)) - 1
+
 func init() {
 	strategy.Register("max-trend", func() strategy.Strategy {
 		return New()
@@ -20,7 +27,7 @@ func init() {
 // Strategy implements the Max Trend Points strategy
 // It uses Hull Moving Average to identify trend reversals
 type Strategy struct {
-	Factor float64
+	Factor float64 `param:"factor"`
 
 	// Internal state for visualization
 	output *visualizationOutput
@@ -52,11 +59,13 @@ func (s *Strategy) GetMetadata() strategy.Metadata {
 		Name:        "Max Trend Points",
 		Version:     "1.0",
 		Description: "Trend-following strategy using Hull Moving Average to identify trend reversals",
+		WarmupBars:  warmupBars,
+		Lookback:    warmupBars + 100,
 		Parameters: []strategy.ParameterDef{
 			{
 				Name:         "factor",
 				Label:        "Factor",
-				Type:         "number",
+				Type:         strategy.ParamNumber,
 				DefaultValue: 2.5,
 				Min:          &minFactor,
 				Max:          &maxFactor,
@@ -69,29 +78,13 @@ func (s *Strategy) GetMetadata() strategy.Metadata {
 
 // ValidateParams validates strategy parameters
 func (s *Strategy) ValidateParams(params map[string]any) error {
-	factor, ok := params["factor"]
-	if !ok {
-		return fmt.Errorf("missing required parameter: factor")
-	}
-
-	f, ok := factor.(float64)
-	if !ok {
-		return fmt.Errorf("factor must be a number")
-	}
-
-	if f < 0.1 || f > 10.0 {
-		return fmt.Errorf("factor must be between 0.1 and 10.0")
-	}
-
-	return nil
+	_, err := strategy.ValidateAgainst(s.GetMetadata(), params)
+	return err
 }
 
 // Initialize sets up the strategy with validated parameters
 func (s *Strategy) Initialize(params map[string]any) error {
-	if factor, ok := params["factor"].(float64); ok {
-		s.Factor = factor
-	}
-	return nil
+	return strategy.BindParams(s.GetMetadata(), params, s)
 }
 
 // GenerateSignals generates trading signals from candle data
@@ -133,13 +126,72 @@ func (s *Strategy) GetVisualization(candles []hyperliquid.Candle) *strategy.Visu
 	if err := s.calculateTrends(candles); err != nil {
 		return nil
 	}
-	return &strategy.Visualization{
-		TrendLines:  s.output.TrendLines,
-		TrendColors: s.output.TrendColors,
-		Directions:  s.output.Directions,
-		Labels:      s.output.Labels,
-		Lines:       s.output.Lines,
+
+	vis := strategy.NewVisualization()
+
+	// Trend line, with gaps during the HMA warm-up
+	values := make(strategy.Values, len(s.output.TrendLines))
+	for i, v := range s.output.TrendLines {
+		if v > 0 {
+			values[i] = v
+		} else {
+			values[i] = math.NaN()
+		}
+	}
+	vis.Series = []strategy.Series{{
+		ID:        "trend",
+		Name:      "Trend",
+		Pane:      strategy.MainPane,
+		Kind:      strategy.SeriesLine,
+		Colors:    s.output.TrendColors,
+		LineWidth: 2,
+		Values:    values,
+	}}
+
+	for _, line := range s.output.Lines {
+		vis.Segments = append(vis.Segments, strategy.Segment{
+			Pane:       strategy.MainPane,
+			StartIndex: line.StartIndex,
+			StartValue: line.StartPrice,
+			EndIndex:   line.EndIndex,
+			EndValue:   line.EndPrice,
+			Color:      directionColor(line.Direction),
+			Style:      strategy.StyleDashed,
+		})
+	}
+
+	for _, label := range s.output.Labels {
+		position := strategy.PositionAbove
+		if label.Direction == 1 {
+			position = strategy.PositionBelow
+		}
+		vis.Markers = append(vis.Markers, strategy.Marker{
+			Pane:     strategy.MainPane,
+			Index:    label.Index,
+			Value:    label.Price,
+			Shape:    strategy.ShapeLabel,
+			Position: position,
+			Color:    directionColor(label.Direction),
+			Text:     label.Text,
+		})
+	}
+
+	// Version 1 fields for the existing chart renderer
+	vis.TrendLines = s.output.TrendLines
+	vis.TrendColors = s.output.TrendColors
+	vis.Directions = s.output.Directions
+	vis.Labels = s.output.Labels
+	vis.Lines = s.output.Lines
+
+	return vis
+}
+
+// directionColor returns the chart color for a trend direction
+func directionColor(direction int) string {
+	if direction == 1 {
+		return "#e49013" // Orange for short
 	}
+	return "#1cc2d8" // Cyan for long
 }
 
 // calculateTrends computes trend lines and directions
@@ -152,8 +204,8 @@ func (s *Strategy) calculateTrends(candles []hyperliquid.Candle) error {
 		Labels:      []strategy.Label{},
 		Directions:  make([]int, n),
 	}
-	if n < 200 {
-		return fmt.Errorf("insufficient candles: need at least 200, got %d", n)
+	if n < hmaPeriod {
+		return fmt.Errorf("insufficient candles: need at least %d, got %d", hmaPeriod, n)
 	}
 
 	hl2 := make([]float64, n)
@@ -165,7 +217,7 @@ func (s *Strategy) calculateTrends(candles []hyperliquid.Candle) error {
 		highLowDiff[i] = high - low
 	}
 
-	dist := s.hma(highLowDiff, 200)
+	dist := s.hma(highLowDiff, hmaPeriod)
 	upperBand := make([]float64, n)
 	lowerBand := make([]float64, n)
 	for i := range candles {
@@ -210,11 +262,7 @@ func (s *Strategy) calculateTrends(candles []hyperliquid.Candle) error {
 		}
 		s.output.TrendLines[i] = trendLine[i]
 		s.output.Directions[i] = direction[i]
-		if direction[i] == 1 {
-			s.output.TrendColors[i] = "#e49013" // Orange for short
-		} else {
-			s.output.TrendColors[i] = "#1cc2d8" // Cyan for long
-		}
+		s.output.TrendColors[i] = directionColor(direction[i])
 	}
 
 	// Build trend lines and labels
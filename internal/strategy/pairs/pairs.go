@@ -0,0 +1,232 @@
+package pairs
+
+import (
+	"math"
+	"strconv"
+
+	"terminal/internal/exchange"
+	"terminal/internal/strategy"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+func init() {
+	strategy.Register("spread-reversion", func() strategy.Strategy {
+		return New()
+	})
+}
+
+// Strategy implements log-spread mean reversion between two symbols
+// It goes long the cheap leg and short the rich leg when the z-score of
+// ln(A) - ln(B) stretches beyond EntryZ, and flattens when it reverts
+type Strategy struct {
+	Lookback int     `param:"lookback"`
+	EntryZ   float64 `param:"entryZ"`
+	ExitZ    float64 `param:"exitZ"`
+}
+
+// New creates a new spread reversion strategy with default parameters
+func New() *Strategy {
+	return &Strategy{
+		Lookback: 100,
+		EntryZ:   2.0,
+		ExitZ:    0.5,
+	}
+}
+
+// GetMetadata returns strategy metadata for frontend discovery
+func (s *Strategy) GetMetadata() strategy.Metadata {
+	minLookback, maxLookback, stepLookback := 10.0, 1000.0, 1.0
+	minZ, maxZ, stepZ := 0.0, 5.0, 0.1
+
+	return strategy.Metadata{
+		ID:          "spread-reversion",
+		Name:        "Spread Reversion",
+		Version:     "1.0",
+		Description: "Pairs strategy trading mean reversion of the log price spread between two symbols",
+		WarmupBars:  s.Lookback,
+		Lookback:    s.Lookback * 2,
+		Parameters: []strategy.ParameterDef{
+			{
+				Name:         "lookback",
+				Label:        "Lookback",
+				Type:         strategy.ParamInteger,
+				DefaultValue: 100,
+				Min:          &minLookback,
+				Max:          &maxLookback,
+				Step:         &stepLookback,
+				Required:     true,
+			},
+			{
+				Name:         "entryZ",
+				Label:        "Entry Z-Score",
+				Type:         strategy.ParamNumber,
+				DefaultValue: 2.0,
+				Min:          &minZ,
+				Max:          &maxZ,
+				Step:         &stepZ,
+				Required:     true,
+			},
+			{
+				Name:         "exitZ",
+				Label:        "Exit Z-Score",
+				Type:         strategy.ParamNumber,
+				DefaultValue: 0.5,
+				Min:          &minZ,
+				Max:          &maxZ,
+				Step:         &stepZ,
+				Required:     true,
+			},
+		},
+	}
+}
+
+// ValidateParams validates strategy parameters
+func (s *Strategy) ValidateParams(params map[string]any) error {
+	_, err := strategy.ValidateAgainst(s.GetMetadata(), params)
+	return err
+}
+
+// Initialize sets up the strategy with validated parameters
+func (s *Strategy) Initialize(params map[string]any) error {
+	return strategy.BindParams(s.GetMetadata(), params, s)
+}
+
+// GenerateSignals returns nothing; this strategy needs a basket
+func (s *Strategy) GenerateSignals(candles []hyperliquid.Candle) []exchange.Signal {
+	return nil
+}
+
+// GetVisualization returns nothing; this strategy needs a basket
+func (s *Strategy) GetVisualization(candles []hyperliquid.Candle) *strategy.Visualization {
+	return nil
+}
+
+// GenerateBasketSignals emits paired leg signals on the first two symbols.
+// Leg B is sized by the price ratio so both legs carry similar notional.
+func (s *Strategy) GenerateBasketSignals(basket *strategy.Basket) []exchange.Signal {
+	if len(basket.Symbols) < 2 {
+		return nil
+	}
+	symbolA, symbolB := basket.Symbols[0], basket.Symbols[1]
+	candlesA, candlesB := basket.Candles[symbolA], basket.Candles[symbolB]
+	z := s.zScores(candlesA, candlesB)
+
+	signals := []exchange.Signal{}
+	state := 0 // 1 = long spread, -1 = short spread
+	for i := s.Lookback; i < basket.Len(); i++ {
+		priceA := parseFloat(candlesA[i].Close)
+		priceB := parseFloat(candlesB[i].Close)
+		ratio := priceA / priceB
+		time := basket.Times[i]
+
+		leg := func(symbol string, signalType exchange.SignalType, price float64, fraction float64, reason string) exchange.Signal {
+			return exchange.Signal{
+				Index:        i,
+				Type:         signalType,
+				Price:        price,
+				Time:         time,
+				Reason:       reason,
+				SizeFraction: fraction,
+				Symbol:       symbol,
+			}
+		}
+
+		switch {
+		case state <= 0 && z[i] <= -s.EntryZ:
+			signals = append(signals,
+				leg(symbolA, exchange.SignalLong, priceA, 0, "Spread Stretched Low"),
+				leg(symbolB, exchange.SignalShort, priceB, ratio, "Spread Stretched Low"),
+			)
+			state = 1
+		case state >= 0 && z[i] >= s.EntryZ:
+			signals = append(signals,
+				leg(symbolA, exchange.SignalShort, priceA, 0, "Spread Stretched High"),
+				leg(symbolB, exchange.SignalLong, priceB, ratio, "Spread Stretched High"),
+			)
+			state = -1
+		case state != 0 && math.Abs(z[i]) <= s.ExitZ:
+			signals = append(signals,
+				leg(symbolA, exchange.SignalFlatten, priceA, 0, "Spread Reverted"),
+				leg(symbolB, exchange.SignalFlatten, priceB, 0, "Spread Reverted"),
+			)
+			state = 0
+		}
+	}
+	return signals
+}
+
+// GetBasketVisualization plots the spread z-score in its own pane with the
+// entry and exit thresholds
+func (s *Strategy) GetBasketVisualization(basket *strategy.Basket) *strategy.Visualization {
+	if len(basket.Symbols) < 2 {
+		return nil
+	}
+	z := s.zScores(basket.Candles[basket.Symbols[0]], basket.Candles[basket.Symbols[1]])
+
+	values := make(strategy.Values, len(z))
+	colors := make([]string, len(z))
+	for i, v := range z {
+		values[i] = v
+		if i < s.Lookback-1 {
+			values[i] = math.NaN()
+		}
+		if v < 0 {
+			colors[i] = "#1cc2d8"
+		} else {
+			colors[i] = "#e49013"
+		}
+	}
+
+	vis := strategy.NewVisualization()
+	vis.Panes = []strategy.Pane{{ID: "spread", Title: basket.Symbols[0] + "/" + basket.Symbols[1] + " z-score"}}
+	vis.Series = []strategy.Series{{
+		ID:     "z",
+		Name:   "Spread Z",
+		Pane:   "spread",
+		Kind:   strategy.SeriesHistogram,
+		Colors: colors,
+		Values: values,
+	}}
+	vis.Levels = []strategy.Level{
+		{Pane: "spread", Value: 0, Color: "#888888"},
+		{Pane: "spread", Value: s.EntryZ, Color: "#e49013", Style: strategy.StyleDashed, Label: "Entry"},
+		{Pane: "spread", Value: -s.EntryZ, Color: "#1cc2d8", Style: strategy.StyleDashed, Label: "Entry"},
+		{Pane: "spread", Value: s.ExitZ, Color: "#888888", Style: strategy.StyleDotted, Label: "Exit"},
+		{Pane: "spread", Value: -s.ExitZ, Color: "#888888", Style: strategy.StyleDotted, Label: "Exit"},
+	}
+	return vis
+}
+
+// zScores returns the rolling z-score of the log spread, using only bars up
+// to and including i for bar i
+func (s *Strategy) zScores(candlesA, candlesB []hyperliquid.Candle) []float64 {
+	n := len(candlesA)
+	spread := make([]float64, n)
+	for i := 0; i < n; i++ {
+		spread[i] = math.Log(parseFloat(candlesA[i].Close)) - math.Log(parseFloat(candlesB[i].Close))
+	}
+
+	z := make([]float64, n)
+	for i := s.Lookback - 1; i < n; i++ {
+		var sum, sumSq float64
+		for j := i - s.Lookback + 1; j <= i; j++ {
+			sum += spread[j]
+			sumSq += spread[j] * spread[j]
+		}
+		mean := sum / float64(s.Lookback)
+		std := math.Sqrt(math.Max(sumSq/float64(s.Lookback)-mean*mean, 0))
+		if std > 0 {
+			z[i] = (spread[i] - mean) / std
+		}
+	}
+	return z
+}
+
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// Verify Strategy implements the interface
+var _ strategy.MultiAssetStrategy = (*Strategy)(nil)
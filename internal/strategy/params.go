@@ -0,0 +1,273 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parameter types understood by ValidateAgainst
+const (
+	ParamNumber   = "number"
+	ParamInteger  = "integer"
+	ParamBoolean  = "boolean"
+	ParamString   = "string"
+	ParamSelect   = "select"
+	ParamDuration = "duration"
+)
+
+// stepTolerance absorbs float rounding when checking step alignment
+const stepTolerance = 1e-9
+
+// ValidateAgainst checks params against the parameter definitions in meta.
+// It applies defaults for missing values, coerces JSON values to the declared
+// type and enforces min/max/step and option membership. The returned map
+// holds the normalized values; the input map is not modified.
+func ValidateAgainst(meta Metadata, params map[string]any) (map[string]any, error) {
+	result := make(map[string]any, len(meta.Parameters))
+	var errs []error
+
+	for _, def := range meta.Parameters {
+		raw, ok := params[def.Name]
+		if !ok || raw == nil {
+			if def.DefaultValue == nil {
+				if def.Required {
+					errs = append(errs, fmt.Errorf("missing required parameter: %s", def.Name))
+				}
+				continue
+			}
+			raw = def.DefaultValue
+		}
+
+		value, err := coerceParam(def, raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parameter %s: %w", def.Name, err))
+			continue
+		}
+		if err := checkParamBounds(def, value); err != nil {
+			errs = append(errs, fmt.Errorf("parameter %s: %w", def.Name, err))
+			continue
+		}
+		result[def.Name] = value
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+// BindParams validates params against meta and assigns the normalized values
+// to the fields of target tagged with `param:"name"`. target must be a
+// pointer to a struct.
+func BindParams(meta Metadata, params map[string]any, target any) error {
+	values, err := ValidateAgainst(meta, params)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind target must be a pointer to a struct, got %T", target)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name, ok := field.Tag.Lookup("param")
+		if !ok || name == "" || name == "-" {
+			continue
+		}
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		if err := setField(rv.Field(i), value); err != nil {
+			return fmt.Errorf("parameter %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// coerceParam converts a raw (usually JSON-decoded) value to the declared type
+func coerceParam(def ParameterDef, raw any) (any, error) {
+	switch def.Type {
+	case ParamNumber:
+		return toFloat(raw)
+	case ParamInteger:
+		f, err := toFloat(raw)
+		if err != nil {
+			return nil, err
+		}
+		if f != math.Trunc(f) {
+			return nil, fmt.Errorf("must be a whole number, got %v", f)
+		}
+		return int(f), nil
+	case ParamBoolean:
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("must be a boolean, got %q", v)
+			}
+			return b, nil
+		}
+		return nil, fmt.Errorf("must be a boolean, got %T", raw)
+	case ParamDuration:
+		switch v := raw.(type) {
+		case time.Duration:
+			return v, nil
+		case string:
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("must be a duration, got %q", v)
+			}
+			return d, nil
+		}
+		// Plain numbers are interpreted as seconds
+		f, err := toFloat(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a duration, got %T", raw)
+		}
+		return time.Duration(f * float64(time.Second)), nil
+	case ParamSelect:
+		// Select values keep the type of the matching option
+		for _, opt := range def.Options {
+			if optionEquals(opt.Value, raw) {
+				return opt.Value, nil
+			}
+		}
+		return nil, fmt.Errorf("must be one of %s, got %v", optionList(def.Options), raw)
+	case ParamString, "":
+		if s, ok := raw.(string); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("must be a string, got %T", raw)
+	default:
+		return nil, fmt.Errorf("unsupported parameter type %q", def.Type)
+	}
+}
+
+// checkParamBounds enforces Min, Max and Step on numeric values
+func checkParamBounds(def ParameterDef, value any) error {
+	var f float64
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case int:
+		f = float64(v)
+	case time.Duration:
+		f = v.Seconds()
+	default:
+		return nil
+	}
+
+	if def.Min != nil && f < *def.Min {
+		return fmt.Errorf("must be at least %v, got %v", *def.Min, f)
+	}
+	if def.Max != nil && f > *def.Max {
+		return fmt.Errorf("must be at most %v, got %v", *def.Max, f)
+	}
+	if def.Step != nil && *def.Step > 0 {
+		base := 0.0
+		if def.Min != nil {
+			base = *def.Min
+		}
+		steps := (f - base) / *def.Step
+		if math.Abs(steps-math.Round(steps)) > stepTolerance*math.Max(1, math.Abs(steps)) {
+			return fmt.Errorf("must be a multiple of %v from %v, got %v", *def.Step, base, f)
+		}
+	}
+	return nil
+}
+
+func toFloat(raw any) (float64, error) {
+	switch v := raw.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("must be a number, got %q", v)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("must be a number, got %T", raw)
+}
+
+// optionEquals compares option values, treating all numeric types alike
+func optionEquals(option any, raw any) bool {
+	of, oerr := toFloat(option)
+	rf, rerr := toFloat(raw)
+	if _, isString := option.(string); !isString && oerr == nil && rerr == nil {
+		return of == rf
+	}
+	return fmt.Sprint(option) == fmt.Sprint(raw)
+}
+
+func optionList(options []Option) string {
+	values := make([]string, len(options))
+	for i, opt := range options {
+		values[i] = fmt.Sprint(opt.Value)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// setField assigns a normalized parameter value to a struct field
+func setField(field reflect.Value, value any) error {
+	if !field.CanSet() {
+		return fmt.Errorf("field is not settable")
+	}
+
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, ok := value.(time.Duration)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to time.Duration", value)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat(value)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f, err := toFloat(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(f))
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to bool", value)
+		}
+		field.SetBool(b)
+	case reflect.String:
+		field.SetString(fmt.Sprint(value))
+	case reflect.Interface:
+		field.Set(reflect.ValueOf(value))
+	default:
+		return fmt.Errorf("unsupported field kind %s", field.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+)
+
+func ptr(f float64) *float64 { return &f }
+
+func TestCoerceParam(t *testing.T) {
+	selectDef := ParameterDef{Type: ParamSelect, Options: []Option{{Value: 5}, {Value: "fast"}}}
+	tests := []struct {
+		name string
+		def  ParameterDef
+		raw  any
+		want any
+		fail bool
+	}{
+		{name: "number from JSON", def: ParameterDef{Type: ParamNumber}, raw: 1.5, want: 1.5},
+		{name: "number from string", def: ParameterDef{Type: ParamNumber}, raw: " 2.5 ", want: 2.5},
+		{name: "number from bool", def: ParameterDef{Type: ParamNumber}, raw: true, fail: true},
+		{name: "integer", def: ParameterDef{Type: ParamInteger}, raw: 14.0, want: 14},
+		{name: "integer not whole", def: ParameterDef{Type: ParamInteger}, raw: 14.5, fail: true},
+		{name: "boolean", def: ParameterDef{Type: ParamBoolean}, raw: false, want: false},
+		{name: "boolean from string", def: ParameterDef{Type: ParamBoolean}, raw: "true", want: true},
+		{name: "boolean from bad string", def: ParameterDef{Type: ParamBoolean}, raw: "yes please", fail: true},
+		{name: "duration from string", def: ParameterDef{Type: ParamDuration}, raw: "90m", want: 90 * time.Minute},
+		{name: "duration from seconds", def: ParameterDef{Type: ParamDuration}, raw: 1.5, want: 1500 * time.Millisecond},
+		{name: "duration from bad string", def: ParameterDef{Type: ParamDuration}, raw: "soon", fail: true},
+		{name: "select keeps the option type", def: selectDef, raw: 5.0, want: 5},
+		{name: "select string", def: selectDef, raw: "fast", want: "fast"},
+		{name: "select not an option", def: selectDef, raw: "slow", fail: true},
+		{name: "string", def: ParameterDef{Type: ParamString}, raw: "BTC", want: "BTC"},
+		{name: "untyped is a string", def: ParameterDef{}, raw: 1.0, fail: true},
+		{name: "unknown type", def: ParameterDef{Type: "color"}, raw: "red", fail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := coerceParam(tt.def, tt.raw)
+			if tt.fail {
+				if err == nil {
+					t.Errorf("got %v (%T), want an error", got, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %v (%T), %v; want %v (%T)", got, got, err, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckParamBounds(t *testing.T) {
+	tests := []struct {
+		name  string
+		def   ParameterDef
+		value any
+		fail  bool
+	}{
+		{name: "at min", def: ParameterDef{Min: ptr(1), Max: ptr(10)}, value: 1},
+		{name: "below min", def: ParameterDef{Min: ptr(1)}, value: 0.5, fail: true},
+		{name: "at max", def: ParameterDef{Min: ptr(1), Max: ptr(10)}, value: 10.0},
+		{name: "above max", def: ParameterDef{Max: ptr(10)}, value: 11, fail: true},
+		{name: "step from min", def: ParameterDef{Min: ptr(1), Step: ptr(2)}, value: 5},
+		{name: "step off min", def: ParameterDef{Min: ptr(1), Step: ptr(2)}, value: 4, fail: true},
+		{name: "step from zero", def: ParameterDef{Step: ptr(2)}, value: 4},
+		{name: "float step", def: ParameterDef{Min: ptr(0.1), Step: ptr(0.1)}, value: 0.7},
+		{name: "float step off", def: ParameterDef{Min: ptr(0.1), Step: ptr(0.1)}, value: 0.75, fail: true},
+		{name: "duration in seconds", def: ParameterDef{Min: ptr(60), Step: ptr(60)}, value: 5 * time.Minute},
+		{name: "short duration", def: ParameterDef{Min: ptr(60)}, value: 30 * time.Second, fail: true},
+		{name: "non-numeric", def: ParameterDef{Min: ptr(1)}, value: "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkParamBounds(tt.def, tt.value); (err != nil) != tt.fail {
+				t.Errorf("got %v, want failure %v", err, tt.fail)
+			}
+		})
+	}
+}
+
+func TestBindParams(t *testing.T) {
+	meta := Metadata{Parameters: []ParameterDef{
+		{Name: "period", Type: ParamInteger, DefaultValue: 14, Min: ptr(2)},
+		{Name: "threshold", Type: ParamNumber, DefaultValue: 0.5},
+		{Name: "cooldown", Type: ParamDuration, DefaultValue: "1h"},
+		{Name: "trail", Type: ParamBoolean, DefaultValue: false},
+		{Name: "mode", Type: ParamSelect, DefaultValue: "fast", Options: []Option{{Value: "fast"}, {Value: "slow"}}},
+		{Name: "symbol", Type: ParamString, Required: true},
+	}}
+	type config struct {
+		Period    int           `param:"period"`
+		Threshold float64       `param:"threshold"`
+		Cooldown  time.Duration `param:"cooldown"`
+		Trail     bool          `param:"trail"`
+		Mode      string        `param:"mode"`
+		Symbol    string        `param:"symbol"`
+		Untagged  int
+	}
+
+	var got config
+	params := map[string]any{"period": 20.0, "cooldown": 900.0, "trail": "true", "symbol": "ETH"}
+	if err := BindParams(meta, params, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := config{Period: 20, Threshold: 0.5, Cooldown: 15 * time.Minute, Trail: true, Mode: "fast", Symbol: "ETH"}
+	if got != want {
+		t.Errorf("bound %+v, want %+v", got, want)
+	}
+
+	if err := BindParams(meta, map[string]any{"period": 1.0}, &got); err == nil {
+		t.Error("bound a period below min without the required symbol")
+	}
+	if err := BindParams(meta, map[string]any{"symbol": "ETH"}, got); err == nil {
+		t.Error("bound to a struct that is not a pointer")
+	}
+}
@@ -0,0 +1,75 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"terminal/internal/data"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// PerpSeries holds the perpetual futures data of the primary symbol aligned
+// to the primary candles. Element i of each series is the value last known
+// when bar i closed, or NaN before the first, so there is no lookahead.
+type PerpSeries struct {
+	// FundingRate is the hourly rate of the last funding payment and
+	// Premium the premium it was based on
+	FundingRate []float64
+	Premium     []float64
+
+	// The rest come from market snapshots, which are only recorded while
+	// the app runs with Redis: PredictedFunding is the rate of the next
+	// funding payment
+	PredictedFunding []float64
+	OpenInterest     []float64
+	MarkPrice        []float64
+	OraclePrice      []float64
+
+	funding []data.FundingRate
+	markets []data.MarketContext
+}
+
+// SetPerpData aligns the funding payments and market snapshots of the
+// primary symbol, both sorted by time, to the primary candles. A payment or
+// snapshot is visible at bar i when it is not later than the close of bar i.
+func (c *DataContext) SetPerpData(funding []data.FundingRate, markets []data.MarketContext) {
+	fundingTime := func(j int) int64 { return funding[j].Time }
+	marketTime := func(j int) int64 { return markets[j].Time }
+	c.perp = &PerpSeries{
+		FundingRate:      alignValues(c.Candles, len(funding), fundingTime, func(j int) float64 { return funding[j].Rate }),
+		Premium:          alignValues(c.Candles, len(funding), fundingTime, func(j int) float64 { return funding[j].Premium }),
+		PredictedFunding: alignValues(c.Candles, len(markets), marketTime, func(j int) float64 { return markets[j].Funding }),
+		OpenInterest:     alignValues(c.Candles, len(markets), marketTime, func(j int) float64 { return markets[j].OpenInterest }),
+		MarkPrice:        alignValues(c.Candles, len(markets), marketTime, func(j int) float64 { return markets[j].MarkPrice }),
+		OraclePrice:      alignValues(c.Candles, len(markets), marketTime, func(j int) float64 { return markets[j].OraclePrice }),
+		funding:          funding,
+		markets:          markets,
+	}
+}
+
+// Perp returns the perpetual futures series of the primary symbol, which
+// are loaded for strategies that set PerpData in their Metadata
+func (c *DataContext) Perp() (*PerpSeries, error) {
+	if c.perp == nil {
+		return nil, fmt.Errorf("perp data not loaded for %s", c.Symbol)
+	}
+	return c.perp, nil
+}
+
+// alignValues returns, for each candle, the value of the last of n samples
+// timed at or before its close, or NaN when there is none
+func alignValues(candles []hyperliquid.Candle, n int, time func(int) int64, value func(int) float64) []float64 {
+	aligned := make([]float64, len(candles))
+	j := -1
+	for i, candle := range candles {
+		for j+1 < n && time(j+1) <= candle.Timestamp {
+			j++
+		}
+		aligned[i] = math.NaN()
+		if j >= 0 {
+			aligned[i] = value(j)
+		}
+	}
+	return aligned
+}
@@ -26,6 +26,13 @@ func (r *Registry) Register(id string, factory Factory) {
 	r.strategies[id] = factory
 }
 
+// Unregister removes a strategy factory from the registry
+func (r *Registry) Unregister(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.strategies, id)
+}
+
 // Get creates a new instance of a strategy by ID
 func (r *Registry) Get(id string) (Strategy, error) {
 	r.mu.RLock()
@@ -45,8 +52,7 @@ func (r *Registry) List() []Metadata {
 
 	result := make([]Metadata, 0, len(r.strategies))
 	for _, factory := range r.strategies {
-		strategy := factory()
-		result = append(result, strategy.GetMetadata())
+		result = append(result, Describe(factory()))
 	}
 	return result
 }
@@ -73,6 +79,11 @@ func Register(id string, factory Factory) {
 	globalRegistry.Register(id, factory)
 }
 
+// Unregister removes a strategy from the global registry
+func Unregister(id string) {
+	globalRegistry.Unregister(id)
+}
+
 // Get gets a strategy from the global registry
 func Get(id string) (Strategy, error) {
 	return globalRegistry.Get(id)
@@ -0,0 +1,292 @@
+// Package repaint detects strategies whose output changes after the fact.
+//
+// Candles are replayed one bar at a time, as the live engine sees them, and
+// what the strategy produced for the newest bar at each step is compared with
+// what the full-history run shows for that bar. Any difference means a trader
+// watching live would have seen something other than the backtest: a signal
+// that appears, disappears or moves, or a plotted value that is redrawn.
+//
+// Plotted values are the visualization's Series. Drawings (segments, markers,
+// boxes) are expected to be edited as bars arrive, like Pine's line and label
+// objects, and are not compared.
+package repaint
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"terminal/internal/exchange"
+	"terminal/internal/strategy"
+)
+
+// Change kinds
+const (
+	ChangeSignal = "signal"
+	ChangeValue  = "value"
+	ChangeColor  = "color"
+)
+
+// valueTolerance is the relative difference below which plotted values are
+// considered equal
+const valueTolerance = 1e-9
+
+// Change is one output that differs between the live replay and the
+// full-history run
+type Change struct {
+	Kind  string `json:"kind"`
+	Index int    `json:"index"`
+	// Series is the series id for value and color changes
+	Series string `json:"series,omitempty"`
+	// Live is what the strategy showed when bar Index was the newest bar
+	Live string `json:"live"`
+	// Final is what the full-history run shows for bar Index
+	Final string `json:"final"`
+}
+
+func (c Change) String() string {
+	subject := c.Kind
+	if c.Series != "" {
+		subject += " " + c.Series
+	}
+	return fmt.Sprintf("bar %d: %s live=%s final=%s", c.Index, subject, c.Live, c.Final)
+}
+
+// Report lists every change found for one strategy run
+type Report struct {
+	StrategyID string   `json:"strategyId"`
+	Bars       int      `json:"bars"`
+	Start      int      `json:"start"`
+	Changes    []Change `json:"changes"`
+}
+
+// Repaints reports whether any output changed after the fact
+func (r *Report) Repaints() bool {
+	return len(r.Changes) > 0
+}
+
+// Write prints a summary and at most limit changes to w; limit <= 0 prints all
+func (r *Report) Write(w io.Writer, limit int) {
+	if !r.Repaints() {
+		fmt.Fprintf(w, "%s: ok (%d bars replayed)\n", r.StrategyID, r.Bars-r.Start)
+		return
+	}
+	fmt.Fprintf(w, "%s: REPAINTS (%d changes over %d bars replayed)\n", r.StrategyID, len(r.Changes), r.Bars-r.Start)
+	for i, change := range r.Changes {
+		if limit > 0 && i == limit {
+			fmt.Fprintf(w, "  ... %d more\n", len(r.Changes)-limit)
+			break
+		}
+		fmt.Fprintf(w, "  %s\n", change)
+	}
+}
+
+// Runner produces a strategy's output from the first bars bars only. It must
+// use a fresh strategy instance for every call.
+type Runner func(bars int) ([]exchange.Signal, *strategy.Visualization, error)
+
+// Detect replays bars start through total-1 with run and compares each with
+// the full-history run. start is clamped to at least 1.
+func Detect(strategyID string, total, start int, run Runner) (*Report, error) {
+	start = max(start, 1)
+	report := &Report{StrategyID: strategyID, Bars: total, Start: start}
+	if start >= total {
+		return report, nil
+	}
+
+	finalSignals, finalVis, err := run(total)
+	if err != nil {
+		return nil, err
+	}
+	final := newSnapshot(finalSignals, finalVis)
+
+	for i := start; i < total; i++ {
+		signals, vis, err := run(i + 1)
+		if err != nil {
+			return nil, fmt.Errorf("bar %d: %w", i, err)
+		}
+		live := newSnapshot(signals, vis)
+		report.Changes = append(report.Changes, compareBar(i, live, final)...)
+	}
+	return report, nil
+}
+
+// DetectContext replays a single-asset strategy over ctx
+func DetectContext(strategyID string, params map[string]any, ctx *strategy.DataContext) (*Report, error) {
+	strat, err := newStrategy(strategyID, params)
+	if err != nil {
+		return nil, err
+	}
+	run := func(bars int) ([]exchange.Signal, *strategy.Visualization, error) {
+		s, err := newStrategy(strategyID, params)
+		if err != nil {
+			return nil, nil, err
+		}
+		head := ctx.Head(bars)
+		return strategy.GenerateSignals(s, head), strategy.GetVisualization(s, head), nil
+	}
+	return Detect(strategyID, len(ctx.Candles), strat.GetMetadata().WarmupBars, run)
+}
+
+// DetectBasket replays a multi-asset strategy over basket
+func DetectBasket(strategyID string, params map[string]any, basket *strategy.Basket) (*Report, error) {
+	strat, err := newStrategy(strategyID, params)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := strat.(strategy.MultiAssetStrategy); !ok {
+		return nil, fmt.Errorf("strategy %s is not a multi-asset strategy", strategyID)
+	}
+	run := func(bars int) ([]exchange.Signal, *strategy.Visualization, error) {
+		s, err := newStrategy(strategyID, params)
+		if err != nil {
+			return nil, nil, err
+		}
+		multi := s.(strategy.MultiAssetStrategy)
+		head := basket.Head(bars)
+		return multi.GenerateBasketSignals(head), multi.GetBasketVisualization(head), nil
+	}
+	return Detect(strategyID, basket.Len(), strat.GetMetadata().WarmupBars, run)
+}
+
+// newStrategy creates and initializes a fresh instance from the registry
+func newStrategy(strategyID string, params map[string]any) (strategy.Strategy, error) {
+	strat, err := strategy.Get(strategyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := strat.Initialize(params); err != nil {
+		return nil, fmt.Errorf("init %s: %w", strategyID, err)
+	}
+	return strat, nil
+}
+
+// snapshot indexes one run's output for per-bar comparison
+type snapshot struct {
+	signals map[int][]string
+	series  map[string]strategy.Series
+	order   []string
+}
+
+func newSnapshot(signals []exchange.Signal, vis *strategy.Visualization) snapshot {
+	snap := snapshot{
+		signals: make(map[int][]string),
+		series:  make(map[string]strategy.Series),
+	}
+	for _, signal := range signals {
+		snap.signals[signal.Index] = append(snap.signals[signal.Index], describeSignal(signal))
+	}
+	for index := range snap.signals {
+		slices.Sort(snap.signals[index])
+	}
+	if vis != nil {
+		for _, series := range vis.Series {
+			snap.series[series.ID] = series
+			snap.order = append(snap.order, series.ID)
+		}
+	}
+	return snap
+}
+
+// compareBar returns the differences at bar i between a live run whose
+// newest bar is i and the final run
+func compareBar(i int, live, final snapshot) []Change {
+	var changes []Change
+
+	liveSignals := strings.Join(live.signals[i], "; ")
+	finalSignals := strings.Join(final.signals[i], "; ")
+	if liveSignals != finalSignals {
+		changes = append(changes, Change{
+			Kind:  ChangeSignal,
+			Index: i,
+			Live:  orNone(liveSignals),
+			Final: orNone(finalSignals),
+		})
+	}
+
+	for _, id := range final.order {
+		finalSeries := final.series[id]
+		liveSeries := live.series[id]
+
+		liveValue, finalValue := valueAt(liveSeries.Values, i), valueAt(finalSeries.Values, i)
+		if !sameValue(liveValue, finalValue) {
+			changes = append(changes, Change{
+				Kind:   ChangeValue,
+				Index:  i,
+				Series: id,
+				Live:   formatValue(liveValue),
+				Final:  formatValue(finalValue),
+			})
+		}
+
+		liveColor, finalColor := colorAt(liveSeries.Colors, i), colorAt(finalSeries.Colors, i)
+		if liveColor != finalColor {
+			changes = append(changes, Change{
+				Kind:   ChangeColor,
+				Index:  i,
+				Series: id,
+				Live:   orNone(liveColor),
+				Final:  orNone(finalColor),
+			})
+		}
+	}
+	return changes
+}
+
+// describeSignal formats the parts of a signal a trader would act on
+func describeSignal(signal exchange.Signal) string {
+	var b strings.Builder
+	b.WriteString(signal.Type.String())
+	if signal.Symbol != "" {
+		b.WriteString(" " + signal.Symbol)
+	}
+	b.WriteString(" @ " + formatValue(signal.Price))
+	if signal.SizeFraction != 0 {
+		b.WriteString(" x" + formatValue(signal.SizeFraction))
+	}
+	if signal.StopPrice != 0 {
+		b.WriteString(" stop=" + formatValue(signal.StopPrice))
+	}
+	if signal.TargetPrice != 0 {
+		b.WriteString(" target=" + formatValue(signal.TargetPrice))
+	}
+	return b.String()
+}
+
+func valueAt(values strategy.Values, i int) float64 {
+	if i < 0 || i >= len(values) {
+		return math.NaN()
+	}
+	return values[i]
+}
+
+func colorAt(colors []string, i int) string {
+	if i < 0 || i >= len(colors) {
+		return ""
+	}
+	return colors[i]
+}
+
+func sameValue(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) <= valueTolerance*max(1, math.Abs(a), math.Abs(b))
+}
+
+func formatValue(v float64) string {
+	if math.IsNaN(v) {
+		return "none"
+	}
+	return strconv.FormatFloat(v, 'g', 10, 64)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,160 @@
+package repaint_test
+
+import (
+	"strconv"
+	"testing"
+
+	"terminal/internal/exchange"
+	"terminal/internal/strategy"
+	"terminal/internal/strategy/repaint"
+	"terminal/internal/strategy/strategytest"
+
+	_ "terminal/internal/strategy/builtin"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+var fixtures = strategytest.Fixtures{Dir: "../strategytest/testdata/candles"}
+
+// centered plots a moving average centered on each bar and signals at the
+// highest close seen, the two classic ways indicators repaint
+type centered struct{ lookahead bool }
+
+func (s *centered) GetMetadata() strategy.Metadata {
+	return strategy.Metadata{ID: "test-centered", Name: "Centered", Version: "1.0"}
+}
+
+func (s *centered) ValidateParams(map[string]any) error { return nil }
+
+func (s *centered) Initialize(map[string]any) error { return nil }
+
+func (s *centered) GenerateSignals(candles []hyperliquid.Candle) []exchange.Signal {
+	if len(candles) < 2 {
+		return nil
+	}
+	if !s.lookahead {
+		// Compares each bar with the one before: stable once the bar has closed
+		signals := []exchange.Signal{}
+		for i := 1; i < len(candles); i++ {
+			if closeAt(candles, i) > closeAt(candles, i-1) {
+				signals = append(signals, exchange.Signal{Index: i, Type: exchange.SignalLong, Price: closeAt(candles, i)})
+			}
+		}
+		return signals
+	}
+
+	best := 0
+	for i := range candles {
+		if closeAt(candles, i) > closeAt(candles, best) {
+			best = i
+		}
+	}
+	return []exchange.Signal{{Index: best, Type: exchange.SignalShort, Price: closeAt(candles, best)}}
+}
+
+func (s *centered) GetVisualization(candles []hyperliquid.Candle) *strategy.Visualization {
+	values := make(strategy.Values, len(candles))
+	for i := range candles {
+		from, to := i-2, i
+		if s.lookahead {
+			to = i + 2
+		}
+		sum, n := 0.0, 0
+		for j := max(from, 0); j <= min(to, len(candles)-1); j++ {
+			sum += closeAt(candles, j)
+			n++
+		}
+		values[i] = sum / float64(n)
+	}
+	vis := strategy.NewVisualization()
+	vis.Series = []strategy.Series{{ID: "ma", Pane: strategy.MainPane, Kind: strategy.SeriesLine, Values: values}}
+	return vis
+}
+
+func closeAt(candles []hyperliquid.Candle, i int) float64 {
+	f, _ := strconv.ParseFloat(candles[i].Close, 64)
+	return f
+}
+
+func loadContext(t *testing.T) *strategy.DataContext {
+	t.Helper()
+	candles, err := fixtures.Candles("BTC", "1h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strategy.NewDataContext("BTC", "1h", candles[:300])
+}
+
+func TestDetectsRepaint(t *testing.T) {
+	strategy.Register("test-centered", func() strategy.Strategy { return &centered{lookahead: true} })
+	t.Cleanup(func() { strategy.Unregister("test-centered") })
+
+	report, err := repaint.DetectContext("test-centered", nil, loadContext(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Repaints() {
+		t.Fatal("expected centered strategy to repaint")
+	}
+
+	kinds := map[string]bool{}
+	for _, change := range report.Changes {
+		kinds[change.Kind] = true
+	}
+	if !kinds[repaint.ChangeSignal] || !kinds[repaint.ChangeValue] {
+		t.Errorf("expected signal and value changes, got %v", kinds)
+	}
+}
+
+func TestTrailingStrategyIsClean(t *testing.T) {
+	strategy.Register("test-trailing", func() strategy.Strategy { return &centered{} })
+	t.Cleanup(func() { strategy.Unregister("test-trailing") })
+
+	report, err := repaint.DetectContext("test-trailing", nil, loadContext(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Repaints() {
+		t.Errorf("unexpected repaint: %v", report.Changes[0])
+	}
+}
+
+// TestRegisteredStrategies fails when any registered strategy repaints
+func TestRegisteredStrategies(t *testing.T) {
+	strategytest.RegisterComposites(t)
+
+	for _, meta := range strategy.List() {
+		c := strategytest.DefaultCase(meta)
+		t.Run(meta.ID, func(t *testing.T) {
+			var report *repaint.Report
+			if meta.MultiAsset {
+				basket, err := fixtures.Basket(c)
+				if err != nil {
+					t.Fatal(err)
+				}
+				report, err = repaint.DetectBasket(meta.ID, c.Params, basket)
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				ctx, err := fixtures.DataContext(meta, c)
+				if err != nil {
+					t.Fatal(err)
+				}
+				report, err = repaint.DetectContext(meta.ID, c.Params, ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if report.Repaints() {
+				for i, change := range report.Changes {
+					if i == 20 {
+						t.Errorf("... %d more", len(report.Changes)-i)
+						break
+					}
+					t.Error(change)
+				}
+			}
+		})
+	}
+}
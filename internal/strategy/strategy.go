@@ -14,6 +14,7 @@ type Strategy interface {
 	GetMetadata() Metadata
 
 	// ValidateParams validates parameters before use
+	// Most strategies delegate to ValidateAgainst with their own metadata
 	ValidateParams(params map[string]any) error
 
 	// Initialize sets up the strategy with validated parameters
@@ -34,13 +35,34 @@ type Metadata struct {
 	Version     string         `json:"version"`
 	Description string         `json:"description"`
 	Parameters  []ParameterDef `json:"parameters"`
+	// WarmupBars is the number of bars the strategy needs before its first
+	// valid signal; results on those bars are discarded
+	WarmupBars int `json:"warmupBars,omitempty"`
+	// Lookback is the preferred number of bars to evaluate on each live update
+	Lookback int `json:"lookback,omitempty"`
+	// Feeds lists extra (symbol, interval) series delivered to FeedStrategy
+	Feeds []FeedDef `json:"feeds,omitempty"`
+	// PerpData requests the funding, open interest and mark price series of
+	// the primary symbol, delivered to FeedStrategy through DataContext.Perp
+	PerpData bool `json:"perpData,omitempty"`
+	// MultiAsset is set by Describe for MultiAssetStrategy implementations
+	MultiAsset bool `json:"multiAsset,omitempty"`
+}
+
+// Describe returns the strategy's metadata with derived fields filled in
+func Describe(s Strategy) Metadata {
+	meta := s.GetMetadata()
+	if _, ok := s.(MultiAssetStrategy); ok {
+		meta.MultiAsset = true
+	}
+	return meta
 }
 
 // ParameterDef describes a strategy parameter for dynamic UI generation
 type ParameterDef struct {
 	Name         string   `json:"name"`
 	Label        string   `json:"label"`
-	Type         string   `json:"type"` // "number", "string", "select"
+	Type         string   `json:"type"` // one of the Param* constants
 	DefaultValue any      `json:"defaultValue"`
 	Min          *float64 `json:"min,omitempty"`
 	Max          *float64 `json:"max,omitempty"`
@@ -54,35 +76,3 @@ type Option struct {
 	Value any    `json:"value"`
 	Label string `json:"label"`
 }
-
-// Visualization contains strategy-specific chart overlays
-type Visualization struct {
-	// TrendLines is the main trend line values (one per candle)
-	TrendLines []float64 `json:"trendLines"`
-	// TrendColors is the color for each candle's trend line segment
-	TrendColors []string `json:"trendColors"`
-	// Directions indicates trend direction at each candle (-1 = up/long, 1 = down/short)
-	Directions []int `json:"directions"`
-	// Labels are text annotations on the chart
-	Labels []Label `json:"labels"`
-	// Lines are trend line segments
-	Lines []Line `json:"lines"`
-}
-
-// Label represents a text label on the chart
-type Label struct {
-	Index      int     `json:"index"`
-	Price      float64 `json:"price"`
-	Text       string  `json:"text"`
-	Direction  int     `json:"direction"`
-	Percentage float64 `json:"percentage"`
-}
-
-// Line represents a line segment on the chart
-type Line struct {
-	StartIndex int     `json:"startIndex"`
-	StartPrice float64 `json:"startPrice"`
-	EndIndex   int     `json:"endIndex"`
-	EndPrice   float64 `json:"endPrice"`
-	Direction  int     `json:"direction"`
-}
@@ -0,0 +1,17 @@
+package strategytest_test
+
+import (
+	"testing"
+
+	"terminal/internal/strategy/strategytest"
+
+	_ "terminal/internal/strategy/builtin"
+)
+
+// fixtureDir holds the candle fixtures shared by the strategy tests
+const fixtureDir = "testdata/candles"
+
+func TestRegisteredStrategies(t *testing.T) {
+	strategytest.RegisterComposites(t)
+	strategytest.RunAll(t, fixtureDir)
+}
@@ -0,0 +1,353 @@
+// Package strategytest runs registered strategies against recorded candle
+// fixtures, snapshots their output to golden files and checks the
+// invariants every strategy must hold.
+//
+// Fixtures are JSON arrays of hyperliquid.Candle named SYMBOL_interval.json,
+// e.g. BTC_1h.json. Golden files are written to testdata/golden of the
+// calling package when tests run with -update.
+package strategytest
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"terminal/internal/exchange"
+	"terminal/internal/strategy"
+	"terminal/internal/strategy/composite"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+var update = flag.Bool("update", false, "rewrite strategy golden files")
+
+// Case describes one strategy run
+type Case struct {
+	StrategyID string
+	Params     map[string]any
+	// Symbols holds the primary symbol, or every leg for multi-asset strategies
+	Symbols  []string
+	Interval string
+}
+
+// Output is what a strategy produced for a run; it is the golden file content
+type Output struct {
+	Bars          int                     `json:"bars"`
+	Signals       []exchange.Signal       `json:"signals"`
+	Visualization *strategy.Visualization `json:"visualization"`
+}
+
+// Fixtures loads candle fixtures from a directory
+type Fixtures struct {
+	Dir string
+}
+
+// LoadCandles reads a JSON array of candles from path
+func LoadCandles(path string) ([]hyperliquid.Candle, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var candles []hyperliquid.Candle
+	if err := json.Unmarshal(raw, &candles); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return candles, nil
+}
+
+// Candles loads the fixture for symbol and interval
+func (f Fixtures) Candles(symbol, interval string) ([]hyperliquid.Candle, error) {
+	return LoadCandles(filepath.Join(f.Dir, symbol+"_"+interval+".json"))
+}
+
+// Run runs the case on the first bars primary candles; bars <= 0 uses the
+// whole fixture. Feeds and other legs are cut at the same moment, so the
+// strategy sees exactly what it would have seen live at that bar. A fresh
+// strategy instance is used for every run.
+func (f Fixtures) Run(c Case, bars int) (Output, error) {
+	strat, err := strategy.Get(c.StrategyID)
+	if err != nil {
+		return Output{}, err
+	}
+	if err := strat.Initialize(c.Params); err != nil {
+		return Output{}, fmt.Errorf("init %s: %w", c.StrategyID, err)
+	}
+	if len(c.Symbols) == 0 {
+		return Output{}, fmt.Errorf("case %s has no symbols", c.StrategyID)
+	}
+
+	if multi, ok := strat.(strategy.MultiAssetStrategy); ok {
+		basket, err := f.Basket(c)
+		if err != nil {
+			return Output{}, err
+		}
+		if bars > 0 {
+			basket = basket.Head(bars)
+		}
+		return Output{
+			Bars:          basket.Len(),
+			Signals:       multi.GenerateBasketSignals(basket),
+			Visualization: multi.GetBasketVisualization(basket),
+		}, nil
+	}
+
+	ctx, err := f.DataContext(strat.GetMetadata(), c)
+	if err != nil {
+		return Output{}, err
+	}
+	if bars > 0 {
+		ctx = ctx.Head(bars)
+	}
+	return Output{
+		Bars:          len(ctx.Candles),
+		Signals:       strategy.GenerateSignals(strat, ctx),
+		Visualization: strategy.GetVisualization(strat, ctx),
+	}, nil
+}
+
+// DataContext loads the primary candles of c and the feeds meta declares
+func (f Fixtures) DataContext(meta strategy.Metadata, c Case) (*strategy.DataContext, error) {
+	symbol := c.Symbols[0]
+	candles, err := f.Candles(symbol, c.Interval)
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("fixture %s %s is empty", symbol, c.Interval)
+	}
+
+	ctx := strategy.NewDataContext(symbol, c.Interval, candles)
+	for _, feed := range meta.Feeds {
+		feedSymbol := feed.Symbol
+		if feedSymbol == "" {
+			feedSymbol = symbol
+		}
+		feedCandles, err := f.Candles(feedSymbol, feed.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("feed %s %s: %w", feedSymbol, feed.Interval, err)
+		}
+		ctx.AddFeed(feedSymbol, feed.Interval, feedCandles)
+	}
+	return ctx, nil
+}
+
+// Basket loads and aligns every leg of c
+func (f Fixtures) Basket(c Case) (*strategy.Basket, error) {
+	series := make(map[string][]hyperliquid.Candle, len(c.Symbols))
+	for _, symbol := range c.Symbols {
+		candles, err := f.Candles(symbol, c.Interval)
+		if err != nil {
+			return nil, err
+		}
+		series[symbol] = candles
+	}
+	return strategy.NewBasket(c.Interval, c.Symbols, series), nil
+}
+
+// DefaultCase returns the case used for a registered strategy when none is
+// given: default params on BTC 1h, paired with ETH for multi-asset strategies
+func DefaultCase(meta strategy.Metadata) Case {
+	c := Case{
+		StrategyID: meta.ID,
+		Params:     map[string]any{},
+		Symbols:    []string{"BTC"},
+		Interval:   "1h",
+	}
+	if meta.MultiAsset {
+		c.Symbols = []string{"BTC", "ETH"}
+	}
+	return c
+}
+
+// RegisterComposites registers the sample composite strategies used to
+// cover composition and higher-timeframe feeds, removing them when t ends
+func RegisterComposites(t *testing.T) {
+	t.Helper()
+	specs := []composite.Spec{
+		{
+			ID:      "test-trend-filter",
+			Name:    "Trend Filter",
+			Version: "1.0",
+			Mode:    composite.ModeFilter,
+			Children: []composite.Child{
+				{Alias: "entry", StrategyID: "max-trend"},
+				{Alias: "filter", StrategyID: "max-trend", Interval: "4h"},
+			},
+		},
+	}
+	for _, spec := range specs {
+		if err := composite.Register(spec); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { composite.Unregister(spec.ID) })
+	}
+}
+
+// RunAll checks every strategy in the registry against the fixtures in dir
+// with its DefaultCase: invariants first, then the golden snapshot
+func RunAll(t *testing.T, dir string) {
+	t.Helper()
+	fixtures := Fixtures{Dir: dir}
+	metas := strategy.List()
+	if len(metas) == 0 {
+		t.Fatal("no strategies registered")
+	}
+	for _, meta := range metas {
+		c := DefaultCase(meta)
+		t.Run(meta.ID, func(t *testing.T) {
+			CheckInvariants(t, fixtures, c)
+			out, err := fixtures.Run(c, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			CheckGolden(t, meta.ID, out)
+		})
+	}
+}
+
+// CheckGolden compares out with testdata/golden/name.json, rewriting the
+// file instead when the -update flag is set
+func CheckGolden(t *testing.T, name string, out Output) {
+	t.Helper()
+	got, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal output: %v", err)
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("output differs from %s; run with -update if the change is intended\n%s",
+			path, firstDiff(string(want), string(got)))
+	}
+}
+
+// CheckInvariants verifies the properties every strategy must hold:
+// signal indices and symbols are valid, the visualization is consistent,
+// output is deterministic, and signals do not change when future candles
+// are appended.
+func CheckInvariants(t *testing.T, fixtures Fixtures, c Case) {
+	t.Helper()
+	full, err := fixtures.Run(c, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if full.Bars == 0 {
+		t.Fatal("fixture has no bars")
+	}
+
+	symbols := make(map[string]bool, len(c.Symbols))
+	for _, symbol := range c.Symbols {
+		symbols[symbol] = true
+	}
+	multi := len(c.Symbols) > 1
+	for _, signal := range full.Signals {
+		if signal.Index < 0 || signal.Index >= full.Bars {
+			t.Errorf("signal %s at index %d outside [0, %d)", signal.Type, signal.Index, full.Bars)
+		}
+		if multi && !symbols[signal.Symbol] {
+			t.Errorf("signal %s at index %d has unknown symbol %q", signal.Type, signal.Index, signal.Symbol)
+		}
+	}
+	if full.Visualization != nil {
+		if err := full.Visualization.Validate(full.Bars); err != nil {
+			t.Errorf("invalid visualization: %v", err)
+		}
+	}
+
+	again, err := fixtures.Run(c, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := json.Marshal(full)
+	second, _ := json.Marshal(again)
+	if string(first) != string(second) {
+		t.Errorf("output is not deterministic\n%s", firstDiff(string(first), string(second)))
+	}
+
+	for _, bars := range cutPoints(full.Bars) {
+		prefix, err := fixtures.Run(c, bars)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := signalsBefore(full.Signals, bars)
+		if !reflect.DeepEqual(nonNil(prefix.Signals), want) {
+			t.Errorf("lookahead: signals on the first %d bars change when later bars are added\nprefix: %+v\nfull:   %+v",
+				bars, prefix.Signals, want)
+		}
+	}
+}
+
+// cutPoints returns prefix lengths spread over the last half of n bars
+func cutPoints(n int) []int {
+	var points []int
+	for _, frac := range []float64{0.5, 0.75, 0.9} {
+		if p := int(float64(n) * frac); p > 0 && p < n {
+			points = append(points, p)
+		}
+	}
+	if n > 1 {
+		points = append(points, n-1)
+	}
+	return points
+}
+
+// signalsBefore returns the signals with an index below bars
+func signalsBefore(signals []exchange.Signal, bars int) []exchange.Signal {
+	result := []exchange.Signal{}
+	for _, signal := range signals {
+		if signal.Index < bars {
+			result = append(result, signal)
+		}
+	}
+	return result
+}
+
+func nonNil(signals []exchange.Signal) []exchange.Signal {
+	if signals == nil {
+		return []exchange.Signal{}
+	}
+	return signals
+}
+
+// firstDiff describes the first line where want and got differ
+func firstDiff(want, got string) string {
+	line := 1
+	start := 0
+	for i := 0; i < len(want) && i < len(got); i++ {
+		if want[i] != got[i] {
+			return fmt.Sprintf("line %d:\n  want: %s\n  got:  %s", line, lineAt(want, start), lineAt(got, start))
+		}
+		if want[i] == '\n' {
+			line++
+			start = i + 1
+		}
+	}
+	return fmt.Sprintf("lengths differ: want %d bytes, got %d bytes", len(want), len(got))
+}
+
+func lineAt(s string, start int) string {
+	end := start
+	for end < len(s) && s[end] != '\n' {
+		end++
+	}
+	return s[start:end]
+}
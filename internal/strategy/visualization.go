@@ -0,0 +1,446 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// VisualizationVersion is the current version of the visualization schema.
+// Version 1 only had the max-trend shaped fields (TrendLines, TrendColors,
+// Directions, Labels, Lines); version 2 adds panes, series and the other
+// generic overlays.
+const VisualizationVersion = 2
+
+// MainPane is the pane id of the price chart
+const MainPane = "main"
+
+// Series kinds
+const (
+	SeriesLine      = "line"
+	SeriesArea      = "area"
+	SeriesHistogram = "histogram"
+)
+
+// Marker shapes
+const (
+	ShapeArrowUp   = "arrowUp"
+	ShapeArrowDown = "arrowDown"
+	ShapeCircle    = "circle"
+	ShapeSquare    = "square"
+	ShapeLabel     = "label"
+)
+
+// Marker positions relative to the candle
+const (
+	PositionAbove = "above"
+	PositionBelow = "below"
+	PositionAt    = "at"
+)
+
+// Line styles
+const (
+	StyleSolid  = "solid"
+	StyleDashed = "dashed"
+	StyleDotted = "dotted"
+)
+
+// Visualization contains strategy-specific chart overlays
+type Visualization struct {
+	// Version is the schema version, see VisualizationVersion
+	Version int `json:"version"`
+
+	// Panes are extra chart panes below the price chart; MainPane always exists
+	Panes []Pane `json:"panes,omitempty"`
+	// Series are per-candle values drawn as lines, areas or histograms
+	Series []Series `json:"series,omitempty"`
+	// Levels are horizontal lines at a fixed value
+	Levels []Level `json:"levels,omitempty"`
+	// Segments are straight lines between two points
+	Segments []Segment `json:"segments,omitempty"`
+	// Boxes are rectangles spanning a range of candles
+	Boxes []Box `json:"boxes,omitempty"`
+	// Fills shade the area between two series
+	Fills []Fill `json:"fills,omitempty"`
+	// Markers are shapes and text attached to a candle
+	Markers []Marker `json:"markers,omitempty"`
+	// Tables are small data tables overlaid on the chart
+	Tables []Table `json:"tables,omitempty"`
+
+	// Version 1 fields, kept for the existing max-trend chart renderer.
+	// New strategies should use Series, Segments and Markers instead.
+
+	// TrendLines is the main trend line values (one per candle)
+	TrendLines []float64 `json:"trendLines,omitempty"`
+	// TrendColors is the color for each candle's trend line segment
+	TrendColors []string `json:"trendColors,omitempty"`
+	// Directions indicates trend direction at each candle (-1 = up/long, 1 = down/short)
+	Directions []int `json:"directions,omitempty"`
+	// Labels are text annotations on the chart
+	Labels []Label `json:"labels,omitempty"`
+	// Lines are trend line segments
+	Lines []Line `json:"lines,omitempty"`
+}
+
+// NewVisualization creates an empty visualization at the current version
+func NewVisualization() *Visualization {
+	return &Visualization{Version: VisualizationVersion}
+}
+
+// Pane is a separate chart area, e.g. for an oscillator
+type Pane struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Height is relative to the other panes; zero lets the frontend decide
+	Height float64 `json:"height,omitempty"`
+	// Min and Max fix the pane's value range, e.g. 0-100 for RSI
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+// Values is a per-candle value series. NaN marks a gap and is encoded as
+// null in JSON.
+type Values []float64
+
+// MarshalJSON encodes NaN and infinite values as null
+func (v Values) MarshalJSON() ([]byte, error) {
+	out := make([]*float64, len(v))
+	for i := range v {
+		if !math.IsNaN(v[i]) && !math.IsInf(v[i], 0) {
+			out[i] = &v[i]
+		}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes null as NaN
+func (v *Values) UnmarshalJSON(data []byte) error {
+	var in []*float64
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*v = make(Values, len(in))
+	for i, f := range in {
+		if f == nil {
+			(*v)[i] = math.NaN()
+		} else {
+			(*v)[i] = *f
+		}
+	}
+	return nil
+}
+
+// Series is a named per-candle value series
+type Series struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Pane  string `json:"pane"`
+	Kind  string `json:"kind"`
+	Color string `json:"color,omitempty"`
+	// Colors optionally overrides Color per point
+	Colors    []string `json:"colors,omitempty"`
+	LineWidth int      `json:"lineWidth,omitempty"`
+	Values    Values   `json:"values"`
+}
+
+// Level is a horizontal line at a fixed value
+type Level struct {
+	Pane  string  `json:"pane"`
+	Value float64 `json:"value"`
+	Color string  `json:"color,omitempty"`
+	Style string  `json:"style,omitempty"`
+	Label string  `json:"label,omitempty"`
+}
+
+// Segment is a straight line between two candle/value points
+type Segment struct {
+	Pane       string  `json:"pane"`
+	StartIndex int     `json:"startIndex"`
+	StartValue float64 `json:"startValue"`
+	EndIndex   int     `json:"endIndex"`
+	EndValue   float64 `json:"endValue"`
+	Color      string  `json:"color,omitempty"`
+	Style      string  `json:"style,omitempty"`
+	Width      int     `json:"width,omitempty"`
+}
+
+// Box is a rectangle spanning a range of candles
+type Box struct {
+	Pane        string  `json:"pane"`
+	StartIndex  int     `json:"startIndex"`
+	EndIndex    int     `json:"endIndex"`
+	Top         float64 `json:"top"`
+	Bottom      float64 `json:"bottom"`
+	Color       string  `json:"color,omitempty"`
+	BorderColor string  `json:"borderColor,omitempty"`
+	Text        string  `json:"text,omitempty"`
+}
+
+// Fill shades the area between two series in the same pane
+type Fill struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Color string `json:"color,omitempty"`
+	// Colors optionally overrides Color per point
+	Colors []string `json:"colors,omitempty"`
+}
+
+// Marker is a shape or text attached to a candle
+type Marker struct {
+	Pane     string  `json:"pane"`
+	Index    int     `json:"index"`
+	Value    float64 `json:"value,omitempty"`
+	Shape    string  `json:"shape"`
+	Position string  `json:"position,omitempty"`
+	Color    string  `json:"color,omitempty"`
+	Text     string  `json:"text,omitempty"`
+}
+
+// Table is a small grid of text overlaid on a pane
+type Table struct {
+	ID       string     `json:"id"`
+	Title    string     `json:"title,omitempty"`
+	Pane     string     `json:"pane"`
+	Position string     `json:"position,omitempty"` // e.g. "topRight"
+	Columns  []string   `json:"columns"`
+	Rows     [][]string `json:"rows"`
+}
+
+// Validate checks that every overlay references a known pane or series and
+// that per-candle arrays fit within n candles
+func (v *Visualization) Validate(n int) error {
+	panes := map[string]bool{MainPane: true}
+	for _, pane := range v.Panes {
+		if pane.ID == "" || panes[pane.ID] {
+			return fmt.Errorf("invalid or duplicate pane id %q", pane.ID)
+		}
+		panes[pane.ID] = true
+	}
+
+	series := make(map[string]string, len(v.Series))
+	for _, s := range v.Series {
+		if s.ID == "" || series[s.ID] != "" {
+			return fmt.Errorf("invalid or duplicate series id %q", s.ID)
+		}
+		if !panes[s.Pane] {
+			return fmt.Errorf("series %s: unknown pane %q", s.ID, s.Pane)
+		}
+		if len(s.Values) > n {
+			return fmt.Errorf("series %s: %d values for %d candles", s.ID, len(s.Values), n)
+		}
+		if len(s.Colors) > 0 && len(s.Colors) != len(s.Values) {
+			return fmt.Errorf("series %s: %d colors for %d values", s.ID, len(s.Colors), len(s.Values))
+		}
+		series[s.ID] = s.Pane
+	}
+
+	for _, fill := range v.Fills {
+		from, to := series[fill.From], series[fill.To]
+		if from == "" || to == "" {
+			return fmt.Errorf("fill %s-%s: unknown series", fill.From, fill.To)
+		}
+		if from != to {
+			return fmt.Errorf("fill %s-%s: series are in different panes", fill.From, fill.To)
+		}
+	}
+
+	inRange := func(i int) bool { return i >= 0 && i < n }
+	for _, level := range v.Levels {
+		if !panes[level.Pane] {
+			return fmt.Errorf("level %v: unknown pane %q", level.Value, level.Pane)
+		}
+	}
+	for _, seg := range v.Segments {
+		if !panes[seg.Pane] || !inRange(seg.StartIndex) || !inRange(seg.EndIndex) {
+			return fmt.Errorf("segment %d-%d: invalid pane or index", seg.StartIndex, seg.EndIndex)
+		}
+	}
+	for _, box := range v.Boxes {
+		if !panes[box.Pane] || !inRange(box.StartIndex) || !inRange(box.EndIndex) {
+			return fmt.Errorf("box %d-%d: invalid pane or index", box.StartIndex, box.EndIndex)
+		}
+	}
+	for _, marker := range v.Markers {
+		if !panes[marker.Pane] || !inRange(marker.Index) {
+			return fmt.Errorf("marker at %d: invalid pane or index", marker.Index)
+		}
+	}
+	for _, table := range v.Tables {
+		if !panes[table.Pane] {
+			return fmt.Errorf("table %s: unknown pane %q", table.ID, table.Pane)
+		}
+	}
+	return nil
+}
+
+// Merge appends other's overlays, namespacing its series and panes with
+// prefix so that several strategies can share one chart. The main pane is
+// shared. Version 1 fields are taken from v if set, otherwise from other.
+func (v *Visualization) Merge(prefix string, other *Visualization) {
+	if other == nil {
+		return
+	}
+	if v.Version < other.Version {
+		v.Version = other.Version
+	}
+
+	rename := func(id string) string {
+		if prefix == "" {
+			return id
+		}
+		return prefix + "." + id
+	}
+	pane := func(id string) string {
+		if id == MainPane || id == "" {
+			return MainPane
+		}
+		return rename(id)
+	}
+
+	for _, p := range other.Panes {
+		p.ID = pane(p.ID)
+		v.Panes = append(v.Panes, p)
+	}
+	for _, s := range other.Series {
+		s.ID = rename(s.ID)
+		s.Pane = pane(s.Pane)
+		v.Series = append(v.Series, s)
+	}
+	for _, l := range other.Levels {
+		l.Pane = pane(l.Pane)
+		v.Levels = append(v.Levels, l)
+	}
+	for _, seg := range other.Segments {
+		seg.Pane = pane(seg.Pane)
+		v.Segments = append(v.Segments, seg)
+	}
+	for _, b := range other.Boxes {
+		b.Pane = pane(b.Pane)
+		v.Boxes = append(v.Boxes, b)
+	}
+	for _, f := range other.Fills {
+		f.From = rename(f.From)
+		f.To = rename(f.To)
+		v.Fills = append(v.Fills, f)
+	}
+	for _, m := range other.Markers {
+		m.Pane = pane(m.Pane)
+		v.Markers = append(v.Markers, m)
+	}
+	for _, t := range other.Tables {
+		t.ID = rename(t.ID)
+		t.Pane = pane(t.Pane)
+		v.Tables = append(v.Tables, t)
+	}
+
+	if v.TrendLines == nil {
+		v.TrendLines = other.TrendLines
+		v.TrendColors = other.TrendColors
+		v.Directions = other.Directions
+	}
+	v.Labels = append(v.Labels, other.Labels...)
+	v.Lines = append(v.Lines, other.Lines...)
+}
+
+// Trim drops the first start candles from the visualization, shifting all
+// indices down. Overlays that end before start are removed and ones that
+// straddle it are clipped.
+func (v *Visualization) Trim(start int) *Visualization {
+	if v == nil || start <= 0 {
+		return v
+	}
+
+	out := *v
+	out.Series = v.Series[:0:0]
+	for _, s := range v.Series {
+		s.Values = trimSlice(s.Values, start)
+		s.Colors = trimSlice(s.Colors, start)
+		out.Series = append(out.Series, s)
+	}
+	out.Fills = v.Fills[:0:0]
+	for _, f := range v.Fills {
+		f.Colors = trimSlice(f.Colors, start)
+		out.Fills = append(out.Fills, f)
+	}
+
+	out.Segments = v.Segments[:0:0]
+	for _, seg := range v.Segments {
+		if seg.EndIndex < start {
+			continue
+		}
+		if seg.StartIndex < start && seg.EndIndex > seg.StartIndex {
+			// Interpolate the clipped start point
+			t := float64(start-seg.StartIndex) / float64(seg.EndIndex-seg.StartIndex)
+			seg.StartValue += (seg.EndValue - seg.StartValue) * t
+			seg.StartIndex = start
+		}
+		seg.StartIndex -= start
+		seg.EndIndex -= start
+		out.Segments = append(out.Segments, seg)
+	}
+	out.Boxes = v.Boxes[:0:0]
+	for _, b := range v.Boxes {
+		if b.EndIndex < start {
+			continue
+		}
+		b.StartIndex = max(b.StartIndex, start) - start
+		b.EndIndex -= start
+		out.Boxes = append(out.Boxes, b)
+	}
+	out.Markers = v.Markers[:0:0]
+	for _, m := range v.Markers {
+		if m.Index >= start {
+			m.Index -= start
+			out.Markers = append(out.Markers, m)
+		}
+	}
+
+	out.TrendLines = trimSlice(v.TrendLines, start)
+	out.TrendColors = trimSlice(v.TrendColors, start)
+	out.Directions = trimSlice(v.Directions, start)
+	out.Labels = v.Labels[:0:0]
+	for _, l := range v.Labels {
+		if l.Index >= start {
+			l.Index -= start
+			out.Labels = append(out.Labels, l)
+		}
+	}
+	out.Lines = v.Lines[:0:0]
+	for _, l := range v.Lines {
+		if l.EndIndex < start {
+			continue
+		}
+		l.StartIndex = max(l.StartIndex, start) - start
+		l.EndIndex -= start
+		out.Lines = append(out.Lines, l)
+	}
+	return &out
+}
+
+func trimSlice[T any](values []T, start int) []T {
+	if len(values) <= start {
+		if values == nil {
+			return nil
+		}
+		return values[:0]
+	}
+	return values[start:]
+}
+
+// Label represents a text label on the chart (version 1, prefer Marker)
+type Label struct {
+	Index      int     `json:"index"`
+	Price      float64 `json:"price"`
+	Text       string  `json:"text"`
+	Direction  int     `json:"direction"`
+	Percentage float64 `json:"percentage"`
+}
+
+// Line represents a line segment on the chart (version 1, prefer Segment)
+type Line struct {
+	StartIndex int     `json:"startIndex"`
+	StartPrice float64 `json:"startPrice"`
+	EndIndex   int     `json:"endIndex"`
+	EndPrice   float64 `json:"endPrice"`
+	Direction  int     `json:"direction"`
+}
@@ -0,0 +1,73 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+// ParseHexKey parses a hex private key, with or without a 0x prefix
+func ParseHexKey(s string) (*ecdsa.PrivateKey, error) {
+	return crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+}
+
+// Address returns the checksummed address of a private key
+func Address(key *ecdsa.PrivateKey) string {
+	return crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+// scryptN and scryptP are the key derivation cost of new keystores
+var scryptN, scryptP = keystore.StandardScryptN, keystore.StandardScryptP
+
+// ReadKeystore decrypts a go-ethereum (Web3 Secret Storage) keystore file
+func ReadKeystore(path, passphrase string) (*ecdsa.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(raw, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock %s: %w", path, err)
+	}
+	return key.PrivateKey, nil
+}
+
+// WriteKeystore encrypts a private key with passphrase into a go-ethereum
+// keystore file readable by ReadKeystore and by other Ethereum wallets. It
+// refuses to overwrite an existing file.
+func WriteKeystore(path string, key *ecdsa.PrivateKey, passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("keystore passphrase must not be empty")
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	raw, err := keystore.EncryptKey(&keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey: key,
+	}, passphrase, scryptN, scryptP)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(raw); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,56 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func init() {
+	scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "keys", "signer.json")
+	if err := WriteKeystore(path, key, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadKeystore(path, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(key) || Address(got) != Address(key) {
+		t.Errorf("unlocked %s, want %s", Address(got), Address(key))
+	}
+	if _, err := ReadKeystore(path, "wrong horse"); err == nil {
+		t.Error("unlocked with the wrong passphrase")
+	}
+	if err := WriteKeystore(path, key, "correct horse"); err == nil {
+		t.Error("overwrote an existing keystore")
+	}
+	if err := WriteKeystore(filepath.Join(t.TempDir(), "empty.json"), key, ""); err == nil {
+		t.Error("wrote a keystore without a passphrase")
+	}
+}
+
+func TestParseHexKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseHexKey("0x" + hex.EncodeToString(crypto.FromECDSA(key)) + "\n")
+	if err != nil || !got.Equal(key) {
+		t.Errorf("parsed %v, %v; want %s", got, err, Address(key))
+	}
+	if _, err := ParseHexKey("not a key"); err == nil {
+		t.Error("parsed an invalid key")
+	}
+}
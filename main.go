@@ -2,6 +2,9 @@ package main
 
 import (
 	"embed"
+	"flag"
+	"fmt"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -9,15 +12,23 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/options/linux"
 
 	"terminal/internal/app"
+	"terminal/internal/config"
 )
 
 //go:embed all:frontend/dist
 var assets embed.FS
 
 func main() {
-	application := app.New()
+	flags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	cfg, err := flags.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid configuration:", err)
+		os.Exit(1)
+	}
+	application := app.New(cfg)
 
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:  "HyperTerminal",
 		Width:  1024,
 		Height: 1024,